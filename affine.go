@@ -0,0 +1,45 @@
+package geojson
+
+import (
+	"github.com/tidwall/geojson/geometry"
+)
+
+// Affine returns a copy of an object where every coordinate has been
+// transformed by a matrix. Feature members and foreign members are kept,
+// except that a "bbox" is replaced by the rect of the new coordinates, and
+// extra coordinate values, such as Z and M, are not changed. Rects and
+// shapes, such as circles, are converted to polygons first.
+func Affine(obj Object, m geometry.Matrix, opts *geometry.IndexOptions,
+) Object {
+	return rebuildObject(obj, func(points []geometry.Point, values []float64,
+		dims int, closed bool,
+	) ([]geometry.Point, []float64) {
+		npoints := make([]geometry.Point, len(points))
+		for i, point := range points {
+			npoints[i] = m.Transform(point)
+		}
+		return npoints, values
+	}, true, opts)
+}
+
+// Scale returns a copy of an object that is scaled away from an origin
+func Scale(obj Object, scaleX, scaleY float64, origin geometry.Point,
+	opts *geometry.IndexOptions,
+) Object {
+	return Affine(obj, geometry.ScaleMatrix(scaleX, scaleY, origin), opts)
+}
+
+// Rotate returns a copy of an object that is rotated counter-clockwise
+// about an origin.
+func Rotate(obj Object, degrees float64, origin geometry.Point,
+	opts *geometry.IndexOptions,
+) Object {
+	return Affine(obj, geometry.RotateMatrix(degrees, origin), opts)
+}
+
+// Skew returns a copy of an object that is sheared about an origin
+func Skew(obj Object, degreesX, degreesY float64, origin geometry.Point,
+	opts *geometry.IndexOptions,
+) Object {
+	return Affine(obj, geometry.SkewMatrix(degreesX, degreesY, origin), opts)
+}
@@ -0,0 +1,71 @@
+package geojson
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tidwall/geojson/geometry"
+	"github.com/tidwall/gjson"
+)
+
+func TestAffine(t *testing.T) {
+	expectAffine := func(g Object, m geometry.Matrix, output string) {
+		t.Helper()
+		out := Affine(g, m, nil).JSON()
+		if out != output {
+			t.Fatalf("expected '%v', got '%v'", output, out)
+		}
+	}
+	m := geometry.Matrix{2, 0, 1, 0, 3, -1}
+	expectAffine(expectJSON(t, `{"type":"Point","coordinates":[1,2,3]}`, nil), m,
+		`{"type":"Point","coordinates":[3,5,3]}`)
+	expectAffine(expectJSON(t, `{"type":"LineString","coordinates":[[0,0],[1,1]],"id":5}`, nil), m,
+		`{"type":"LineString","coordinates":[[1,-1],[3,2]],"id":5}`)
+	expectAffine(RO(0, 0, 1, 1), m,
+		`{"type":"Polygon","coordinates":[[[1,-1],[3,-1],[3,2],[1,2],[1,-1]]]}`)
+	expectAffine(expectJSON(t, `{"type":"Feature","geometry":{"type":"MultiPoint","coordinates":[[0,0],[1,1]]},"properties":{"a":1}}`, nil), m,
+		`{"type":"Feature","geometry":{"type":"MultiPoint","coordinates":[[1,-1],[3,2]]},"properties":{"a":1}}`)
+	expectAffine(expectJSON(t, `{"type":"GeometryCollection","geometries":[{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}]}`, nil), m,
+		`{"type":"GeometryCollection","geometries":[{"type":"Polygon","coordinates":[[[1,-1],[3,-1],[3,2],[1,-1]]]}]}`)
+	expectAffine(NewSimplePoint(P(1, 1)), m,
+		`{"type":"Point","coordinates":[3,2]}`)
+	// the bbox is the rect of the new coordinates
+	expectAffine(expectJSON(t, `{"type":"LineString","coordinates":[[0,0],[1,1]],"bbox":[0,0,1,1]}`, nil), m,
+		`{"type":"LineString","coordinates":[[1,-1],[3,2]],"bbox":[1,-1,3,2]}`)
+	expectAffine(expectJSON(t, `{"type":"Feature","geometry":{"type":"Point","coordinates":[1,1]},"bbox":[1,1,1,1],"properties":{}}`, nil), m,
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[3,2]},"bbox":[3,2,3,2],"properties":{}}`)
+	g := Rotate(expectJSON(t, `{"type":"MultiPoint","coordinates":[[1,0],[2,0]],"bbox":[1,0,2,0]}`, nil), 90, P(0, 0), nil)
+	rect := g.Rect()
+	bbox := gjson.Get(g.JSON(), "bbox").Array()
+	expect(t, len(bbox) == 4)
+	expect(t, bbox[0].Float() == rect.Min.X && bbox[1].Float() == rect.Min.Y)
+	expect(t, bbox[2].Float() == rect.Max.X && bbox[3].Float() == rect.Max.Y)
+	expect(t, math.Abs(rect.Max.Y-2) < 1e-9)
+}
+
+func TestRotateScaleSkew(t *testing.T) {
+	near := func(a, b geometry.Point) bool {
+		return math.Abs(a.X-b.X) < 1e-9 && math.Abs(a.Y-b.Y) < 1e-9
+	}
+	g := expectJSON(t, `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"MultiLineString","coordinates":[[[0,0],[2,0]],[[0,0],[0,2]]]},"properties":{}}]}`, nil)
+	rotated := Rotate(g, 90, P(0, 0), nil).(*FeatureCollection)
+	lines := rotated.Children()[0].(*Feature).Base().(*MultiLineString)
+	expect(t, near(lines.Children()[0].(*LineString).Base().PointAt(1), P(0, 2)))
+	expect(t, near(lines.Children()[1].(*LineString).Base().PointAt(1), P(-2, 0)))
+	expect(t, near(rotated.Rect().Min, P(-2, 0)))
+
+	scaled := Scale(g, 2, 0.5, P(1, 1), nil)
+	expect(t, scaled.Rect() == R(-1, 0.5, 3, 1.5))
+
+	skewed := Skew(PPO([]geometry.Point{P(0, 0), P(1, 0), P(1, 1), P(0, 1), P(0, 0)}, nil),
+		45, 0, P(0, 0), nil)
+	expect(t, skewed.Contains(PO(1.5, 0.75)))
+	expect(t, !skewed.Contains(PO(0.25, 0.75)))
+
+	// circles are rotated as polygons
+	circle := NewCircle(P(0, 0), 1000, 16)
+	poly := Rotate(circle, 45, P(1, 0), nil).(*Polygon)
+	expect(t, poly.Base().Exterior.NumPoints() ==
+		circle.Primative().(*Polygon).Base().Exterior.NumPoints())
+	expect(t, poly.Contains(PO(1-math.Sqrt(0.5), -math.Sqrt(0.5))))
+}
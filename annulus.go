@@ -0,0 +1,292 @@
+package geojson
+
+import (
+	"strconv"
+
+	"github.com/tidwall/geojson/geo"
+	"github.com/tidwall/geojson/geometry"
+	"github.com/tidwall/gjson"
+)
+
+// Annulus is a ring between two circles that share the same center. It covers
+// the points that are farther than the inner radius and no farther than the
+// outer radius.
+//
+// Its GeoJSON representation is a special Feature syntax such as:
+//
+//	{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},
+//	 "properties":{"type":"Annulus","inner_radius":500,"outer_radius":1000,
+//	 "radius_units":"m"}}
+type Annulus struct {
+	object Object
+	inner  *Circle // nil when there is no inner radius
+	outer  *Circle
+	id     gjson.Result // id of the Feature, for filters
+	props  *Properties  // properties of the Feature, for filters
+}
+
+// NewAnnulus returns an annulus object
+func NewAnnulus(center geometry.Point, innerMeters, outerMeters float64,
+	steps int,
+) *Annulus {
+	return NewAnnulusUnits(center, innerMeters, outerMeters, geo.Meters, steps)
+}
+
+// NewAnnulusUnits returns an annulus object with radii in the provided units.
+// The units are kept when the annulus is converted to JSON.
+func NewAnnulusUnits(center geometry.Point, inner, outer float64,
+	units geo.Unit, steps int,
+) *Annulus {
+	g := new(Annulus)
+	g.outer = NewCircleUnits(center, outer, units, steps)
+	if inner > 0 {
+		g.inner = NewCircleUnits(center, inner, units, steps)
+	}
+	g.object = g.makeObject()
+	return g
+}
+
+// AppendJSON ...
+func (g *Annulus) AppendJSON(dst []byte) []byte {
+	var inner float64
+	if g.inner != nil {
+		inner = g.inner.radius
+	}
+	outer, units := g.outer.radius, g.outer.units.String()
+	if units == "" {
+		// unknown units are written as meters
+		inner, outer = g.InnerMeters(), g.OuterMeters()
+		units = geo.Meters.String()
+	}
+	var props []byte
+	props = append(props, `"inner_radius":`...)
+	props = strconv.AppendFloat(props, inner, 'f', -1, 64)
+	props = append(props, `,"outer_radius":`...)
+	props = strconv.AppendFloat(props, outer, 'f', -1, 64)
+	props = append(props, `,"radius_units":"`...)
+	props = append(props, units...)
+	props = append(props, '"')
+	return appendJSONShape(dst, g.outer.center, "Annulus", props)
+}
+
+// JSON ...
+func (g *Annulus) JSON() string {
+	return string(g.AppendJSON(nil))
+}
+
+// MarshalJSON ...
+func (g *Annulus) MarshalJSON() ([]byte, error) {
+	return g.AppendJSON(nil), nil
+}
+
+// String ...
+func (g *Annulus) String() string {
+	return string(g.AppendJSON(nil))
+}
+
+// Center returns the annulus's center point
+func (g *Annulus) Center() geometry.Point {
+	return g.outer.center
+}
+
+// InnerMeters returns the annulus's inner radius
+func (g *Annulus) InnerMeters() float64 {
+	if g.inner == nil {
+		return 0
+	}
+	return g.inner.meters
+}
+
+// OuterMeters returns the annulus's outer radius
+func (g *Annulus) OuterMeters() float64 {
+	return g.outer.meters
+}
+
+// Units returns the units of the annulus's original radii
+func (g *Annulus) Units() geo.Unit {
+	return g.outer.units
+}
+
+// Within returns true if annulus is contained inside object
+func (g *Annulus) Within(obj Object) bool {
+	return obj.Contains(g)
+}
+
+// Contains returns true if the annulus contains other object
+func (g *Annulus) Contains(obj Object) bool {
+	switch other := obj.(type) {
+	case *Feature:
+		return g.Contains(other.base)
+	case Collection:
+		for _, p := range other.Children() {
+			if !g.Contains(p) {
+				return false
+			}
+		}
+		return true
+	default:
+		// inside of the outer circle without touching the inner circle
+		return g.outer.Contains(obj) &&
+			(g.inner == nil || !g.inner.Intersects(obj))
+	}
+}
+
+// Intersects returns true the annulus intersects other object
+func (g *Annulus) Intersects(obj Object) bool {
+	switch other := obj.(type) {
+	case *Feature:
+		return g.Intersects(other.base)
+	case Collection:
+		for _, p := range other.Children() {
+			if g.Intersects(p) {
+				return true
+			}
+		}
+		return false
+	default:
+		// A connected object that reaches the outer circle, but that isn't
+		// entirely inside of the inner circle, must cross the ring.
+		return g.outer.Intersects(obj) &&
+			(g.inner == nil || !g.inner.Contains(obj))
+	}
+}
+
+// Empty ...
+func (g *Annulus) Empty() bool {
+	return false
+}
+
+// Valid ...
+func (g *Annulus) Valid() bool {
+	return g.object.Valid()
+}
+
+// ForEach ...
+func (g *Annulus) ForEach(iter func(geom Object) bool) bool {
+	return iter(g)
+}
+
+// NumPoints ...
+func (g *Annulus) NumPoints() int {
+	return 1
+}
+
+// Distance ...
+func (g *Annulus) Distance(other Object) float64 {
+	return g.object.Distance(other)
+}
+
+// Rect ...
+func (g *Annulus) Rect() geometry.Rect {
+	return g.object.Rect()
+}
+
+// Spatial ...
+func (g *Annulus) Spatial() Spatial {
+	return g
+}
+
+// Primative returns a primative GeoJSON object. Either a Polygon or Point.
+func (g *Annulus) Primative() Object {
+	return g.object
+}
+
+// WithinRect ...
+func (g *Annulus) WithinRect(rect geometry.Rect) bool {
+	return g.outer.WithinRect(rect)
+}
+
+// WithinPoint ...
+func (g *Annulus) WithinPoint(point geometry.Point) bool {
+	return g.object.Spatial().WithinPoint(point)
+}
+
+// WithinLine ...
+func (g *Annulus) WithinLine(line *geometry.Line) bool {
+	return g.object.Spatial().WithinLine(line)
+}
+
+// WithinPoly ...
+func (g *Annulus) WithinPoly(poly *geometry.Poly) bool {
+	return g.object.Spatial().WithinPoly(poly)
+}
+
+// IntersectsPoint ...
+func (g *Annulus) IntersectsPoint(point geometry.Point) bool {
+	return g.outer.containsPoint(point) &&
+		(g.inner == nil || !g.inner.containsPoint(point))
+}
+
+// IntersectsRect ...
+func (g *Annulus) IntersectsRect(rect geometry.Rect) bool {
+	return g.Intersects(NewRect(rect))
+}
+
+// IntersectsLine ...
+func (g *Annulus) IntersectsLine(line *geometry.Line) bool {
+	return g.Intersects(NewLineString(line))
+}
+
+// IntersectsPoly ...
+func (g *Annulus) IntersectsPoly(poly *geometry.Poly) bool {
+	return g.Intersects(NewPolygon(poly))
+}
+
+// DistancePoint ...
+func (g *Annulus) DistancePoint(point geometry.Point) float64 {
+	return g.object.Spatial().DistancePoint(point)
+}
+
+// DistanceRect ...
+func (g *Annulus) DistanceRect(rect geometry.Rect) float64 {
+	return g.object.Spatial().DistanceRect(rect)
+}
+
+// DistanceLine ...
+func (g *Annulus) DistanceLine(line *geometry.Line) float64 {
+	return g.object.Spatial().DistanceLine(line)
+}
+
+// DistancePoly ...
+func (g *Annulus) DistancePoly(poly *geometry.Poly) float64 {
+	return g.object.Spatial().DistancePoly(poly)
+}
+
+func (g *Annulus) makeObject() Object {
+	outer, ok := g.outer.getObject().(*Polygon)
+	if !ok || g.inner == nil {
+		return g.outer.getObject()
+	}
+	inner := g.inner.getObject().(*Polygon)
+	return NewPolygon(
+		geometry.NewPoly(
+			seriesPoints(outer.base.Exterior),
+			[][]geometry.Point{seriesPoints(inner.base.Exterior)},
+			&geometry.IndexOptions{Kind: geometry.None},
+		),
+	)
+}
+
+func seriesPoints(series geometry.Series) []geometry.Point {
+	points := make([]geometry.Point, series.NumPoints())
+	for i := range points {
+		points[i] = series.PointAt(i)
+	}
+	return points
+}
+
+// parseJSONAnnulus returns the Annulus of a Feature, or nil when the radii
+// are missing, the inner radius is negative, or the outer radius is not
+// larger than the inner radius, which leaves it a plain Feature.
+func parseJSONAnnulus(center geometry.Point, members string) (Object, error) {
+	inner, ok1 := parseShapeSize(members, "inner_radius")
+	outer, ok2 := parseShapeSize(members, "outer_radius")
+	if !ok1 || !ok2 || !(inner >= 0) || !(outer > inner) {
+		return nil, nil
+	}
+	units, ok := parseRadiusUnits(members)
+	if !ok {
+		return nil, errRadiusUnitsInvalid
+	}
+	return NewAnnulusUnits(center, inner, outer, units, 64), nil
+}
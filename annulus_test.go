@@ -0,0 +1,97 @@
+package geojson
+
+import (
+	"testing"
+
+	"github.com/tidwall/geojson/geo"
+	"github.com/tidwall/geojson/geometry"
+)
+
+func TestAnnulusNew(t *testing.T) {
+	data := `{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},"properties":{"type":"Annulus","inner_radius":1,"outer_radius":2,"radius_units":"km"}}`
+	g := expectJSON(t, data, data)
+	expect(t, g.(*Annulus).Units() == geo.Kilometers)
+	expect(t, g.(*Annulus).InnerMeters() == 1000)
+	expect(t, g.(*Annulus).OuterMeters() == 2000)
+	data = `{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},"properties":{"type":"Annulus","inner_radius":0,"outer_radius":2,"radius_units":"mi"}}`
+	expectJSON(t, data, data)
+	g, err := Parse(`{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},"properties":{"type":"Annulus","inner_radius":1000,"outer_radius":2000}}`,
+		&ParseOptions{DisableAnnulusType: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := g.(*Feature); !ok {
+		t.Fatalf("expected Feature, got %T", g)
+	}
+	// invalid sizes are plain Features
+	for _, json := range []string{
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},"properties":{"type":"Annulus","inner_radius":2000,"outer_radius":2000}}`,
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},"properties":{"type":"Annulus","inner_radius":3000,"outer_radius":2000}}`,
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},"properties":{"type":"Annulus","inner_radius":-1,"outer_radius":2000}}`,
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},"properties":{"type":"Annulus","outer_radius":2000}}`,
+	} {
+		g, err := Parse(json, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := g.(*Feature); !ok {
+			t.Fatalf("expected Feature, got %T", g)
+		}
+	}
+	annulus := NewAnnulus(P(-112, 33), 1000, 2000, 64)
+	expect(t, annulus.InnerMeters() == 1000)
+	expect(t, annulus.OuterMeters() == 2000)
+	poly, ok := annulus.Primative().(*Polygon)
+	expect(t, ok && len(poly.Base().Holes) == 1)
+	expectJSON(t, annulus.JSON(), `{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},"properties":{"type":"Annulus","inner_radius":1000,"outer_radius":2000,"radius_units":"m"}}`)
+}
+
+func TestAnnulusPredicates(t *testing.T) {
+	center := P(-122.4412, 37.7335)
+	g := NewAnnulus(center, 1000, 2000, 64)
+	p := func(meters, bearing float64) geometry.Point {
+		return destinationPoint(center, meters, bearing)
+	}
+	expect(t, !g.Contains(PO(center.X, center.Y)))
+	expect(t, !g.Intersects(PO(center.X, center.Y)))
+	expect(t, g.Contains(PO(p(1500, 30).X, p(1500, 30).Y)))
+	expect(t, g.Intersects(PO(p(1500, 30).X, p(1500, 30).Y)))
+	expect(t, !g.Contains(PO(p(990, 30).X, p(990, 30).Y)))
+	expect(t, !g.Contains(PO(p(2010, 30).X, p(2010, 30).Y)))
+
+	// a line inside of the hole
+	line := LO([]geometry.Point{p(900, 0), p(900, 90)})
+	expect(t, !g.Intersects(line))
+	expect(t, !line.Intersects(g))
+	// a line that passes through the hole
+	line = LO([]geometry.Point{p(1500, 0), p(1500, 180)})
+	expect(t, g.Intersects(line))
+	expect(t, !g.Contains(line))
+	// a line within the ring
+	line = LO([]geometry.Point{p(1500, 0), p(1500, 30)})
+	expect(t, g.Contains(line))
+
+	// a polygon that covers the hole
+	poly := PPO([]geometry.Point{
+		p(1500, 0), p(1500, 120), p(1500, 240), p(1500, 0),
+	}, nil)
+	expect(t, g.Intersects(poly))
+	expect(t, !g.Contains(poly))
+	// a polygon with a hole that is bigger than the annulus
+	poly = PPO([]geometry.Point{
+		p(5000, 0), p(5000, 120), p(5000, 240), p(5000, 0),
+	}, [][]geometry.Point{{
+		p(4500, 0), p(4500, 120), p(4500, 240), p(4500, 0),
+	}})
+	expect(t, !g.Intersects(poly))
+	expect(t, !poly.Intersects(g))
+
+	// circles
+	expect(t, g.Intersects(NewCircle(center, 1500, 64)))
+	expect(t, !g.Intersects(NewCircle(center, 500, 64)))
+	expect(t, g.Contains(NewCircle(p(1500, 45), 100, 64)))
+
+	// without an inner radius
+	g = NewAnnulus(center, 0, 2000, 64)
+	expect(t, g.Contains(PO(center.X, center.Y)))
+}
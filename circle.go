@@ -6,6 +6,7 @@ import (
 
 	"github.com/tidwall/geojson/geo"
 	"github.com/tidwall/geojson/geometry"
+	"github.com/tidwall/gjson"
 )
 
 // Circle ...
@@ -15,36 +16,82 @@ type Circle struct {
 	meters    float64
 	haversine float64
 	steps     int
-	km        bool
+	radius    float64  // radius in the original units
+	units     geo.Unit // units of the original radius
+	model     geo.Model
 	extra     *extra
+	id        gjson.Result // id of the Feature, for filters
+	props     *Properties  // properties of the Feature, for filters
 }
 
 // NewCircle returns an circle object
 func NewCircle(center geometry.Point, meters float64, steps int) *Circle {
+	return NewCircleUnits(center, meters, geo.Meters, steps)
+}
+
+// NewCircleUnits returns an circle object with a radius in the provided
+// units. The units are kept when the circle is converted to JSON.
+func NewCircleUnits(center geometry.Point, radius float64, units geo.Unit,
+	steps int,
+) *Circle {
 	if steps < 3 {
 		steps = 3
 	}
 	g := new(Circle)
 	g.center = center
-	g.meters = meters
+	g.radius = radius
+	g.units = units
+	g.meters = units.ToMeters(radius)
 	g.steps = steps
-	if meters > 0 {
-		meters = geo.NormalizeDistance(meters)
+	if g.meters > 0 {
+		meters := geo.NormalizeDistance(g.meters)
 		g.haversine = geo.DistanceToHaversine(meters)
 	}
 	return g
 }
 
+// WithModel returns a copy of the circle that measures distances using the
+// provided model of the earth.
+func (g *Circle) WithModel(model geo.Model) *Circle {
+	circle := *g
+	circle.model = model
+	circle.object = nil
+	return &circle
+}
+
+// Model returns the model of the earth used by the circle
+func (g *Circle) Model() geo.Model {
+	return g.model
+}
+
 // AppendJSON ...
 func (g *Circle) AppendJSON(dst []byte) []byte {
+	return g.appendFeatureJSON(dst, nil)
+}
+
+// appendFeatureJSON appends the Feature of the circle, with the clipper in
+// the properties when it's not nil.
+func (g *Circle) appendFeatureJSON(dst []byte, clipper Object) []byte {
 	dst = append(dst, `{"type":"Feature","geometry":`...)
 	dst = append(dst, `{"type":"Point","coordinates":[`...)
 	dst = strconv.AppendFloat(dst, g.center.X, 'f', -1, 64)
 	dst = append(dst, ',')
 	dst = strconv.AppendFloat(dst, g.center.Y, 'f', -1, 64)
 	dst = append(dst, `]},"properties":{"type":"Circle","radius":`...)
-	dst = strconv.AppendFloat(dst, g.meters, 'f', -1, 64)
-	dst = append(dst, `,"radius_units":"m"}}`...)
+	radius, units := g.radius, g.units.String()
+	if units == "" {
+		// an invalid unit is written as the meters of the circle
+		radius, units = g.meters, geo.Meters.String()
+	}
+	dst = strconv.AppendFloat(dst, radius, 'f', -1, 64)
+	dst = append(dst, `,"radius_units":"`...)
+	dst = append(dst, units...)
+	dst = append(dst, '"')
+	if clipper != nil {
+		dst = append(dst, `,"clipper":`...)
+		dst = clipper.AppendJSON(dst)
+	}
+	dst = append(dst, `}}`...)
 	return dst
 }
 
@@ -68,6 +115,16 @@ func (g *Circle) Meters() float64 {
 	return g.meters
 }
 
+// Radius returns the circle's radius in its original units
+func (g *Circle) Radius() float64 {
+	return g.radius
+}
+
+// Units returns the units of the circle's original radius
+func (g *Circle) Units() geo.Unit {
+	return g.units
+}
+
 // Center returns the circle's center point
 func (g *Circle) Center() geometry.Point {
 	return g.center
@@ -90,10 +147,268 @@ func (g *Circle) Within(obj Object) bool {
 
 // containsPoint returns true if circle contains a given point
 func (g *Circle) containsPoint(p geometry.Point) bool {
+	if g.model != geo.Spherical {
+		return g.model.DistanceTo(p.Y, p.X, g.center.Y, g.center.X) <=
+			g.normMeters()
+	}
 	h := geo.Haversine(p.Y, p.X, g.center.Y, g.center.X)
 	return h <= g.haversine
 }
 
+// normMeters returns the normalized radius of the circle in meters
+func (g *Circle) normMeters() float64 {
+	if g.meters <= 0 {
+		return 0
+	}
+	return geo.NormalizeDistance(g.meters)
+}
+
+// distanceToSegment returns the distance in meters from the circle's center
+// to the closest point of the segment, which is a straight line in lat/lon
+// space like the edges of the geometries.
+func (g *Circle) distanceToSegment(seg geometry.Segment) float64 {
+	return g.model.DistanceToSegment(g.center.Y, g.center.X,
+		seg.A.Y, seg.A.X, seg.B.Y, seg.B.X)
+}
+
+// distanceToCenter returns the distance in meters from the circle's center
+// to the other circle's center.
+func (g *Circle) distanceToCenter(other *Circle) float64 {
+	return g.model.DistanceTo(g.center.Y, g.center.X,
+		other.center.Y, other.center.X)
+}
+
+// capRect returns the rectangle that bounds the circle.
+func (g *Circle) capRect() geometry.Rect {
+	meters := g.normMeters()
+	if g.model != geo.Spherical {
+		// spherical and ellipsoidal distances differ by less than 1%
+		meters *= 1.01
+	}
+	minLat, minLon, maxLat, maxLon :=
+		geo.RectFromCenter(g.center.Y, g.center.X, meters)
+	return geometry.Rect{
+		Min: geometry.Point{X: minLon, Y: minLat},
+		Max: geometry.Point{X: maxLon, Y: maxLat},
+	}
+}
+
+// containsSeries returns true if every point of the series is inside of the
+// circle. The distance from the center along a segment is always greatest
+// at one of its ends, so containing all of the vertices also means
+// containing all of the segments in between.
+func (g *Circle) containsSeries(series geometry.Series) bool {
+	n := series.NumPoints()
+	if n == 0 {
+		return false
+	}
+	for i := 0; i < n; i++ {
+		if !g.containsPoint(series.PointAt(i)) {
+			return false
+		}
+	}
+	return true
+}
+
+// intersectsSeries returns true if any point or segment of the series is
+// inside of the circle.
+func (g *Circle) intersectsSeries(series geometry.Series) bool {
+	n := series.NumPoints()
+	if n == 0 {
+		return false
+	}
+	if g.containsPoint(series.PointAt(0)) {
+		return true
+	}
+	radius := g.normMeters()
+	var intersects bool
+	series.Search(g.capRect(), func(seg geometry.Segment, _ int) bool {
+		if g.distanceToSegment(seg) <= radius {
+			intersects = true
+			return false
+		}
+		return true
+	})
+	return intersects
+}
+
+// intersectsPoly returns true if the circle intersects the polygon. This
+// happens when the polygon contains the circle's center or when any of the
+// polygon's rings passes through the circle.
+func (g *Circle) intersectsPoly(poly *geometry.Poly) bool {
+	if poly.Empty() {
+		return false
+	}
+	if poly.ContainsPoint(g.center) {
+		return true
+	}
+	if g.intersectsSeries(poly.Exterior) {
+		return true
+	}
+	for _, hole := range poly.Holes {
+		if g.intersectsSeries(hole) {
+			return true
+		}
+	}
+	return false
+}
+
+// distanceTo returns the distance in meters from the circle's center to a
+// point.
+func (g *Circle) distanceTo(point geometry.Point) float64 {
+	return g.model.DistanceTo(g.center.Y, g.center.X, point.Y, point.X)
+}
+
+// shapeDistances returns the distances in meters from the circle's center to
+// the closest and the farthest points of a shape, where the closest distance
+// is zero when the shape contains the center. Both are infinite for an empty
+// shape. The circle contains the shape when the farthest point is inside,
+// and intersects it when the closest point is inside.
+func (g *Circle) shapeDistances(obj Object) (min, max float64) {
+	min, max = math.Inf(+1), math.Inf(-1)
+	add := func(point geometry.Point) {
+		meters := g.distanceTo(point)
+		min, max = math.Min(min, meters), math.Max(max, meters)
+	}
+	switch other := obj.(type) {
+	case *Annulus:
+		meters := g.distanceTo(other.outer.center)
+		outer, inner := other.OuterMeters(), other.InnerMeters()
+		max = meters + outer
+		switch {
+		case meters > outer:
+			min = meters - outer
+		case meters < inner:
+			min = inner - meters
+		default:
+			min = 0
+		}
+		return min, max
+	case *Sector:
+		add(other.center)
+		if !other.full() {
+			// the radial edges, which end on the arc
+			for _, bearing := range []float64{other.start, other.end} {
+				add(destinationPoint(other.center, other.meters, bearing))
+				_, meters := geo.SegmentMin(func(t float64) float64 {
+					return g.distanceTo(destinationPoint(other.center,
+						other.meters*t, bearing))
+				})
+				min = math.Min(min, meters)
+			}
+		}
+		// Along the arc, the distance only has one closest and one farthest
+		// point, which are toward and away from the circle's center.
+		toward := other.bearingTo(g.center)
+		for _, bearing := range []float64{toward, toward + 180} {
+			if other.full() || bearingInRange(bearing, other.start, other.end) {
+				add(destinationPoint(other.center, other.meters, bearing))
+			}
+		}
+	case *Ellipse:
+		if other.semiMajor <= 0 || other.semiMinor <= 0 {
+			add(other.center)
+			break
+		}
+		// The distance along the edge can have more than one closest and
+		// farthest point, so the edge is sampled and then refined near the
+		// closest and farthest samples.
+		const steps = 360
+		edge := func(bearing float64) float64 {
+			return g.distanceTo(destinationPoint(other.center,
+				other.radiusAt(bearing), bearing))
+		}
+		var minBearing, maxBearing float64
+		for i := 0; i < steps; i++ {
+			bearing := 360 * float64(i) / steps
+			meters := edge(bearing)
+			if meters < min {
+				min, minBearing = meters, bearing
+			}
+			if meters > max {
+				max, maxBearing = meters, bearing
+			}
+		}
+		const span = 2 * 360 / steps
+		_, meters := geo.SegmentMin(func(t float64) float64 {
+			return edge(minBearing - span/2 + span*t)
+		})
+		min = math.Min(min, meters)
+		_, meters = geo.SegmentMin(func(t float64) float64 {
+			return -edge(maxBearing - span/2 + span*t)
+		})
+		max = math.Max(max, -meters)
+	case *ClippedCircle:
+		if other.Empty() {
+			return min, max
+		}
+		// The shape is the part of the other circle that's inside of the
+		// rect. Along the other circle's edge, the distance only has one
+		// closest and one farthest point, and along each part of a rect
+		// segment that's inside the other circle, the distance only has one
+		// closest point, which is at one of its ends when it's not between.
+		c := other.circle
+		rect := other.clipRect()
+		toward := c.model.BearingTo(c.center.Y, c.center.X,
+			g.center.Y, g.center.X)
+		for _, bearing := range []float64{toward, toward + 180} {
+			lat, lon := c.model.DestinationPoint(c.center.Y, c.center.X,
+				c.normMeters(), bearing)
+			point := geometry.Point{X: lon, Y: lat}
+			if rect.ContainsPoint(point) {
+				add(point)
+			}
+		}
+		for i := 0; i < 4; i++ {
+			seg := rect.SegmentAt(i)
+			t0, t1, ok := c.segmentInside(seg)
+			if !ok {
+				continue
+			}
+			add(segmentPointAt(seg, t0))
+			add(segmentPointAt(seg, t1))
+			t, _ := geo.SegmentMin(func(t float64) float64 {
+				return g.distanceTo(segmentPointAt(seg, t))
+			})
+			add(segmentPointAt(seg, math.Max(t0, math.Min(t1, t))))
+		}
+	}
+	if obj.Spatial().IntersectsPoint(g.center) {
+		min = 0
+	}
+	return min, max
+}
+
+// segmentInside returns the part of a segment, from t0 to t1 along it, that
+// is inside of the circle, and false when none of it is inside.
+func (g *Circle) segmentInside(seg geometry.Segment) (t0, t1 float64, ok bool) {
+	inside := func(t float64) bool {
+		return g.containsPoint(segmentPointAt(seg, t))
+	}
+	t, _ := geo.SegmentMin(func(t float64) float64 {
+		return g.distanceTo(segmentPointAt(seg, t))
+	})
+	if !inside(t) {
+		return 0, 0, false
+	}
+	// bisect for the ends, from the closest point, which is inside
+	edge := func(out, in float64) float64 {
+		if inside(out) {
+			return out
+		}
+		for i := 0; i < 64; i++ {
+			mid := (out + in) / 2
+			if inside(mid) {
+				in = mid
+			} else {
+				out = mid
+			}
+		}
+		return in
+	}
+	return edge(0, t), edge(1, t), true
+}
+
 // Contains returns true if the circle contains other object
 func (g *Circle) Contains(obj Object) bool {
 	switch other := obj.(type) {
@@ -102,7 +417,18 @@ func (g *Circle) Contains(obj Object) bool {
 	case *SimplePoint:
 		return g.containsPoint(other.Center())
 	case *Circle:
-		return other.Distance(g) < (other.meters + g.meters)
+		return g.distanceToCenter(other)+other.normMeters() <= g.normMeters()
+	case *LineString:
+		return g.containsSeries(&other.base)
+	case *Polygon:
+		if other.base.Empty() {
+			return false
+		}
+		return g.containsSeries(other.base.Exterior)
+	case *Rect:
+		return g.containsSeries(other.base)
+	case *Feature:
+		return g.Contains(other.base)
 	case Collection:
 		for _, p := range other.Children() {
 			if !g.Contains(p) {
@@ -110,6 +436,9 @@ func (g *Circle) Contains(obj Object) bool {
 			}
 		}
 		return true
+	case *ClippedCircle, *Ellipse, *Sector, *Annulus:
+		_, max := g.shapeDistances(other)
+		return max <= g.normMeters()
 	default:
 		// No simple cases, so using polygon approximation.
 		return g.getObject().Contains(other)
@@ -121,8 +450,16 @@ func (g *Circle) Intersects(obj Object) bool {
 	switch other := obj.(type) {
 	case *Point:
 		return g.containsPoint(other.Center())
+	case *SimplePoint:
+		return g.containsPoint(other.Center())
 	case *Circle:
-		return other.Distance(g) <= (other.meters + g.meters)
+		return g.distanceToCenter(other) <= other.normMeters()+g.normMeters()
+	case *LineString:
+		return g.intersectsSeries(&other.base)
+	case *Polygon:
+		return g.intersectsPoly(&other.base)
+	case *Rect:
+		return g.intersectsPoly(&geometry.Poly{Exterior: other.base})
 	case Collection:
 		for _, p := range other.Children() {
 			if g.Intersects(p) {
@@ -132,6 +469,9 @@ func (g *Circle) Intersects(obj Object) bool {
 		return false
 	case *Feature:
 		return g.Intersects(other.base)
+	case *ClippedCircle, *Ellipse, *Sector, *Annulus:
+		min, _ := g.shapeDistances(other)
+		return min <= g.normMeters()
 	default:
 		// No simple cases, so using polygon approximation.
 		return g.getObject().Intersects(obj)
@@ -171,7 +511,90 @@ func (g *Circle) Rect() geometry.Rect {
 
 // Spatial ...
 func (g *Circle) Spatial() Spatial {
-	return g.getObject().Spatial()
+	return g
+}
+
+// WithinRect ...
+func (g *Circle) WithinRect(rect geometry.Rect) bool {
+	return rect.ContainsRect(g.capRect())
+}
+
+// WithinPoint ...
+func (g *Circle) WithinPoint(point geometry.Point) bool {
+	return g.getObject().Spatial().WithinPoint(point)
+}
+
+// WithinLine ...
+func (g *Circle) WithinLine(line *geometry.Line) bool {
+	return g.getObject().Spatial().WithinLine(line)
+}
+
+// WithinPoly returns true if the polygon contains the circle's center and
+// none of the polygon's rings pass through the circle.
+func (g *Circle) WithinPoly(poly *geometry.Poly) bool {
+	if !poly.ContainsPoint(g.center) {
+		return false
+	}
+	radius := g.normMeters()
+	if radius == 0 {
+		return true
+	}
+	rect := g.capRect()
+	within := true
+	rings := append([]geometry.Ring{poly.Exterior}, poly.Holes...)
+	for _, ring := range rings {
+		ring.Search(rect, func(seg geometry.Segment, _ int) bool {
+			if g.distanceToSegment(seg) < radius {
+				within = false
+				return false
+			}
+			return true
+		})
+		if !within {
+			return false
+		}
+	}
+	return true
+}
+
+// IntersectsPoint ...
+func (g *Circle) IntersectsPoint(point geometry.Point) bool {
+	return g.containsPoint(point)
+}
+
+// IntersectsRect ...
+func (g *Circle) IntersectsRect(rect geometry.Rect) bool {
+	return g.intersectsPoly(&geometry.Poly{Exterior: rect})
+}
+
+// IntersectsLine ...
+func (g *Circle) IntersectsLine(line *geometry.Line) bool {
+	return g.intersectsSeries(line)
+}
+
+// IntersectsPoly ...
+func (g *Circle) IntersectsPoly(poly *geometry.Poly) bool {
+	return g.intersectsPoly(poly)
+}
+
+// DistancePoint ...
+func (g *Circle) DistancePoint(point geometry.Point) float64 {
+	return g.getObject().Spatial().DistancePoint(point)
+}
+
+// DistanceRect ...
+func (g *Circle) DistanceRect(rect geometry.Rect) float64 {
+	return g.getObject().Spatial().DistanceRect(rect)
+}
+
+// DistanceLine ...
+func (g *Circle) DistanceLine(line *geometry.Line) float64 {
+	return g.getObject().Spatial().DistanceLine(line)
+}
+
+// DistancePoly ...
+func (g *Circle) DistancePoly(poly *geometry.Poly) float64 {
+	return g.getObject().Spatial().DistancePoly(poly)
 }
 
 // Primative returns a primative GeoJSON object. Either a Polygon or Point.
@@ -183,10 +606,12 @@ func (g *Circle) getObject() Object {
 	if g.object != nil {
 		return g.object
 	}
-	return makeCircleObject(g.center, g.meters, g.steps)
+	return makeCircleObject(g.center, g.meters, g.steps, g.model)
 }
 
-func makeCircleObject(center geometry.Point, meters float64, steps int) Object {
+func makeCircleObject(center geometry.Point, meters float64, steps int,
+	model geo.Model,
+) Object {
 	if meters <= 0 {
 		return NewPoint(center)
 	}
@@ -194,10 +619,10 @@ func makeCircleObject(center geometry.Point, meters float64, steps int) Object {
 	points := make([]geometry.Point, 0, steps+1)
 
 	// calc the four corners
-	maxY, _ := geo.DestinationPoint(center.Y, center.X, meters, 0)
-	_, maxX := geo.DestinationPoint(center.Y, center.X, meters, 90)
-	minY, _ := geo.DestinationPoint(center.Y, center.X, meters, 180)
-	_, minX := geo.DestinationPoint(center.Y, center.X, meters, 270)
+	maxY, _ := model.DestinationPoint(center.Y, center.X, meters, 0)
+	_, maxX := model.DestinationPoint(center.Y, center.X, meters, 90)
+	minY, _ := model.DestinationPoint(center.Y, center.X, meters, 180)
+	_, minX := model.DestinationPoint(center.Y, center.X, meters, 270)
 
 	// TODO: detect of pole and antimeridian crossing and generate a
 	// valid multigeometry
@@ -222,3 +647,27 @@ func makeCircleObject(center geometry.Point, meters float64, steps int) Object {
 		}),
 	)
 }
+
+func parseJSONCircle(center geometry.Point, members string, opts *ParseOptions,
+) (Object, error) {
+	units, ok := parseRadiusUnits(members)
+	if !ok {
+		return nil, errCircleRadiusUnitsInvalid
+	}
+	radius := gjson.Get(members, "properties.radius").Float()
+	circle := NewCircleUnits(center, radius, units, 64)
+	if opts.DistanceModel != geo.Spherical {
+		circle = circle.WithModel(opts.DistanceModel)
+	}
+	rClipper := gjson.Get(members, "properties.clipper")
+	if rClipper.Exists() {
+		// ClippedCircle
+		clipper, err := Parse(rClipper.Raw, opts)
+		if err != nil {
+			return nil, err
+		}
+		gopts := toGeometryOpts(opts)
+		return NewClippedCircle(circle, clipper, &gopts), nil
+	}
+	return circle, nil
+}
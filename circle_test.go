@@ -1,8 +1,11 @@
 package geojson
 
 import (
+	"math"
+	"math/rand"
 	"testing"
 
+	"github.com/tidwall/geojson/geo"
 	"github.com/tidwall/geojson/geometry"
 )
 
@@ -29,6 +32,54 @@ func TestCircleNew(t *testing.T) {
 
 }
 
+func TestCircleUnits(t *testing.T) {
+	for _, units := range []string{"m", "km", "mi", "ft", "yd", "nmi"} {
+		data := `{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},"properties":{"type":"Circle","radius":1.5,"radius_units":"` + units + `"}}`
+		g := expectJSON(t, data, data)
+		circle := g.(*Circle)
+		unit, _ := geo.ParseUnit(units)
+		expect(t, circle.Units() == unit)
+		expect(t, circle.Radius() == 1.5)
+		expect(t, circle.Meters() == unit.ToMeters(1.5))
+	}
+	expectJSON(t,
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},"properties":{"type":"Circle","radius":1,"radius_units":"parsecs"}}`,
+		errCircleRadiusUnitsInvalid,
+	)
+	circle := NewCircleUnits(P(-112, 33), 2, geo.Miles, 64)
+	expect(t, circle.Meters() == 3218.688)
+	expect(t, circle.Contains(PO(-112, 33.02)))
+	expect(t, !circle.Contains(PO(-112, 33.03)))
+	expectJSON(t, circle.JSON(), `{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},"properties":{"type":"Circle","radius":2,"radius_units":"mi"}}`)
+	// an invalid unit has no meters, which are written instead
+	circle = NewCircleUnits(P(-112, 33), 2, geo.Unit(100), 64)
+	expectJSON(t, circle.JSON(), `{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},"properties":{"type":"Circle","radius":0,"radius_units":"m"}}`)
+}
+
+func TestCircleModel(t *testing.T) {
+	// one degree of latitude at the equator is 111195 meters on the sphere
+	// and 110574 meters on the ellipsoid.
+	circle := NewCircle(P(0, 0), 110800, 64)
+	expect(t, circle.Model() == geo.Spherical)
+	expect(t, !circle.Contains(PO(0, 1)))
+	expect(t, !circle.Intersects(LO([]geometry.Point{{X: -1, Y: 1}, {X: 1, Y: 1}})))
+	wgs84 := circle.WithModel(geo.Ellipsoidal)
+	expect(t, wgs84.Model() == geo.Ellipsoidal)
+	expect(t, circle.Model() == geo.Spherical)
+	expect(t, wgs84.Contains(PO(0, 1)))
+	expect(t, wgs84.Intersects(LO([]geometry.Point{{X: -1, Y: 1}, {X: 1, Y: 1}})))
+	expect(t, !wgs84.Contains(PO(0, 1.01)))
+	expect(t, wgs84.JSON() == circle.JSON())
+
+	data := `{"type":"Feature","geometry":{"type":"Point","coordinates":[0,0]},"properties":{"type":"Circle","radius":110800}}`
+	g, err := Parse(data, &ParseOptions{DistanceModel: geo.Ellipsoidal})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect(t, g.(*Circle).Model() == geo.Ellipsoidal)
+	expect(t, g.Contains(PO(0, 1)))
+}
+
 func TestCircleContains(t *testing.T) {
 	g := NewCircle(P(-122.4412, 37.7335), 1000, 64)
 	expect(t, g.Contains(PO(-122.4412, 37.7335)))
@@ -160,6 +211,206 @@ func TestCircleIntersects(t *testing.T) {
 			P(-122.210, 37.860)})))
 }
 
+func TestCircleExactEdge(t *testing.T) {
+	center := P(-122.4412, 37.7335)
+	g := NewCircle(center, 100000, 64)
+	dest := func(meters, bearing float64) geometry.Point {
+		lat, lon := geo.DestinationPoint(center.Y, center.X, meters, bearing)
+		return P(lon, lat)
+	}
+	// A point just inside of the circle, halfway between two vertices of
+	// the polygon approximation, where the approximation falls short.
+	bearing := 90 - 360.0/64/2
+	inside := dest(99950, bearing)
+	outside := dest(100050, bearing)
+	expect(t, !g.getObject().Contains(PO(inside.X, inside.Y)))
+	expect(t, g.Contains(PO(inside.X, inside.Y)))
+	expect(t, g.Intersects(PO(inside.X, inside.Y)))
+	expect(t, !g.Contains(PO(outside.X, outside.Y)))
+	expect(t, !g.Intersects(PO(outside.X, outside.Y)))
+
+	// A line that grazes the inside of the circle with both ends outside.
+	line := LO([]geometry.Point{
+		dest(150000, bearing-60), dest(150000, bearing+60),
+	})
+	expect(t, g.Intersects(line))
+	expect(t, line.Intersects(g))
+	expect(t, !g.Contains(line))
+	line = LO([]geometry.Point{inside, dest(50000, 0)})
+	expect(t, g.Contains(line))
+
+	// A polygon that only touches the circle with one of its edges.
+	poly := PPO([]geometry.Point{
+		dest(150000, bearing-60), dest(150000, bearing+60),
+		dest(300000, bearing), dest(150000, bearing-60),
+	}, nil)
+	expect(t, g.Intersects(poly))
+	expect(t, poly.Intersects(g))
+	expect(t, !g.Contains(poly))
+	poly = PPO([]geometry.Point{
+		center, inside, dest(50000, 0), center,
+	}, nil)
+	expect(t, g.Contains(poly))
+
+	// A polygon with a hole that holds the entire circle.
+	poly = PPO([]geometry.Point{
+		dest(500000, 0), dest(500000, 120), dest(500000, 240),
+		dest(500000, 0),
+	}, [][]geometry.Point{{
+		dest(300000, 0), dest(300000, 120), dest(300000, 240),
+		dest(300000, 0),
+	}})
+	expect(t, !g.Intersects(poly))
+	expect(t, !poly.Intersects(g))
+
+	// A rect that only touches the circle with one of its edges.
+	north := dest(99950, 0)
+	rect := RO(center.X-0.5, north.Y, center.X+0.5, north.Y+1)
+	expect(t, g.Intersects(rect))
+	expect(t, rect.Intersects(g))
+	expect(t, !g.Contains(rect))
+
+	// Circles
+	expect(t, g.Contains(NewCircle(dest(50000, 10), 49000, 64)))
+	expect(t, !g.Contains(NewCircle(dest(50000, 10), 51000, 64)))
+	expect(t, g.Intersects(NewCircle(dest(150000, 10), 51000, 64)))
+	expect(t, !g.Intersects(NewCircle(dest(150000, 10), 49000, 64)))
+
+	// Features
+	expect(t, g.Contains(NewFeature(PO(inside.X, inside.Y), "")))
+	expect(t, !g.Contains(NewFeature(PO(outside.X, outside.Y), "")))
+
+	// Within
+	expect(t, g.Within(RO(center.X-2, center.Y-2, center.X+2, center.Y+2)))
+	expect(t, !g.Within(RO(center.X-1, center.Y-2, center.X+1, center.Y+2)))
+	expect(t, !g.Within(poly))
+	poly = PPO([]geometry.Point{
+		dest(500000, 0), dest(500000, 120), dest(500000, 240),
+		dest(500000, 0),
+	}, nil)
+	expect(t, g.Within(poly))
+}
+
+func TestCircleShapes(t *testing.T) {
+	center := P(-112, 33)
+	dest := func(meters, bearing float64) geometry.Point {
+		lat, lon := geo.DestinationPoint(center.Y, center.X, meters, bearing)
+		return P(lon, lat)
+	}
+	// The closest and farthest distances to a shape, from points along its
+	// edges, or from the segments of a clipped circle's approximation.
+	shapeDistances := func(g *Circle, obj Object) (min, max float64) {
+		min, max = math.Inf(+1), math.Inf(-1)
+		if obj.Spatial().IntersectsPoint(g.center) {
+			min = 0
+		}
+		add := func(point geometry.Point) {
+			meters := g.distanceTo(point)
+			min, max = math.Min(min, meters), math.Max(max, meters)
+		}
+		const steps = 20000
+		switch shape := obj.(type) {
+		case *Ellipse:
+			for i := 0; i < steps; i++ {
+				bearing := 360 * float64(i) / steps
+				add(destinationPoint(shape.center, shape.radiusAt(bearing),
+					bearing))
+			}
+		case *Sector:
+			sweep := bearingSweep(shape.start, shape.end)
+			for i := 0; i <= steps; i++ {
+				t := float64(i) / steps
+				add(destinationPoint(shape.center, shape.meters,
+					shape.start+sweep*t))
+				add(destinationPoint(shape.center, shape.meters*t,
+					shape.start))
+				add(destinationPoint(shape.center, shape.meters*t, shape.end))
+			}
+		case *ClippedCircle:
+			rect := shape.clipRect()
+			for i := 0; i < steps; i++ {
+				point := destinationPoint(shape.circle.center,
+					shape.circle.Meters(), 360*float64(i)/steps)
+				if rect.ContainsPoint(point) {
+					add(point)
+				}
+			}
+			for i := 0; i < 4; i++ {
+				for j := 0; j <= steps; j++ {
+					point := segmentPointAt(rect.SegmentAt(i),
+						float64(j)/steps)
+					if shape.circle.containsPoint(point) {
+						add(point)
+					}
+				}
+			}
+		}
+		return min, max
+	}
+	shapes := func() []Object {
+		at := dest(rand.Float64()*30000, rand.Float64()*360)
+		return []Object{
+			NewEllipse(at, 5000+rand.Float64()*10000,
+				1000+rand.Float64()*4000, rand.Float64()*360, 4096),
+			NewSector(at, 1000+rand.Float64()*10000, rand.Float64()*360,
+				rand.Float64()*360, 4096),
+			NewAnnulus(at, rand.Float64()*5000, 5000+rand.Float64()*5000,
+				4096),
+			NewClippedCircle(NewCircle(at, 1000+rand.Float64()*10000, 4096),
+				RO(at.X-rand.Float64()*0.1, at.Y-rand.Float64()*0.1,
+					at.X+rand.Float64()*0.1, at.Y+rand.Float64()*0.1), nil),
+		}
+	}
+	for i := 0; i < 100; i++ {
+		g := NewCircle(center, 1000, 64)
+		for _, shape := range shapes() {
+			min, max := g.shapeDistances(shape)
+			if _, ok := shape.(*Annulus); ok {
+				// the hole is not in the exterior of the approximation
+				a := shape.(*Annulus)
+				d := g.distanceTo(a.outer.center)
+				expect(t, math.Abs(max-(d+a.OuterMeters())) < 1e-6)
+				continue
+			}
+			pmin, pmax := shapeDistances(g, shape)
+			expect(t, math.Abs(min-pmin) < 1)
+			expect(t, math.Abs(max-pmax) < 1)
+			// the predicates follow the distances
+			for _, meters := range []float64{min - 2, min + 2, max - 2,
+				max + 2} {
+				if meters <= 0 {
+					continue
+				}
+				g := NewCircle(center, meters, 64)
+				expect(t, g.Intersects(shape) == (min <= meters))
+				expect(t, g.Contains(shape) == (max <= meters))
+				expect(t, shape.Within(g) == (max <= meters))
+			}
+		}
+	}
+
+	// An ellipse that only reaches the circle between two vertices of its
+	// polygon approximation, and a circle that holds the approximation but
+	// not the ellipse.
+	ellipse := NewEllipse(center, 10000, 10000, 0, 64)
+	touch := NewCircle(dest(10005, 360.0/64/2), 10, 64)
+	expect(t, !ellipse.Primative().Intersects(touch))
+	expect(t, touch.Intersects(ellipse))
+	expect(t, !touch.Contains(ellipse))
+	around := NewCircle(center, 9995, 64)
+	expect(t, !around.Contains(ellipse))
+	expect(t, NewCircle(center, 10001, 64).Contains(ellipse))
+
+	// annuli
+	annulus := NewAnnulus(center, 5000, 10000, 64)
+	expect(t, !NewCircle(center, 4000, 64).Intersects(annulus))
+	expect(t, NewCircle(center, 6000, 64).Intersects(annulus))
+	expect(t, NewCircle(dest(20000, 45), 10001, 64).Intersects(annulus))
+	expect(t, !NewCircle(dest(20000, 45), 9999, 64).Intersects(annulus))
+	expect(t, NewCircle(center, 10001, 64).Contains(annulus))
+	expect(t, !NewCircle(center, 9999, 64).Contains(annulus))
+}
+
 // This snippet tests 100M comparisons.
 // On my box this takes 24.5s without haversine trick, and 13.7s with the trick.
 //
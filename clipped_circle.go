@@ -4,11 +4,19 @@ import (
 	"github.com/tidwall/geojson/geometry"
 )
 
-// Clipped Circle ...
+// ClippedCircle is a circle that has been clipped by the rectangle of another
+// object.
+//
+// Its GeoJSON representation is the special Circle syntax with the clipper
+// object added to the properties, such as:
+//
+//	{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},
+//	 "properties":{"type":"Circle","radius":1000,"radius_units":"m",
+//	 "clipper":{"type":"Polygon","coordinates":[...]}}}
 type ClippedCircle struct {
-	circle    *Circle
-	clipper   Object
-	clipped   Object
+	circle  *Circle
+	clipper Object
+	clipped Object
 }
 
 // NewClippedCircle returns a clipped circle object
@@ -16,17 +24,14 @@ func NewClippedCircle(circle *Circle, clipper Object, opts *geometry.IndexOption
 	g := new(ClippedCircle)
 	g.circle = circle
 	g.clipper = clipper
-	g.clipped = clipPolygon(circle.getObject().(*Polygon), clipper, opts)
+	// a circle without a radius is a point, which is clipped like a point
+	g.clipped = Clip(circle.getObject(), clipper, opts)
 	return g
 }
 
 // AppendJSON ...
 func (g *ClippedCircle) AppendJSON(dst []byte) []byte {
-	dst = append(dst, `{"type":"FeatureCollection","features":[`...)
-	dst = g.circle.AppendJSON(dst)
-	dst = g.clipper.AppendJSON(dst)
-	dst = append(dst, '}')
-	return dst
+	return g.circle.appendFeatureJSON(dst, g.clipper)
 }
 
 // JSON ...
@@ -44,6 +49,26 @@ func (g *ClippedCircle) String() string {
 	return string(g.AppendJSON(nil))
 }
 
+// Circle returns the unclipped circle
+func (g *ClippedCircle) Circle() *Circle {
+	return g.circle
+}
+
+// Clipper returns the clipper object
+func (g *ClippedCircle) Clipper() Object {
+	return g.clipper
+}
+
+// Primative returns the clipped polygon approximation.
+func (g *ClippedCircle) Primative() Object {
+	return g.clipped
+}
+
+// clipRect returns the rectangle that the circle is clipped to.
+func (g *ClippedCircle) clipRect() geometry.Rect {
+	return g.clipper.Rect()
+}
+
 // Center returns the circle's center point
 func (g *ClippedCircle) Center() geometry.Point {
 	return g.clipped.Center()
@@ -51,20 +76,27 @@ func (g *ClippedCircle) Center() geometry.Point {
 
 // Within returns true if circle is contained inside object
 func (g *ClippedCircle) Within(obj Object) bool {
-	return g.clipped.Within(obj)
+	return obj.Contains(g)
 }
 
 // Contains returns true if the circle contains other object
 func (g *ClippedCircle) Contains(obj Object) bool {
 	// contains can be exact, without approximation
-	return g.circle.Contains(obj) && g.clipper.Contains(obj)
+	return g.circle.Contains(obj) && NewRect(g.clipRect()).Contains(obj)
 }
 
 // Intersects returns true the circle intersects other object
 func (g *ClippedCircle) Intersects(obj Object) bool {
 	switch other := obj.(type) {
 	case *Point:
-		return g.circle.Intersects(obj) && g.clipper.Intersects(obj)
+		return g.IntersectsPoint(other.Center())
+	case *SimplePoint:
+		return g.IntersectsPoint(other.Center())
+	case *LineString, *Polygon, *Rect:
+		// clipping the other object to the same rectangle keeps only the
+		// parts that can intersect the circle.
+		clipped := Clip(other, NewRect(g.clipRect()), nil)
+		return !clipped.Empty() && g.circle.Intersects(clipped)
 	case Collection:
 		for _, p := range other.Children() {
 			if g.Intersects(p) {
@@ -82,7 +114,7 @@ func (g *ClippedCircle) Intersects(obj Object) bool {
 
 // Empty ...
 func (g *ClippedCircle) Empty() bool {
-	return !g.clipper.Intersects(g.circle.getObject())
+	return !g.circle.IntersectsRect(g.clipRect())
 }
 
 // Valid ...
@@ -92,26 +124,85 @@ func (g *ClippedCircle) Valid() bool {
 
 // ForEach ...
 func (g *ClippedCircle) ForEach(iter func(geom Object) bool) bool {
-	return g.circle.ForEach(iter)
+	return iter(g)
 }
 
 // NumPoints ...
 func (g *ClippedCircle) NumPoints() int {
-	// should this be g.steps?
-	return g.circle.NumPoints()
+	return g.clipped.NumPoints()
 }
 
 // Distance ...
 func (g *ClippedCircle) Distance(other Object) float64 {
-	return g.circle.Distance(other)
+	return g.clipped.Distance(other)
 }
 
 // Rect ...
 func (g *ClippedCircle) Rect() geometry.Rect {
-	return g.clipper.Rect()
+	return g.clipped.Rect()
 }
 
 // Spatial ...
 func (g *ClippedCircle) Spatial() Spatial {
-	return g.circle.Spatial()
+	return g
+}
+
+// WithinRect ...
+func (g *ClippedCircle) WithinRect(rect geometry.Rect) bool {
+	return g.clipped.Spatial().WithinRect(rect)
+}
+
+// WithinPoint ...
+func (g *ClippedCircle) WithinPoint(point geometry.Point) bool {
+	return g.clipped.Spatial().WithinPoint(point)
+}
+
+// WithinLine ...
+func (g *ClippedCircle) WithinLine(line *geometry.Line) bool {
+	return g.clipped.Spatial().WithinLine(line)
+}
+
+// WithinPoly ...
+func (g *ClippedCircle) WithinPoly(poly *geometry.Poly) bool {
+	return g.clipped.Spatial().WithinPoly(poly)
+}
+
+// IntersectsPoint ...
+func (g *ClippedCircle) IntersectsPoint(point geometry.Point) bool {
+	return g.clipRect().ContainsPoint(point) && g.circle.containsPoint(point)
+}
+
+// IntersectsRect ...
+func (g *ClippedCircle) IntersectsRect(rect geometry.Rect) bool {
+	return g.Intersects(NewRect(rect))
+}
+
+// IntersectsLine ...
+func (g *ClippedCircle) IntersectsLine(line *geometry.Line) bool {
+	return g.Intersects(NewLineString(line))
+}
+
+// IntersectsPoly ...
+func (g *ClippedCircle) IntersectsPoly(poly *geometry.Poly) bool {
+	return g.Intersects(NewPolygon(poly))
+}
+
+// DistancePoint ...
+func (g *ClippedCircle) DistancePoint(point geometry.Point) float64 {
+	return g.clipped.Spatial().DistancePoint(point)
+}
+
+// DistanceRect ...
+func (g *ClippedCircle) DistanceRect(rect geometry.Rect) float64 {
+	return g.clipped.Spatial().DistanceRect(rect)
+}
+
+// DistanceLine ...
+func (g *ClippedCircle) DistanceLine(line *geometry.Line) float64 {
+	return g.clipped.Spatial().DistanceLine(line)
+}
+
+// DistancePoly ...
+func (g *ClippedCircle) DistancePoly(poly *geometry.Poly) float64 {
+	return g.clipped.Spatial().DistancePoly(poly)
 }
@@ -10,10 +10,72 @@ func TestClippedCircleNew(t *testing.T) {
 	circle := NewCircle(P(-112, 33), 123456.654321, 64)
 	clipper := RO(-113, 32.5, -112, 33.5)
 	g := NewClippedCircle(circle, clipper, nil)
-	exectedJson := `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},"properties":{"type":"Circle","radius":123456.654321,"radius_units":"m"}}{"type":"Polygon","coordinates":[[[-113,32.5],[-112,32.5],[-112,33.5],[-113,33.5],[-113,32.5]]]}}`
+	exectedJson := `{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},"properties":{"type":"Circle","radius":123456.654321,"radius_units":"m","clipper":{"type":"Polygon","coordinates":[[[-113,32.5],[-112,32.5],[-112,33.5],[-113,33.5],[-113,32.5]]]}}}`
 	expect(t, g.JSON() == exectedJson)
+	g2 := expectJSON(t, g.JSON(), nil)
+	if _, ok := g2.(*ClippedCircle); !ok {
+		t.Fatalf("expected ClippedCircle, got %T", g2)
+	}
+	expect(t, g2.JSON() == exectedJson)
+	expect(t, g2.Rect() == g.Rect())
 }
 
+func TestClippedCircleZeroRadius(t *testing.T) {
+	// a circle without a radius is a point
+	g, err := Parse(`{"type":"Feature","geometry":{"type":"Point",`+
+		`"coordinates":[-112,33]},"properties":{"type":"Circle","radius":0,`+
+		`"clipper":{"type":"Polygon","coordinates":[[[-113,32.5],[-111,32.5],`+
+		`[-111,33.5],[-113,33.5],[-113,32.5]]]}}}`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc, ok := g.(*ClippedCircle)
+	if !ok {
+		t.Fatalf("expected ClippedCircle, got %T", g)
+	}
+	expect(t, cc.Primative().JSON() == `{"type":"Point","coordinates":[-112,33]}`)
+	expect(t, !cc.Empty())
+	expect(t, cc.Intersects(PO(-112, 33)))
+	expect(t, !cc.Intersects(PO(-112.5, 33)))
+	outside := NewClippedCircle(NewCircle(P(-112, 33), 0, 64),
+		RO(-100, 32.5, -99, 33.5), nil)
+	expect(t, outside.Primative().Empty())
+	expect(t, outside.Empty())
+}
+
+func TestClippedCircleGeometry(t *testing.T) {
+	circle := NewCircle(P(-112, 33), 123456.654321, 64)
+	clipper := RO(-113, 32.5, -112, 33.5)
+	g := NewClippedCircle(circle, clipper, nil)
+	expect(t, g.Rect() == g.clipped.Rect())
+	expect(t, clipper.Rect().ContainsRect(g.Rect()))
+	small := NewClippedCircle(NewCircle(P(-112, 33), 50000, 64), clipper, nil)
+	expect(t, clipper.Rect().ContainsRect(small.Rect()))
+	expect(t, small.Rect() != clipper.Rect())
+	expect(t, g.Spatial().IntersectsPoint(P(-112.26, 33.49)))
+	expect(t, !g.Spatial().IntersectsPoint(P(-111.9, 33)))
+	expect(t, !g.Spatial().IntersectsPoint(P(-112.5, 33.7)))
+	expect(t, g.Distance(PO(-111, 33)) == g.clipped.Distance(PO(-111, 33)))
+	var n int
+	g.ForEach(func(geom Object) bool {
+		expect(t, geom == g)
+		n++
+		return true
+	})
+	expect(t, n == 1)
+	expect(t, !g.Empty())
+	expect(t, NewClippedCircle(circle, RO(-100, 32.5, -99, 33.5), nil).Empty())
+
+	// a line that crosses the clipped edge, but only touches the circle
+	// outside of the clipper.
+	line := LO([]geometry.Point{P(-111.5, 32.8), P(-111.5, 33.2)})
+	expect(t, circle.Intersects(line))
+	expect(t, !g.Intersects(line))
+	expect(t, !line.Intersects(g))
+	line = LO([]geometry.Point{P(-111.5, 32.8), P(-112.5, 33.2)})
+	expect(t, g.Intersects(line))
+	expect(t, line.Intersects(g))
+}
 
 func TestPointClippedCircle(t *testing.T) {
 	circle := NewCircle(P(-112, 33), 123456.654321, 64)
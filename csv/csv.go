@@ -0,0 +1,120 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+// Package csv reads and writes features as delimited text, such as CSV or
+// TSV, with the geometries in longitude and latitude columns or in a WKT
+// column.
+package csv
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrNoGeometryColumns is returned when the header does not have the
+	// configured geometry columns, or when none are found.
+	ErrNoGeometryColumns = errors.New("no geometry columns")
+	// ErrInvalidCoordinates is returned for a row when the longitude or
+	// latitude is not a number, or is out of range.
+	ErrInvalidCoordinates = errors.New("invalid coordinates")
+	// ErrInvalidWKT is returned for a row when the WKT is not valid, or is an
+	// empty geometry.
+	ErrInvalidWKT = errors.New("invalid wkt")
+)
+
+// Options are the options for reading and writing delimited text.
+type Options struct {
+	// Comma is the field delimiter. The default is a comma.
+	Comma rune
+	// Lon, Lat, and Z are the names of the longitude, latitude, and
+	// elevation columns. The Z column is optional.
+	Lon, Lat, Z string
+	// WKT is the name of a column with WKT geometries. It's used instead of
+	// the Lon and Lat columns.
+	WKT string
+}
+
+// DefaultOptions are the default options, which find the geometry columns
+// by their names when reading, and write geometries to a "WKT" column.
+var DefaultOptions = &Options{
+	Comma: ',',
+}
+
+// RowError is an error of a single row, which is left out of the
+// collection.
+type RowError struct {
+	// Line is the line number of the row, starting at 1 for the header.
+	Line int
+	Err  error
+}
+
+func (err *RowError) Error() string {
+	return fmt.Sprintf("line %d: %v", err.Line, err.Err)
+}
+
+// names of the geometry columns that are found when not configured
+var (
+	wktNames = []string{"wkt", "geometry", "geom", "the_geom", "wkt_geom",
+		"shape"}
+	lonNames = []string{"lon", "lng", "long", "longitude", "x", "point_x"}
+	latNames = []string{"lat", "latitude", "y", "point_y"}
+	zNames   = []string{"z", "elevation", "ele", "altitude", "alt"}
+)
+
+// columns are the indexes of the geometry columns, or -1 when missing
+type columns struct {
+	lon, lat, z, wkt int
+}
+
+func findColumn(header []string, names ...string) int {
+	for _, name := range names {
+		for i, col := range header {
+			if strings.EqualFold(strings.TrimSpace(col), name) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// findColumns returns the geometry columns of a header. The configured
+// columns are required, otherwise a WKT column is preferred over longitude
+// and latitude columns.
+func findColumns(header []string, opts *Options) (columns, error) {
+	cols := columns{lon: -1, lat: -1, z: -1, wkt: -1}
+	switch {
+	case opts.WKT != "":
+		cols.wkt = findColumn(header, opts.WKT)
+		if cols.wkt == -1 {
+			return cols, ErrNoGeometryColumns
+		}
+		return cols, nil
+	case opts.Lon != "" || opts.Lat != "":
+		cols.lon = findColumn(header, opts.Lon)
+		cols.lat = findColumn(header, opts.Lat)
+		if opts.Z != "" {
+			cols.z = findColumn(header, opts.Z)
+			if cols.z == -1 {
+				return cols, ErrNoGeometryColumns
+			}
+		}
+	default:
+		if cols.wkt = findColumn(header, wktNames...); cols.wkt != -1 {
+			return cols, nil
+		}
+		cols.lon = findColumn(header, lonNames...)
+		cols.lat = findColumn(header, latNames...)
+		cols.z = findColumn(header, zNames...)
+	}
+	if cols.lon == -1 || cols.lat == -1 {
+		return cols, ErrNoGeometryColumns
+	}
+	return cols, nil
+}
+
+func (cols columns) has(i int) bool {
+	return i == cols.lon || i == cols.lat || i == cols.z || i == cols.wkt
+}
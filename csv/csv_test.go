@@ -0,0 +1,164 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package csv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/gjson"
+)
+
+func expect(t testing.TB, what bool) {
+	t.Helper()
+	if !what {
+		t.Fatal("expection failure")
+	}
+}
+
+func TestReadLonLat(t *testing.T) {
+	fc, errs, err := Read(strings.NewReader("\ufeffName,Latitude,Longitude,Pop,Area,Open,Note\n"+
+		"Phoenix,33.45,-112.07,1608139,1340.6,true,\n"+
+		"Tucson,32.22,-110.97,542629,627,FALSE,\"Old\nPueblo\"\n"+
+		"Nowhere,95,0,1,1,true,\n"+
+		"Mesa,33.42,-111.83,504258,,true,x\n"), nil)
+	expect(t, err == nil)
+	expect(t, len(errs) == 1)
+	// the line of the row after a quoted newline
+	expect(t, errs[0].Line == 5 && errs[0].Err == ErrInvalidCoordinates)
+	expect(t, errs[0].Error() == "line 5: invalid coordinates")
+	features := fc.Children()
+	expect(t, len(features) == 3)
+	expect(t, features[0].JSON() == `{"type":"Feature","geometry":{"type":"Point","coordinates":[-112.07,33.45]},"properties":{"Name":"Phoenix","Pop":1608139,"Area":1340.6,"Open":true,"Note":null}}`)
+	expect(t, features[1].JSON() == `{"type":"Feature","geometry":{"type":"Point","coordinates":[-110.97,32.22]},"properties":{"Name":"Tucson","Pop":542629,"Area":627,"Open":false,"Note":"Old\nPueblo"}}`)
+	expect(t, features[2].JSON() == `{"type":"Feature","geometry":{"type":"Point","coordinates":[-111.83,33.42]},"properties":{"Name":"Mesa","Pop":504258,"Area":null,"Open":true,"Note":"x"}}`)
+
+	// configured columns, a delimiter, and z values
+	fc, errs, err = Read(strings.NewReader("a\tb\th\tid\n"+
+		"1\t2\t3\t007\n4\t5\t\tx\n6\n"), &Options{Comma: '\t', Lon: "a",
+		Lat: "b", Z: "h"})
+	expect(t, err == nil)
+	expect(t, len(errs) == 1 && errs[0].Line == 4 &&
+		errs[0].Err == csv.ErrFieldCount)
+	expect(t, fc.JSON() == `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2,3]},"properties":{"id":"007"}},{"type":"Feature","geometry":{"type":"Point","coordinates":[4,5]},"properties":{"id":"x"}}]}`)
+
+	// leading zeros and long integers are strings
+	fc, _, err = Read(strings.NewReader("lon,lat,zip,id,n\n"+
+		"1,2,01234,12345678901234567890,0\n3,4,85001,1,-0.5\n"), nil)
+	expect(t, err == nil)
+	expect(t, fc.JSON() == `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":{"zip":"01234","id":"12345678901234567890","n":0}},{"type":"Feature","geometry":{"type":"Point","coordinates":[3,4]},"properties":{"zip":"85001","id":"1","n":-0.5}}]}`)
+
+	// only decimal numbers are numbers
+	fc, _, err = Read(strings.NewReader("lon,lat,hex,inf,under,exp\n"+
+		"1,2,0x10,Inf,1_000,1e3\n3,4,5,NaN,5,.5\n"), nil)
+	expect(t, err == nil)
+	expect(t, fc.JSON() == `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":{"hex":"0x10","inf":"Inf","under":"1_000","exp":1000}},{"type":"Feature","geometry":{"type":"Point","coordinates":[3,4]},"properties":{"hex":"5","inf":"NaN","under":"5","exp":0.5}}]}`)
+
+	_, _, err = Read(strings.NewReader("a,b\n1,2\n"), nil)
+	expect(t, err == ErrNoGeometryColumns)
+	_, _, err = Read(strings.NewReader("lon,lat\n1,2\n"), &Options{WKT: "wkt"})
+	expect(t, err == ErrNoGeometryColumns)
+	_, _, err = Read(strings.NewReader(""), nil)
+	expect(t, err != nil)
+}
+
+func TestReadWKT(t *testing.T) {
+	fc, errs, err := Read(strings.NewReader(`id,geom,x
+1,POINT (1 2),a
+2,POINT Z (1 2 3),b
+3,"POINTM(1 2 3)",c
+4,"LINESTRING (0 0, 1 1, 2 2)",d
+5,"POLYGON ((0 0, 10 0, 10 10, 0 0), (1 1, 2 1, 2 2, 1 1))",e
+6,"MULTIPOINT ((1 2), (3 4))",f
+7,"MULTIPOINT (1 2, 3 4)",g
+8,"MULTILINESTRING ((0 0, 1 1), (2 2, 3 3))",h
+9,"multipolygon zm (((0 0 1 9, 1 0 1 9, 1 1 1 9, 0 0 1 9)))",i
+10,"SRID=4326;GEOMETRYCOLLECTION (POINT (1 2), LINESTRING (0 0, 1 1))",j
+11,POINT EMPTY,k
+12,"POLYGON ((0 0, 1 0, 1 1))",l
+13,POINT (1),m
+14,POINT (1 2) x,n
+15,,o
+`), nil)
+	expect(t, err == nil)
+	expect(t, len(errs) == 5)
+	for i, rerr := range errs {
+		expect(t, rerr.Line == 12+i)
+	}
+	expect(t, errs[0].Err == ErrInvalidWKT)
+	geoms := []string{
+		`{"type":"Point","coordinates":[1,2]}`,
+		`{"type":"Point","coordinates":[1,2,3]}`,
+		`{"type":"Point","coordinates":[1,2]}`,
+		`{"type":"LineString","coordinates":[[0,0],[1,1],[2,2]]}`,
+		`{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,0]],[[1,1],[2,1],[2,2],[1,1]]]}`,
+		`{"type":"MultiPoint","coordinates":[[1,2],[3,4]]}`,
+		`{"type":"MultiPoint","coordinates":[[1,2],[3,4]]}`,
+		`{"type":"MultiLineString","coordinates":[[[0,0],[1,1]],[[2,2],[3,3]]]}`,
+		`{"type":"MultiPolygon","coordinates":[[[[0,0,1],[1,0,1],[1,1,1],[0,0,1]]]]}`,
+		`{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2]},{"type":"LineString","coordinates":[[0,0],[1,1]]}]}`,
+	}
+	features := fc.Children()
+	expect(t, len(features) == len(geoms))
+	for i, f := range features {
+		expect(t, f.(*geojson.Feature).Base().JSON() == geoms[i])
+		expect(t, gjson.Get(f.JSON(), "properties.id").Int() == int64(i+1))
+	}
+}
+
+func TestWrite(t *testing.T) {
+	obj, err := geojson.Parse(`{"type":"FeatureCollection","features":[
+		{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2,3]},
+			"properties":{"name":"a, b","tags":[1,2],"addr":{"city":"x","zip":85001}}},
+		{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[0,0],[4,0],[4,4],[0,4],[0,0]]]},
+			"properties":{"name":null,"open":true}},
+		{"type":"MultiLineString","coordinates":[[[0,0,1],[1,1]],[[2,2,2],[3,3,3]]]}
+	]}`, nil)
+	expect(t, err == nil)
+	fc := obj.(*geojson.FeatureCollection)
+	var buf bytes.Buffer
+	expect(t, Write(&buf, fc, nil) == nil)
+	expect(t, buf.String() == `WKT,name,tags,addr.city,addr.zip,open
+POINT Z (1 2 3),"a, b","[1,2]",x,85001,
+"POLYGON ((0 0, 4 0, 4 4, 0 4, 0 0))",,,,,true
+"MULTILINESTRING Z ((0 0 1, 1 1 0), (2 2 2, 3 3 3))",,,,,
+`)
+	fc2, errs, err := Read(&buf, nil)
+	expect(t, err == nil && len(errs) == 0)
+	features := fc2.Children()
+	expect(t, features[0].JSON() == `{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2,3]},"properties":{"name":"a, b","tags":"[1,2]","addr.city":"x","addr.zip":85001,"open":null}}`)
+	expect(t, features[1].(*geojson.Feature).Base().JSON() ==
+		fc.Children()[1].(*geojson.Feature).Base().JSON())
+
+	// longitude and latitude columns
+	buf.Reset()
+	opts := &Options{Comma: ';', Lon: "lon", Lat: "lat", Z: "z"}
+	expect(t, Write(&buf, fc, opts) == nil)
+	expect(t, buf.String() == `lon;lat;z;name;tags;addr.city;addr.zip;open
+1;2;3;a, b;[1,2];x;85001;
+2;2;;;;;;true
+1.5;1.5;;;;;;
+`)
+	buf.Reset()
+	expect(t, Write(&buf, geojson.NewPoint(geojson.NewPoint(
+		fc.Children()[0].Center()).Base()), &Options{WKT: "shape"}) == nil)
+	expect(t, buf.String() == "shape\nPOINT (1 2)\n")
+
+	// null properties have no columns
+	obj, err = geojson.Parse(`{"type":"FeatureCollection","features":[
+		{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":{"s":"a"}},
+		{"type":"Feature","geometry":{"type":"Point","coordinates":[3,4]},"properties":null}
+	]}`, nil)
+	expect(t, err == nil)
+	buf.Reset()
+	expect(t, Write(&buf, obj.(*geojson.FeatureCollection), nil) == nil)
+	expect(t, buf.String() == "WKT,s\nPOINT (1 2),a\nPOINT (3 4),\n")
+	fc2, errs, err = Read(&buf, nil)
+	expect(t, err == nil && len(errs) == 0)
+	expect(t, fc2.Children()[1].JSON() == `{"type":"Feature","geometry":{"type":"Point","coordinates":[3,4]},"properties":{"s":null}}`)
+}
@@ -0,0 +1,248 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package csv
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/geojson"
+)
+
+// kind is the type of the values of a property column
+type kind int
+
+const (
+	kindNone kind = iota // only empty values
+	kindInt
+	kindFloat
+	kindBool
+	kindString
+)
+
+// valueKind returns the kind of a value. Numbers with leading zeros, such as
+// ZIP codes, and integers that don't fit in an int64, such as long ids, are
+// strings, so that they're not changed.
+func valueKind(s string) kind {
+	digits := strings.TrimLeft(s, "+-")
+	if len(digits) > 1 && digits[0] == '0' && digits[1] >= '0' &&
+		digits[1] <= '9' {
+		return kindString
+	}
+	if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return kindInt
+	} else if err.(*strconv.NumError).Err == strconv.ErrRange {
+		return kindString
+	}
+	if isDecimal(s) {
+		if _, err := strconv.ParseFloat(s, 64); err == nil {
+			return kindFloat
+		}
+	}
+	if strings.EqualFold(s, "true") || strings.EqualFold(s, "false") {
+		return kindBool
+	}
+	return kindString
+}
+
+// isDecimal returns true if the value is a decimal number, such as "-1.5" or
+// "2e10". Unlike strconv.ParseFloat, it does not allow hexadecimal numbers,
+// underscores, or the names of infinity and NaN.
+func isDecimal(s string) bool {
+	i := 0
+	if i < len(s) && (s[i] == '+' || s[i] == '-') {
+		i++
+	}
+	var digits bool
+	for ; i < len(s) && s[i] >= '0' && s[i] <= '9'; i++ {
+		digits = true
+	}
+	if i < len(s) && s[i] == '.' {
+		for i++; i < len(s) && s[i] >= '0' && s[i] <= '9'; i++ {
+			digits = true
+		}
+	}
+	if !digits {
+		return false
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			i++
+		}
+		if i == len(s) {
+			return false
+		}
+		for ; i < len(s) && s[i] >= '0' && s[i] <= '9'; i++ {
+		}
+	}
+	return i == len(s)
+}
+
+func mergeKinds(a, b kind) kind {
+	switch {
+	case a == kindNone || a == b:
+		return b
+	case b == kindNone:
+		return a
+	case (a == kindInt && b == kindFloat) || (a == kindFloat && b == kindInt):
+		return kindFloat
+	}
+	return kindString
+}
+
+type row struct {
+	line   int
+	fields []string
+}
+
+// Read returns the rows of delimited text as a FeatureCollection. The first
+// row is the header. The geometry of each row is read from the configured
+// columns, or from the columns that are found by their names, such as "WKT",
+// or "lon" and "lat". The other columns become properties of the features,
+// typed as integers, numbers, booleans, or strings by the values in the
+// column, where empty values are null, and numbers with leading zeros or
+// integers that don't fit in an int64 are strings. Only decimal numbers are
+// numbers. Rows with an invalid geometry or with a wrong number of fields are
+// left out and returned as row errors.
+func Read(r io.Reader, opts *Options) (*geojson.FeatureCollection,
+	[]*RowError, error,
+) {
+	if opts == nil {
+		opts = DefaultOptions
+	}
+	cr := csv.NewReader(r)
+	if opts.Comma != 0 {
+		cr.Comma = opts.Comma
+	}
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, nil, err
+	}
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	cols, err := findColumns(header, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	var rows []row
+	var errs []*RowError
+	for {
+		fields, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if perr, ok := err.(*csv.ParseError); ok {
+			errs = append(errs, &RowError{Line: perr.StartLine, Err: perr.Err})
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		if len(fields) != len(header) {
+			errs = append(errs, &RowError{Line: line, Err: csv.ErrFieldCount})
+			continue
+		}
+		rows = append(rows, row{line: line, fields: fields})
+	}
+	kinds := make([]kind, len(header))
+	for _, row := range rows {
+		for i, field := range row.fields {
+			if field = strings.TrimSpace(field); field != "" && !cols.has(i) {
+				kinds[i] = mergeKinds(kinds[i], valueKind(field))
+			}
+		}
+	}
+	features := make([]geojson.Object, 0, len(rows))
+	for _, row := range rows {
+		geom, err := cols.geometry(row.fields)
+		if err != nil {
+			errs = append(errs, &RowError{Line: row.line, Err: err})
+			continue
+		}
+		members := []byte(`{"properties":{`)
+		var n int
+		for i, field := range row.fields {
+			if cols.has(i) {
+				continue
+			}
+			if n > 0 {
+				members = append(members, ',')
+			}
+			n++
+			members = appendString(members, strings.TrimSpace(header[i]))
+			members = append(members, ':')
+			members = appendValue(members, field, kinds[i])
+		}
+		members = append(members, "}}"...)
+		features = append(features, geojson.NewFeature(geom, string(members)))
+	}
+	return geojson.NewFeatureCollection(features), errs, nil
+}
+
+// geometry returns the geometry of the fields of a row
+func (cols columns) geometry(fields []string) (geojson.Object, error) {
+	var geom []byte
+	if cols.wkt != -1 {
+		var err error
+		geom, err = parseWKT(fields[cols.wkt])
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		lon, err := strconv.ParseFloat(strings.TrimSpace(fields[cols.lon]), 64)
+		if err != nil || lon < -180 || lon > 180 {
+			return nil, ErrInvalidCoordinates
+		}
+		lat, err := strconv.ParseFloat(strings.TrimSpace(fields[cols.lat]), 64)
+		if err != nil || lat < -90 || lat > 90 {
+			return nil, ErrInvalidCoordinates
+		}
+		geom = []byte(`{"type":"Point","coordinates":[`)
+		geom = strconv.AppendFloat(geom, lon, 'f', -1, 64)
+		geom = append(geom, ',')
+		geom = strconv.AppendFloat(geom, lat, 'f', -1, 64)
+		if cols.z != -1 {
+			if s := strings.TrimSpace(fields[cols.z]); s != "" {
+				z, err := strconv.ParseFloat(s, 64)
+				if err != nil || math.IsInf(z, 0) || math.IsNaN(z) {
+					return nil, ErrInvalidCoordinates
+				}
+				geom = append(geom, ',')
+				geom = strconv.AppendFloat(geom, z, 'f', -1, 64)
+			}
+		}
+		geom = append(geom, "]}"...)
+	}
+	return geojson.Parse(string(geom), nil)
+}
+
+func appendString(dst []byte, s string) []byte {
+	b, _ := json.Marshal(s)
+	return append(dst, b...)
+}
+
+// appendValue appends the JSON of a field with the kind of its column
+func appendValue(dst []byte, field string, kind kind) []byte {
+	s := strings.TrimSpace(field)
+	switch {
+	case s == "":
+		return append(dst, "null"...)
+	case kind == kindInt:
+		n, _ := strconv.ParseInt(s, 10, 64)
+		return strconv.AppendInt(dst, n, 10)
+	case kind == kindFloat:
+		f, _ := strconv.ParseFloat(s, 64)
+		return strconv.AppendFloat(dst, f, 'f', -1, 64)
+	case kind == kindBool:
+		return strconv.AppendBool(dst, strings.EqualFold(s, "true"))
+	}
+	return appendString(dst, field)
+}
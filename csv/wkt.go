@@ -0,0 +1,300 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package csv
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// wktTypes are the GeoJSON types of WKT types
+var wktTypes = map[string]string{
+	"POINT":              "Point",
+	"LINESTRING":         "LineString",
+	"POLYGON":            "Polygon",
+	"MULTIPOINT":         "MultiPoint",
+	"MULTILINESTRING":    "MultiLineString",
+	"MULTIPOLYGON":       "MultiPolygon",
+	"GEOMETRYCOLLECTION": "GeometryCollection",
+}
+
+type wktParser struct {
+	s   string
+	pos int
+}
+
+// parseWKT returns the GeoJSON geometry of WKT, or of EWKT with an SRID.
+// M values are left out, and empty geometries are not supported.
+func parseWKT(s string) ([]byte, error) {
+	p := wktParser{s: s}
+	if i := strings.IndexByte(s, ';'); i >= 0 &&
+		strings.HasPrefix(strings.ToUpper(strings.TrimSpace(s[:i])), "SRID=") {
+		p.pos = i + 1
+	}
+	dst, err := p.appendGeometry(nil)
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.pos != len(p.s) {
+		return nil, ErrInvalidWKT
+	}
+	return dst, nil
+}
+
+func (p *wktParser) skipSpace() {
+	for p.pos < len(p.s) && strings.IndexByte(" \t\r\n", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// word returns the next word in upper case
+func (p *wktParser) word() string {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.s) && (p.s[p.pos] >= 'a' && p.s[p.pos] <= 'z' ||
+		p.s[p.pos] >= 'A' && p.s[p.pos] <= 'Z') {
+		p.pos++
+	}
+	return strings.ToUpper(p.s[start:p.pos])
+}
+
+// next consumes the next character when it is c
+func (p *wktParser) next(c byte) bool {
+	p.skipSpace()
+	if p.pos < len(p.s) && p.s[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *wktParser) appendGeometry(dst []byte) ([]byte, error) {
+	word := p.word()
+	var tag string
+	for _, suffix := range []string{"ZM", "Z", "M"} {
+		if _, ok := wktTypes[word]; !ok && strings.HasSuffix(word, suffix) {
+			word, tag = word[:len(word)-len(suffix)], suffix
+		}
+	}
+	typ, ok := wktTypes[word]
+	if !ok {
+		return nil, ErrInvalidWKT
+	}
+	if tag == "" {
+		start := p.pos
+		if tag = p.word(); tag != "Z" && tag != "M" && tag != "ZM" {
+			p.pos, tag = start, ""
+		}
+	}
+	dst = append(dst, `{"type":"`...)
+	dst = append(dst, typ...)
+	if typ == "GeometryCollection" {
+		dst = append(dst, `","geometries":`...)
+	} else {
+		dst = append(dst, `","coordinates":`...)
+	}
+	var err error
+	switch typ {
+	case "Point":
+		if !p.next('(') {
+			return nil, ErrInvalidWKT
+		}
+		if dst, err = p.appendPosition(dst, tag); err != nil {
+			return nil, err
+		}
+		if !p.next(')') {
+			return nil, ErrInvalidWKT
+		}
+	case "LineString":
+		dst, err = p.appendPositions(dst, tag, false)
+	case "MultiPoint":
+		dst, err = p.appendPositions(dst, tag, true)
+	case "Polygon", "MultiLineString":
+		dst, err = p.appendList(dst, func(dst []byte) ([]byte, error) {
+			return p.appendPositions(dst, tag, false)
+		})
+	case "MultiPolygon":
+		dst, err = p.appendList(dst, func(dst []byte) ([]byte, error) {
+			return p.appendList(dst, func(dst []byte) ([]byte, error) {
+				return p.appendPositions(dst, tag, false)
+			})
+		})
+	case "GeometryCollection":
+		dst, err = p.appendList(dst, p.appendGeometry)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append(dst, '}'), nil
+}
+
+// appendList appends a parenthesized list of items as a JSON array
+func (p *wktParser) appendList(dst []byte,
+	item func(dst []byte) ([]byte, error),
+) ([]byte, error) {
+	if !p.next('(') {
+		return nil, ErrInvalidWKT
+	}
+	dst = append(dst, '[')
+	for i := 0; ; i++ {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		var err error
+		if dst, err = item(dst); err != nil {
+			return nil, err
+		}
+		if p.next(')') {
+			return append(dst, ']'), nil
+		}
+		if !p.next(',') {
+			return nil, ErrInvalidWKT
+		}
+	}
+}
+
+// appendPositions appends a list of positions, which may each be in
+// parentheses for multipoints.
+func (p *wktParser) appendPositions(dst []byte, tag string, multi bool,
+) ([]byte, error) {
+	return p.appendList(dst, func(dst []byte) ([]byte, error) {
+		if multi && p.next('(') {
+			dst, err := p.appendPosition(dst, tag)
+			if err != nil || !p.next(')') {
+				return nil, ErrInvalidWKT
+			}
+			return dst, nil
+		}
+		return p.appendPosition(dst, tag)
+	})
+}
+
+// appendPosition appends a position with a Z value, if any
+func (p *wktParser) appendPosition(dst []byte, tag string) ([]byte, error) {
+	var vals []float64
+	for {
+		p.skipSpace()
+		start := p.pos
+		for p.pos < len(p.s) && strings.IndexByte("+-.0123456789eE",
+			p.s[p.pos]) >= 0 {
+			p.pos++
+		}
+		if start == p.pos {
+			break
+		}
+		f, err := strconv.ParseFloat(p.s[start:p.pos], 64)
+		if err != nil {
+			return nil, ErrInvalidWKT
+		}
+		vals = append(vals, f)
+	}
+	if len(vals) < 2 || len(vals) > 4 ||
+		(tag == "ZM" && len(vals) != 4) ||
+		((tag == "Z" || tag == "M") && len(vals) != 3) {
+		return nil, ErrInvalidWKT
+	}
+	if tag == "M" || len(vals) == 4 {
+		// leave out the m value
+		vals = vals[:len(vals)-1]
+	}
+	dst = append(dst, '[')
+	for i, v := range vals {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = strconv.AppendFloat(dst, v, 'f', -1, 64)
+	}
+	return append(dst, ']'), nil
+}
+
+// appendWKT appends the WKT of a GeoJSON geometry
+func appendWKT(dst []byte, g gjson.Result) []byte {
+	typ := g.Get("type").String()
+	for name, t := range wktTypes {
+		if t == typ {
+			dst = append(dst, name...)
+			break
+		}
+	}
+	if typ == "GeometryCollection" {
+		dst = append(dst, " ("...)
+		for i, child := range g.Get("geometries").Array() {
+			if i > 0 {
+				dst = append(dst, ", "...)
+			}
+			dst = appendWKT(dst, child)
+		}
+		return append(dst, ')')
+	}
+	coords := g.Get("coordinates")
+	hasZ := hasZ(coords)
+	if hasZ {
+		dst = append(dst, " Z"...)
+	}
+	dst = append(dst, ' ')
+	switch typ {
+	case "Point":
+		dst = append(dst, '(')
+		dst = appendWKTPosition(dst, coords, hasZ)
+		return append(dst, ')')
+	case "LineString", "MultiPoint":
+		return appendWKTList(dst, coords, 1, hasZ)
+	case "Polygon", "MultiLineString":
+		return appendWKTList(dst, coords, 2, hasZ)
+	}
+	return appendWKTList(dst, coords, 3, hasZ)
+}
+
+// hasZ returns true when any position has a Z value
+func hasZ(coords gjson.Result) bool {
+	vals := coords.Array()
+	if len(vals) > 0 && vals[0].Type == gjson.Number {
+		return len(vals) > 2
+	}
+	for _, child := range vals {
+		if hasZ(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// appendWKTList appends nested lists of positions in parentheses
+func appendWKTList(dst []byte, coords gjson.Result, depth int, hasZ bool,
+) []byte {
+	dst = append(dst, '(')
+	for i, child := range coords.Array() {
+		if i > 0 {
+			dst = append(dst, ", "...)
+		}
+		if depth == 1 {
+			dst = appendWKTPosition(dst, child, hasZ)
+		} else {
+			dst = appendWKTList(dst, child, depth-1, hasZ)
+		}
+	}
+	return append(dst, ')')
+}
+
+func appendWKTPosition(dst []byte, pos gjson.Result, hasZ bool) []byte {
+	vals := pos.Array()
+	n := 2
+	if hasZ {
+		n = 3
+	}
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			dst = append(dst, ' ')
+		}
+		var v float64
+		if i < len(vals) {
+			v = vals[i].Float()
+		}
+		dst = strconv.AppendFloat(dst, v, 'f', -1, 64)
+	}
+	return dst
+}
@@ -0,0 +1,122 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package csv
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/gjson"
+)
+
+// Write writes the features of a FeatureCollection, or any other object, as
+// delimited text. The geometries are written to the configured Lon and Lat
+// columns, where geometries that are not points are written at their
+// centers, or otherwise to a WKT column. The properties are flattened into a
+// column for each key, in the order they are first found, where the keys of
+// nested objects are joined with dots, and arrays are written as JSON.
+func Write(w io.Writer, obj geojson.Object, opts *Options) error {
+	if opts == nil {
+		opts = DefaultOptions
+	}
+	var objs []geojson.Object
+	if fc, ok := obj.(*geojson.FeatureCollection); ok {
+		objs = fc.Children()
+	} else {
+		objs = []geojson.Object{obj}
+	}
+	lonlat := opts.Lon != "" && opts.Lat != ""
+	var header []string
+	if lonlat {
+		header = append(header, opts.Lon, opts.Lat)
+		if opts.Z != "" {
+			header = append(header, opts.Z)
+		}
+	} else if opts.WKT != "" {
+		header = append(header, opts.WKT)
+	} else {
+		header = append(header, "WKT")
+	}
+	index := make(map[string]int)
+	rows := make([][]string, len(objs))
+	for i, obj := range objs {
+		var members string
+		if f, ok := obj.(*geojson.Feature); ok {
+			members = f.Members()
+			obj = f.Base()
+		}
+		if p, ok := obj.(interface{ Primative() geojson.Object }); ok {
+			obj = p.Primative()
+		}
+		row := make([]string, len(header))
+		if lonlat {
+			geom := gjson.Parse(obj.JSON())
+			if geom.Get("type").String() == "Point" {
+				coords := geom.Get("coordinates").Array()
+				row[0], row[1] = coords[0].Raw, coords[1].Raw
+				if len(coords) > 2 && opts.Z != "" {
+					row[2] = coords[2].Raw
+				}
+			} else {
+				center := obj.Center()
+				row[0] = strconv.FormatFloat(center.X, 'f', -1, 64)
+				row[1] = strconv.FormatFloat(center.Y, 'f', -1, 64)
+			}
+		} else {
+			row[0] = string(appendWKT(nil, gjson.Parse(obj.JSON())))
+		}
+		if props := gjson.Get(members, "properties"); props.IsObject() {
+			flatten("", props, func(key, value string) {
+				j, ok := index[key]
+				if !ok {
+					j = len(header)
+					index[key] = j
+					header = append(header, key)
+				}
+				for len(row) <= j {
+					row = append(row, "")
+				}
+				row[j] = value
+			})
+		}
+		rows[i] = row
+	}
+	cw := csv.NewWriter(w)
+	if opts.Comma != 0 {
+		cw.Comma = opts.Comma
+	}
+	cw.Write(header)
+	for _, row := range rows {
+		for len(row) < len(header) {
+			row = append(row, "")
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// flatten calls iter for each value of an object, where the keys of nested
+// objects are joined with dots. Null values are empty.
+func flatten(prefix string, obj gjson.Result,
+	iter func(key, value string),
+) {
+	obj.ForEach(func(key, value gjson.Result) bool {
+		name := prefix + key.String()
+		switch {
+		case value.IsObject():
+			flatten(name+".", value, iter)
+		case value.Type == gjson.String:
+			iter(name, value.String())
+		case value.Type == gjson.Null:
+			iter(name, "")
+		default:
+			iter(name, value.Raw)
+		}
+		return true
+	})
+}
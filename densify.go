@@ -0,0 +1,128 @@
+package geojson
+
+import (
+	"math"
+
+	"github.com/tidwall/geojson/geo"
+	"github.com/tidwall/geojson/geometry"
+)
+
+// DensifyMode is how Densify measures and follows the segments of an object
+type DensifyMode byte
+
+const (
+	// DensifyDegrees spaces the vertices in degrees, along the straight
+	// lon/lat segments.
+	DensifyDegrees DensifyMode = iota
+	// DensifyMeters spaces the vertices in meters, along the straight
+	// lon/lat segments.
+	DensifyMeters
+	// DensifyGeodesic spaces the vertices in meters, along the great circle
+	// path between the ends of each segment. Segments are expected to span
+	// less than 180 degrees of longitude.
+	DensifyGeodesic
+)
+
+// Densify returns a copy of a line or polygon object where vertices have
+// been inserted along each segment, so that no two neighboring vertices are
+// farther apart than spacing. Extra coordinate values, such as Z and M, are
+// interpolated. Objects without segments are returned as is.
+func Densify(obj Object, spacing float64, mode DensifyMode,
+	opts *geometry.IndexOptions,
+) Object {
+	if !(spacing > 0) {
+		return obj
+	}
+	return rebuildObject(obj, func(points []geometry.Point, values []float64,
+		dims int, closed bool,
+	) ([]geometry.Point, []float64) {
+		return densifyPoints(points, values, dims, closed, spacing, mode)
+	}, false, opts)
+}
+
+func densifyPoints(points []geometry.Point, values []float64, dims int,
+	closed bool, spacing float64, mode DensifyMode,
+) ([]geometry.Point, []float64) {
+	if len(points) < 2 {
+		return points, values
+	}
+	// rings that do not repeat their first point still have a closing segment
+	open := closed && points[0] != points[len(points)-1]
+	if open {
+		points = append(points[:len(points):len(points)], points[0])
+		values = append(values[:len(values):len(values)], values[:dims]...)
+	}
+	var npoints []geometry.Point
+	var nvalues []float64
+	for i := 0; i < len(points)-1; i++ {
+		a, b := points[i], points[i+1]
+		n := densifySteps(a, b, spacing, mode)
+		for j := 0; j < n; j++ {
+			t := float64(j) / float64(n)
+			npoints = append(npoints, densifyPoint(a, b, t, mode))
+			for k := 0; k < dims; k++ {
+				va, vb := values[i*dims+k], values[(i+1)*dims+k]
+				nvalues = append(nvalues, va+(vb-va)*t)
+			}
+		}
+	}
+	if !open {
+		npoints = append(npoints, points[len(points)-1])
+		nvalues = append(nvalues, values[len(values)-dims:]...)
+	}
+	return npoints, nvalues
+}
+
+// densifySteps returns the number of pieces that a segment is split into.
+func densifySteps(a, b geometry.Point, spacing float64, mode DensifyMode) int {
+	var length float64
+	if mode == DensifyDegrees {
+		length = math.Hypot(b.X-a.X, b.Y-a.Y)
+	} else {
+		length = geo.DistanceTo(a.Y, a.X, b.Y, b.X)
+	}
+	n := math.Ceil(length / spacing)
+	if !(n > 1) {
+		return 1
+	}
+	if mode == DensifyMeters {
+		// The segment is split evenly in degrees, which makes the pieces
+		// nearest to the equator the longest. Add pieces until the longest
+		// one fits.
+		for {
+			longest := densifyLongest(a, b, int(n))
+			if longest <= spacing {
+				break
+			}
+			n = math.Max(n+1, math.Ceil(n*longest/spacing))
+		}
+	}
+	return int(n)
+}
+
+// densifyLongest returns the length in meters of the longest piece of a
+// straight lon/lat segment that is split evenly into n pieces.
+func densifyLongest(a, b geometry.Point, n int) float64 {
+	var longest float64
+	prev := a
+	for j := 1; j <= n; j++ {
+		next := densifyPoint(a, b, float64(j)/float64(n), DensifyMeters)
+		longest = math.Max(longest, geo.DistanceTo(prev.Y, prev.X, next.Y, next.X))
+		prev = next
+	}
+	return longest
+}
+
+// densifyPoint returns the point at t, from 0 to 1, along a segment.
+func densifyPoint(a, b geometry.Point, t float64, mode DensifyMode,
+) geometry.Point {
+	linear := geometry.Point{X: a.X + (b.X-a.X)*t, Y: a.Y + (b.Y-a.Y)*t}
+	if mode != DensifyGeodesic || t == 0 {
+		return linear
+	}
+	lat, lon := geo.IntermediatePoint(a.Y, a.X, b.Y, b.X, t)
+	// keep the longitude continuous with the segment, which may extend past
+	// the antimeridian.
+	lon += 360 * math.Round((linear.X-lon)/360)
+	return geometry.Point{X: lon, Y: lat}
+}
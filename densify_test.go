@@ -0,0 +1,127 @@
+package geojson
+
+import (
+	"testing"
+
+	"github.com/tidwall/geojson/geo"
+	"github.com/tidwall/geojson/geometry"
+)
+
+func TestDensifyLineString(t *testing.T) {
+	line := expectJSON(t, `{"type":"LineString","coordinates":[[0,0],[4,0],[4,2]]}`, nil)
+	dense := Densify(line, 1, DensifyDegrees, nil)
+	expect(t, dense.JSON() == `{"type":"LineString","coordinates":[[0,0],[1,0],[2,0],[3,0],[4,0],[4,1],[4,2]]}`)
+
+	// z values are interpolated
+	line = expectJSON(t, `{"type":"LineString","coordinates":[[0,0,10],[4,0,50]]}`, nil)
+	dense = Densify(line, 1, DensifyDegrees, nil)
+	expect(t, dense.JSON() == `{"type":"LineString","coordinates":[[0,0,10],[1,0,20],[2,0,30],[3,0,40],[4,0,50]]}`)
+
+	// spacing in meters
+	line = expectJSON(t, `{"type":"LineString","coordinates":[[-100,40],[-90,45]]}`, nil)
+	for _, mode := range []DensifyMode{DensifyMeters, DensifyGeodesic} {
+		dense = Densify(line, 50000, mode, nil)
+		base := dense.(*LineString).Base()
+		expect(t, base.NumPoints() > 2)
+		expect(t, base.PointAt(0) == P(-100, 40))
+		expect(t, base.PointAt(base.NumPoints()-1) == P(-90, 45))
+		for i := 0; i < base.NumSegments(); i++ {
+			seg := base.SegmentAt(i)
+			expect(t, geoDistancePoints(seg.A, seg.B) <= 50000+1e-6)
+		}
+	}
+
+	// nothing changes without a spacing, or without segments
+	expect(t, Densify(line, 0, DensifyDegrees, nil) == line)
+	point := PO(1, 2)
+	expect(t, Densify(point, 1, DensifyDegrees, nil).JSON() == point.JSON())
+}
+
+func TestDensifyGeodesic(t *testing.T) {
+	// the great circle bends toward the pole
+	line := LO([]geometry.Point{P(-100, 45), P(0, 45)})
+	dense := Densify(line, 100000, DensifyGeodesic, nil).(*LineString)
+	base := dense.Base()
+	for i := 1; i < base.NumPoints()-1; i++ {
+		point := base.PointAt(i)
+		expect(t, point.Y > 45)
+		// every vertex is on the great circle
+		xtd := geo.CrossTrackDistance(point.Y, point.X, 45, -100, 45, 0)
+		expect(t, xtd < 1e-3 && xtd > -1e-3)
+	}
+
+	// longitudes are kept continuous past the antimeridian
+	line = LO([]geometry.Point{P(170, 10), P(190, 10)})
+	base = Densify(line, 100000, DensifyGeodesic, nil).(*LineString).Base()
+	for i := 1; i < base.NumPoints(); i++ {
+		expect(t, base.PointAt(i).X > base.PointAt(i-1).X)
+	}
+	expect(t, base.PointAt(base.NumPoints()-1) == P(190, 10))
+}
+
+func TestDensifyPolygon(t *testing.T) {
+	poly := expectJSON(t, `{"type":"Polygon","coordinates":[[[0,0,1],[2,0,1],[2,2,3],[0,2,3],[0,0,1]],[[0.5,0.5,0],[1.5,0.5,0],[1.5,1.5,0],[0.5,0.5,0]]]}`, nil)
+	dense := Densify(poly, 1, DensifyDegrees, nil)
+	expect(t, dense.JSON() == `{"type":"Polygon","coordinates":[[[0,0,1],[1,0,1],[2,0,1],[2,1,2],[2,2,3],[1,2,3],[0,2,3],[0,1,2],[0,0,1]],[[0.5,0.5,0],[1.5,0.5,0],[1.5,1.5,0],[1,1,0],[0.5,0.5,0]]]}`)
+	expect(t, dense.Valid())
+	expect(t, dense.Contains(PO(1.5, 0.25)))
+	expect(t, !dense.Contains(PO(1.25, 0.75)))
+
+	// rects become polygons
+	rect := RO(0, 0, 2, 1)
+	dense = Densify(rect, 1, DensifyDegrees, nil)
+	expect(t, dense.JSON() == `{"type":"Polygon","coordinates":[[[0,0],[1,0],[2,0],[2,1],[1,1],[0,1],[0,0]]]}`)
+
+	// re-indexed with the provided options
+	dense = Densify(poly, 0.01, DensifyDegrees, &geometry.IndexOptions{
+		Kind:      geometry.RTree,
+		MinPoints: 64,
+	})
+	expect(t, dense.(*Polygon).Base().Exterior.Index() != nil)
+}
+
+func TestDensifyCollections(t *testing.T) {
+	expectDensify := func(input, output string) {
+		t.Helper()
+		g, err := Parse(input, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dense := Densify(g, 1, DensifyDegrees, nil)
+		if dense.JSON() != output {
+			t.Fatalf("expected '%v', got '%v'", output, dense.JSON())
+		}
+	}
+	expectDensify(
+		`{"type":"MultiLineString","coordinates":[[[0,0],[2,0]],[[0,1],[0,3]]],"id":1}`,
+		`{"type":"MultiLineString","coordinates":[[[0,0],[1,0],[2,0]],[[0,1],[0,2],[0,3]]],"id":1}`,
+	)
+	expectDensify(
+		`{"type":"MultiPolygon","coordinates":[[[[0,0],[2,0],[2,1],[0,1],[0,0]]]]}`,
+		`{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[2,0],[2,1],[1,1],[0,1],[0,0]]]]}`,
+	)
+	expectDensify(
+		`{"type":"Feature","id":"a","geometry":{"type":"LineString","coordinates":[[0,0],[2,0]]},"properties":{"name":"b"}}`,
+		`{"type":"Feature","geometry":{"type":"LineString","coordinates":[[0,0],[1,0],[2,0]]},"id":"a","properties":{"name":"b"}}`,
+	)
+	expectDensify(
+		`{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[0,0]},"properties":{}},{"type":"Feature","geometry":{"type":"LineString","coordinates":[[0,0],[0,2]]},"properties":{}}]}`,
+		`{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[0,0]},"properties":{}},{"type":"Feature","geometry":{"type":"LineString","coordinates":[[0,0],[0,1],[0,2]]},"properties":{}}]}`,
+	)
+	expectDensify(
+		`{"type":"GeometryCollection","geometries":[{"type":"LineString","coordinates":[[0,0],[0,2]]}]}`,
+		`{"type":"GeometryCollection","geometries":[{"type":"LineString","coordinates":[[0,0],[0,1],[0,2]]}]}`,
+	)
+	// the children are indexed like the original children
+	input := `{"type":"MultiLineString","coordinates":[[[0,0],[2,0]],[[0,1],[0,3]]]}`
+	for _, indexChildren := range []int{0, 1, 64} {
+		opts := *DefaultParseOptions
+		opts.IndexChildren = indexChildren
+		g, err := Parse(input, &opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dense := Densify(g, 1, DensifyDegrees, nil).(Collection)
+		expect(t, dense.Indexed() == g.(Collection).Indexed())
+	}
+}
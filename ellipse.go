@@ -0,0 +1,320 @@
+package geojson
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/tidwall/geojson/geo"
+	"github.com/tidwall/geojson/geometry"
+	"github.com/tidwall/gjson"
+)
+
+// Ellipse is an ellipse on the surface of the earth. The semi-major axis
+// points in the direction of the azimuth, which is a bearing in degrees
+// clockwise from north.
+//
+// Its GeoJSON representation is a special Feature syntax such as:
+//
+//	{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},
+//	 "properties":{"type":"Ellipse","semi_major":2000,"semi_minor":1000,
+//	 "azimuth":45,"radius_units":"m"}}
+type Ellipse struct {
+	object    Object
+	center    geometry.Point
+	semiMajor float64
+	semiMinor float64
+	major     float64  // semi-major axis in the original units
+	minor     float64  // semi-minor axis in the original units
+	units     geo.Unit // units of the original axes
+	azimuth   float64
+	steps     int
+	id        gjson.Result // id of the Feature, for filters
+	props     *Properties  // properties of the Feature, for filters
+}
+
+// NewEllipse returns an ellipse object
+func NewEllipse(center geometry.Point, semiMajor, semiMinor, azimuth float64,
+	steps int,
+) *Ellipse {
+	return NewEllipseUnits(center, semiMajor, semiMinor, geo.Meters, azimuth,
+		steps)
+}
+
+// NewEllipseUnits returns an ellipse object with axes in the provided units.
+// The units are kept when the ellipse is converted to JSON.
+func NewEllipseUnits(center geometry.Point, semiMajor, semiMinor float64,
+	units geo.Unit, azimuth float64, steps int,
+) *Ellipse {
+	if steps < 3 {
+		steps = 3
+	}
+	g := new(Ellipse)
+	g.center = center
+	g.major = semiMajor
+	g.minor = semiMinor
+	g.units = units
+	g.semiMajor = units.ToMeters(semiMajor)
+	g.semiMinor = units.ToMeters(semiMinor)
+	g.azimuth = azimuth
+	g.steps = steps
+	g.object = g.makeObject()
+	return g
+}
+
+// AppendJSON ...
+func (g *Ellipse) AppendJSON(dst []byte) []byte {
+	major, minor, units := g.major, g.minor, g.units.String()
+	if units == "" {
+		// unknown units are written as meters
+		major, minor, units = g.semiMajor, g.semiMinor, geo.Meters.String()
+	}
+	var props []byte
+	props = append(props, `"semi_major":`...)
+	props = strconv.AppendFloat(props, major, 'f', -1, 64)
+	props = append(props, `,"semi_minor":`...)
+	props = strconv.AppendFloat(props, minor, 'f', -1, 64)
+	props = append(props, `,"azimuth":`...)
+	props = strconv.AppendFloat(props, g.azimuth, 'f', -1, 64)
+	props = append(props, `,"radius_units":"`...)
+	props = append(props, units...)
+	props = append(props, '"')
+	return appendJSONShape(dst, g.center, "Ellipse", props)
+}
+
+// JSON ...
+func (g *Ellipse) JSON() string {
+	return string(g.AppendJSON(nil))
+}
+
+// MarshalJSON ...
+func (g *Ellipse) MarshalJSON() ([]byte, error) {
+	return g.AppendJSON(nil), nil
+}
+
+// String ...
+func (g *Ellipse) String() string {
+	return string(g.AppendJSON(nil))
+}
+
+// Center returns the ellipse's center point
+func (g *Ellipse) Center() geometry.Point {
+	return g.center
+}
+
+// SemiMajor returns the length of the semi-major axis in meters
+func (g *Ellipse) SemiMajor() float64 {
+	return g.semiMajor
+}
+
+// SemiMinor returns the length of the semi-minor axis in meters
+func (g *Ellipse) SemiMinor() float64 {
+	return g.semiMinor
+}
+
+// Units returns the units of the ellipse's original axes
+func (g *Ellipse) Units() geo.Unit {
+	return g.units
+}
+
+// Azimuth returns the bearing of the semi-major axis in degrees
+func (g *Ellipse) Azimuth() float64 {
+	return g.azimuth
+}
+
+// Within returns true if ellipse is contained inside object
+func (g *Ellipse) Within(obj Object) bool {
+	return obj.Contains(g)
+}
+
+// Contains returns true if the ellipse contains other object
+func (g *Ellipse) Contains(obj Object) bool {
+	return shapeContains(g, obj, g.object)
+}
+
+// Intersects returns true the ellipse intersects other object
+func (g *Ellipse) Intersects(obj Object) bool {
+	return shapeIntersects(g, obj, g.object)
+}
+
+// Empty ...
+func (g *Ellipse) Empty() bool {
+	return false
+}
+
+// Valid ...
+func (g *Ellipse) Valid() bool {
+	return g.object.Valid()
+}
+
+// ForEach ...
+func (g *Ellipse) ForEach(iter func(geom Object) bool) bool {
+	return iter(g)
+}
+
+// NumPoints ...
+func (g *Ellipse) NumPoints() int {
+	return 1
+}
+
+// Distance ...
+func (g *Ellipse) Distance(other Object) float64 {
+	return g.object.Distance(other)
+}
+
+// Rect ...
+func (g *Ellipse) Rect() geometry.Rect {
+	return g.object.Rect()
+}
+
+// Spatial ...
+func (g *Ellipse) Spatial() Spatial {
+	return g
+}
+
+// Primative returns a primative GeoJSON object. Either a Polygon or Point.
+func (g *Ellipse) Primative() Object {
+	return g.object
+}
+
+// WithinRect ...
+func (g *Ellipse) WithinRect(rect geometry.Rect) bool {
+	return g.object.Spatial().WithinRect(rect)
+}
+
+// WithinPoint ...
+func (g *Ellipse) WithinPoint(point geometry.Point) bool {
+	return g.object.Spatial().WithinPoint(point)
+}
+
+// WithinLine ...
+func (g *Ellipse) WithinLine(line *geometry.Line) bool {
+	return g.object.Spatial().WithinLine(line)
+}
+
+// WithinPoly ...
+func (g *Ellipse) WithinPoly(poly *geometry.Poly) bool {
+	return g.object.Spatial().WithinPoly(poly)
+}
+
+// IntersectsPoint ...
+func (g *Ellipse) IntersectsPoint(point geometry.Point) bool {
+	return g.containsPoint(point)
+}
+
+// IntersectsRect ...
+func (g *Ellipse) IntersectsRect(rect geometry.Rect) bool {
+	return shapeIntersectsPoly(g, &geometry.Poly{Exterior: rect})
+}
+
+// IntersectsLine ...
+func (g *Ellipse) IntersectsLine(line *geometry.Line) bool {
+	return shapeIntersectsSeries(g, line)
+}
+
+// IntersectsPoly ...
+func (g *Ellipse) IntersectsPoly(poly *geometry.Poly) bool {
+	return shapeIntersectsPoly(g, poly)
+}
+
+// DistancePoint ...
+func (g *Ellipse) DistancePoint(point geometry.Point) float64 {
+	return g.object.Spatial().DistancePoint(point)
+}
+
+// DistanceRect ...
+func (g *Ellipse) DistanceRect(rect geometry.Rect) float64 {
+	return g.object.Spatial().DistanceRect(rect)
+}
+
+// DistanceLine ...
+func (g *Ellipse) DistanceLine(line *geometry.Line) float64 {
+	return g.object.Spatial().DistanceLine(line)
+}
+
+// DistancePoly ...
+func (g *Ellipse) DistancePoly(poly *geometry.Poly) float64 {
+	return g.object.Spatial().DistancePoly(poly)
+}
+
+// norm returns the squared elliptical distance of a point from the center.
+// Points with a norm of 1 or less are inside of the ellipse.
+func (g *Ellipse) norm(point geometry.Point) float64 {
+	meters := geo.DistanceTo(g.center.Y, g.center.X, point.Y, point.X)
+	if meters == 0 {
+		return 0
+	}
+	if g.semiMajor <= 0 || g.semiMinor <= 0 {
+		return math.Inf(+1)
+	}
+	bearing := geo.BearingTo(g.center.Y, g.center.X, point.Y, point.X)
+	θ := (bearing - g.azimuth) * math.Pi / 180
+	along := meters * math.Cos(θ) / g.semiMajor
+	across := meters * math.Sin(θ) / g.semiMinor
+	return along*along + across*across
+}
+
+// radiusAt returns the distance from the center to the edge of the ellipse
+// along a bearing.
+func (g *Ellipse) radiusAt(bearing float64) float64 {
+	θ := (bearing - g.azimuth) * math.Pi / 180
+	a := g.semiMinor * math.Cos(θ)
+	b := g.semiMajor * math.Sin(θ)
+	return g.semiMajor * g.semiMinor / math.Sqrt(a*a+b*b)
+}
+
+func (g *Ellipse) bounds() geometry.Rect {
+	minLat, minLon, maxLat, maxLon := geo.RectFromCenter(g.center.Y,
+		g.center.X, math.Max(g.semiMajor, g.semiMinor))
+	return geometry.Rect{
+		Min: geometry.Point{X: minLon, Y: minLat},
+		Max: geometry.Point{X: maxLon, Y: maxLat},
+	}
+}
+
+func (g *Ellipse) interiorPoint() geometry.Point {
+	return g.center
+}
+
+func (g *Ellipse) containsPoint(point geometry.Point) bool {
+	return g.norm(point) <= 1
+}
+
+func (g *Ellipse) containsSegment(seg geometry.Segment) bool {
+	// the ellipse is convex
+	return g.containsPoint(seg.A) && g.containsPoint(seg.B)
+}
+
+func (g *Ellipse) intersectsSegment(seg geometry.Segment) bool {
+	_, norm := segmentMin(seg, g.norm)
+	return norm <= 1
+}
+
+func (g *Ellipse) makeObject() Object {
+	if g.semiMajor <= 0 || g.semiMinor <= 0 {
+		return NewPoint(g.center)
+	}
+	points := shapeRing(g.center, g.steps, 0, 360, g.radiusAt)
+	points[len(points)-1] = points[0]
+	return NewPolygon(
+		geometry.NewPoly(points, nil, &geometry.IndexOptions{
+			Kind: geometry.None,
+		}),
+	)
+}
+
+// parseJSONEllipse returns the Ellipse of a Feature, or nil when the axes
+// are missing or not positive, which leaves it a plain Feature.
+func parseJSONEllipse(center geometry.Point, members string) (Object, error) {
+	semiMajor, ok1 := parseShapeSize(members, "semi_major")
+	semiMinor, ok2 := parseShapeSize(members, "semi_minor")
+	if !ok1 || !ok2 || !(semiMajor > 0) || !(semiMinor > 0) {
+		return nil, nil
+	}
+	units, ok := parseRadiusUnits(members)
+	if !ok {
+		return nil, errRadiusUnitsInvalid
+	}
+	azimuth := gjson.Get(members, "properties.azimuth").Float()
+	return NewEllipseUnits(center, semiMajor, semiMinor, units, azimuth,
+		64), nil
+}
@@ -0,0 +1,98 @@
+package geojson
+
+import (
+	"testing"
+
+	"github.com/tidwall/geojson/geo"
+	"github.com/tidwall/geojson/geometry"
+)
+
+func TestEllipseNew(t *testing.T) {
+	data := `{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},"properties":{"type":"Ellipse","semi_major":2,"semi_minor":1,"azimuth":45,"radius_units":"km"}}`
+	g := expectJSON(t, data, data)
+	expect(t, g.(*Ellipse).Units() == geo.Kilometers)
+	expect(t, g.(*Ellipse).SemiMajor() == 2000)
+	expect(t, g.(*Ellipse).SemiMinor() == 1000)
+	expectJSON(t,
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},"properties":{"type":"Ellipse","semi_major":2,"semi_minor":1,"radius_units":"parsecs"}}`,
+		errRadiusUnitsInvalid,
+	)
+	g, err := Parse(`{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},"properties":{"type":"Ellipse","semi_major":2000,"semi_minor":1000}}`,
+		&ParseOptions{DisableEllipseType: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := g.(*Feature); !ok {
+		t.Fatalf("expected Feature, got %T", g)
+	}
+	// invalid sizes are plain Features
+	for _, json := range []string{
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},"properties":{"type":"Ellipse","semi_major":2000,"semi_minor":0}}`,
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},"properties":{"type":"Ellipse","semi_major":-2000,"semi_minor":1000}}`,
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},"properties":{"type":"Ellipse","semi_minor":1000}}`,
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},"properties":{"type":"Ellipse","semi_major":"big","semi_minor":1000}}`,
+	} {
+		g, err := Parse(json, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := g.(*Feature); !ok {
+			t.Fatalf("expected Feature, got %T", g)
+		}
+	}
+	ellipse := NewEllipse(P(-112, 33), 2000, 1000, 90, 64)
+	expect(t, ellipse.SemiMajor() == 2000)
+	expect(t, ellipse.SemiMinor() == 1000)
+	expect(t, ellipse.Azimuth() == 90)
+	expectJSON(t, ellipse.JSON(), `{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},"properties":{"type":"Ellipse","semi_major":2000,"semi_minor":1000,"azimuth":90,"radius_units":"m"}}`)
+	// an invalid unit has no meters, which are written instead
+	ellipse = NewEllipseUnits(P(-112, 33), 2, 1, geo.Unit(100), 90, 64)
+	expectJSON(t, ellipse.JSON(), `{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},"properties":{"type":"Ellipse","semi_major":0,"semi_minor":0,"azimuth":90,"radius_units":"m"}}`)
+}
+
+func TestEllipsePredicates(t *testing.T) {
+	center := P(-122.4412, 37.7335)
+	// semi-major axis points east
+	g := NewEllipse(center, 2000, 1000, 90, 64)
+	expect(t, g.Contains(PO(center.X, center.Y)))
+	p := destinationPoint(center, 1990, 90)
+	expect(t, g.Contains(PO(p.X, p.Y)))
+	p = destinationPoint(center, 1990, 270)
+	expect(t, g.Contains(PO(p.X, p.Y)))
+	p = destinationPoint(center, 1010, 0)
+	expect(t, !g.Contains(PO(p.X, p.Y)))
+	expect(t, !g.Intersects(PO(p.X, p.Y)))
+	p = destinationPoint(center, 990, 180)
+	expect(t, g.Intersects(PO(p.X, p.Y)))
+
+	// a line passing just north of the center, inside of the ellipse only
+	// because of the long east-west axis.
+	north := destinationPoint(center, 990, 0)
+	line := LO([]geometry.Point{P(north.X-0.1, north.Y), P(north.X+0.1, north.Y)})
+	expect(t, g.Intersects(line))
+	expect(t, line.Intersects(g))
+	expect(t, !g.Contains(line))
+	north = destinationPoint(center, 1010, 0)
+	line = LO([]geometry.Point{P(north.X-0.1, north.Y), P(north.X+0.1, north.Y)})
+	expect(t, !g.Intersects(line))
+	expect(t, !line.Intersects(g))
+
+	// a polygon that contains the whole ellipse
+	rect := RO(center.X-0.1, center.Y-0.1, center.X+0.1, center.Y+0.1)
+	expect(t, g.Intersects(rect))
+	expect(t, rect.Intersects(g))
+	expect(t, rect.Contains(g))
+	expect(t, g.Within(rect))
+	expect(t, !g.Contains(rect))
+
+	// a small polygon inside of the ellipse
+	small := PPO([]geometry.Point{
+		center, destinationPoint(center, 1500, 80),
+		destinationPoint(center, 1500, 100), center,
+	}, nil)
+	expect(t, g.Contains(small))
+	expect(t, g.Intersects(small))
+	expect(t, NewFeatureCollection([]Object{
+		NewFeature(small, `{"id":1}`),
+	}).Intersects(g))
+}
@@ -1,6 +1,7 @@
 package geojson
 
 import (
+	"strconv"
 	"strings"
 
 	"github.com/tidwall/geojson/geometry"
@@ -13,6 +14,8 @@ import (
 type Feature struct {
 	base  Object
 	extra *extra
+	id    gjson.Result
+	props *Properties
 }
 
 // NewFeature returns a new GeoJSON Feature.
@@ -32,9 +35,217 @@ func NewFeature(geometry Object, members string) *Feature {
 			g.extra.members = string(pretty.UglyInPlace([]byte(members)))
 		}
 	}
+	g.parseMembers()
 	return g
 }
 
+// parseMembers caches the id and the properties of the members
+func (g *Feature) parseMembers() {
+	if g.extra == nil {
+		return
+	}
+	gjson.Parse(g.extra.members).ForEach(func(key, value gjson.Result) bool {
+		switch key.String() {
+		case "id":
+			if !g.id.Exists() {
+				g.id = value
+			}
+		case "properties":
+			if g.props == nil {
+				g.props = newProperties(value)
+			}
+		}
+		return true
+	})
+}
+
+// ID returns the id of the Feature as a string, or as a number. A number is
+// an int64, or a uint64 when it's too large, when it's an integer, and a
+// float64 otherwise. The ID is nil when the Feature does not have an id or
+// it's another type.
+func (g *Feature) ID() interface{} {
+	switch g.id.Type {
+	case gjson.String:
+		return g.id.Str
+	case gjson.Number:
+		if n, err := strconv.ParseInt(g.id.Raw, 10, 64); err == nil {
+			return n
+		}
+		if n, err := strconv.ParseUint(g.id.Raw, 10, 64); err == nil {
+			return n
+		}
+		return g.id.Num
+	}
+	return nil
+}
+
+// Properties returns the parsed properties of the Feature, which are empty
+// when the Feature does not have properties.
+func (g *Feature) Properties() *Properties {
+	if g.props == nil {
+		return emptyProperties
+	}
+	return g.props
+}
+
+// WithMembers returns a new Feature with the same geometry and the provided
+// members, which are applied like NewFeature.
+func (g *Feature) WithMembers(members string) *Feature {
+	return NewFeature(g.base, members)
+}
+
+// members returns the members, or an empty object
+func (g *Feature) members() string {
+	if g.extra == nil {
+		return "{}"
+	}
+	return g.extra.members
+}
+
+// withProperties returns the members where the properties are an object
+func (g *Feature) withProperties() (string, error) {
+	members := g.members()
+	if g.props == nil || !g.props.raw.IsObject() {
+		return sjson.SetRaw(members, "properties", "{}")
+	}
+	return members, nil
+}
+
+// WithProperty returns a new Feature where the property at a path is set
+// to a value. The path uses the SJSON path syntax, such as "name" or
+// "address.city".
+func (g *Feature) WithProperty(path string, value interface{},
+) (*Feature, error) {
+	members, err := g.withProperties()
+	if err != nil {
+		return nil, err
+	}
+	members, err = sjson.Set(members, "properties."+path, value)
+	if err != nil {
+		return nil, err
+	}
+	return NewFeature(g.base, members), nil
+}
+
+// WithoutProperty returns a new Feature where the property at a path is
+// deleted.
+func (g *Feature) WithoutProperty(path string) (*Feature, error) {
+	if !g.Properties().Get(path).Exists() {
+		return g, nil
+	}
+	members, err := sjson.Delete(g.members(), "properties."+path)
+	if err != nil {
+		return nil, err
+	}
+	return NewFeature(g.base, members), nil
+}
+
+// WithMergedProperties returns a new Feature where a JSON object is merged
+// into the properties, like a JSON Merge Patch. Nested objects are merged,
+// null values delete properties, and other values replace properties.
+func (g *Feature) WithMergedProperties(patch string) (*Feature, error) {
+	if !gjson.Valid(patch) || !gjson.Parse(patch).IsObject() {
+		return nil, errDataInvalid
+	}
+	merged := appendMergePatch(nil, g.Properties().raw, gjson.Parse(patch))
+	members, err := sjson.SetRaw(g.members(), "properties", string(merged))
+	if err != nil {
+		return nil, err
+	}
+	return NewFeature(g.base, members), nil
+}
+
+// WithID returns a new Feature with an id, which should be a string or a
+// number.
+func (g *Feature) WithID(id interface{}) (*Feature, error) {
+	return g.WithMember("id", id)
+}
+
+// WithMember returns a new Feature where a member, such as a foreign
+// member, is set to a value. The "type" and "geometry" members can not be
+// set, and the "feature" member is left out like NewFeature.
+func (g *Feature) WithMember(key string, value interface{}) (*Feature, error) {
+	if key == "type" || key == "geometry" {
+		return nil, errMemberInvalid
+	}
+	members, err := sjson.Set(g.members(), escapePath(key), value)
+	if err != nil {
+		return nil, err
+	}
+	return NewFeature(g.base, members), nil
+}
+
+// WithoutMember returns a new Feature where a member is deleted.
+func (g *Feature) WithoutMember(key string) (*Feature, error) {
+	if g.extra == nil {
+		return g, nil
+	}
+	members, err := sjson.Delete(g.extra.members, escapePath(key))
+	if err != nil {
+		return nil, err
+	}
+	return NewFeature(g.base, members), nil
+}
+
+// escapePath returns a path of a single key
+func escapePath(key string) string {
+	var dst []byte
+	for i := 0; i < len(key); i++ {
+		switch key[i] {
+		case '.', '*', '?', '\\', '|', '#', '@', ':':
+			dst = append(dst, '\\')
+		}
+		dst = append(dst, key[i])
+	}
+	return string(dst)
+}
+
+// appendMergePatch appends the result of a JSON Merge Patch of a target
+func appendMergePatch(dst []byte, target, patch gjson.Result) []byte {
+	if !patch.IsObject() {
+		return append(dst, patch.Raw...)
+	}
+	var keys []gjson.Result
+	values := make(map[string]gjson.Result)
+	patch.ForEach(func(key, value gjson.Result) bool {
+		if _, ok := values[key.String()]; !ok {
+			keys = append(keys, key)
+		}
+		values[key.String()] = value
+		return true
+	})
+	dst = append(dst, '{')
+	var n int
+	appendKey := func(key gjson.Result) {
+		if n > 0 {
+			dst = append(dst, ',')
+		}
+		n++
+		dst = append(dst, key.Raw...)
+		dst = append(dst, ':')
+	}
+	if target.IsObject() {
+		target.ForEach(func(key, value gjson.Result) bool {
+			if patched, ok := values[key.String()]; !ok {
+				appendKey(key)
+				dst = append(dst, value.Raw...)
+			} else if patched.Type != gjson.Null {
+				appendKey(key)
+				dst = appendMergePatch(dst, value, patched)
+			}
+			delete(values, key.String())
+			return true
+		})
+	}
+	for _, key := range keys {
+		if value, ok := values[key.String()]; ok && value.Type != gjson.Null {
+			appendKey(key)
+			dst = appendMergePatch(dst, gjson.Result{}, value)
+		}
+	}
+	return append(dst, '}')
+}
+
 // ForEach ...
 func (g *Feature) ForEach(iter func(geom Object) bool) bool {
 	return g.base.ForEach(iter)
@@ -177,23 +388,47 @@ func parseJSONFeature(keys *parseKeys, opts *ParseOptions) (Object, error) {
 	if err := parseBBoxAndExtras(&g.extra, keys, opts); err != nil {
 		return nil, err
 	}
-	if point, ok := g.base.(*Point); ok {
-		if g.extra != nil {
-			members := g.extra.members
-			if !opts.DisableCircleType &&
-				gjson.Get(members, "properties.type").String() == "Circle" {
-				// Circle
-				radius := gjson.Get(members, "properties.radius").Float()
-				units := gjson.Get(members, "properties.radius_units").String()
-				switch units {
-				case "", "m":
-				case "km":
-					radius *= 1000
-				default:
-					return nil, errCircleRadiusUnitsInvalid
-				}
-				return NewCircle(point.base, radius, 64), nil
+	g.parseMembers()
+	if point, ok := g.base.(*Point); ok && g.extra != nil {
+		members := g.extra.members
+		var shape Object
+		switch g.props.Get("type").String() {
+		case "Circle":
+			if !opts.DisableCircleType {
+				shape, err = parseJSONCircle(point.base, members, opts)
+			}
+		case "Ellipse":
+			if !opts.DisableEllipseType {
+				shape, err = parseJSONEllipse(point.base, members)
+			}
+		case "Sector":
+			if !opts.DisableSectorType {
+				shape, err = parseJSONSector(point.base, members)
+			}
+		case "Annulus":
+			if !opts.DisableAnnulusType {
+				shape, err = parseJSONAnnulus(point.base, members)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		// a shape with invalid sizes is a plain Feature
+		if shape != nil {
+			// the shape keeps the id and properties for filters
+			switch shape := shape.(type) {
+			case *Circle:
+				shape.id, shape.props = g.id, g.props
+			case *ClippedCircle:
+				shape.circle.id, shape.circle.props = g.id, g.props
+			case *Ellipse:
+				shape.id, shape.props = g.id, g.props
+			case *Sector:
+				shape.id, shape.props = g.id, g.props
+			case *Annulus:
+				shape.id, shape.props = g.id, g.props
 			}
+			return shape, nil
 		}
 	}
 	return &g, nil
@@ -95,3 +95,75 @@ func TestIssue529(t *testing.T) {
 	expect(t, ls2.Intersects(circ))
 	expect(t, circ.Intersects(ls2))
 }
+
+func TestFeatureWith(t *testing.T) {
+	f := expectJSON(t, `{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"id":1,"properties":{"name":"a","address":{"city":"x","zip":1}},"foo":"bar"}`, nil).(*Feature)
+	orig := f.JSON()
+	g, err := f.WithProperty("name", "b")
+	expect(t, err == nil)
+	expect(t, g.JSON() == `{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"id":1,"properties":{"name":"b","address":{"city":"x","zip":1}},"foo":"bar"}`)
+	expect(t, g.Base() == f.Base() && f.JSON() == orig)
+	s, _ := g.Properties().String("name")
+	expect(t, s == "b")
+	g, err = g.WithProperty("address.state", "AZ")
+	expect(t, err == nil)
+	expect(t, g.Properties().Get("address").Raw == `{"state":"AZ","city":"x","zip":1}`)
+	g, err = g.WithoutProperty("address.zip")
+	expect(t, err == nil)
+	expect(t, g.Properties().Get("address").Raw == `{"state":"AZ","city":"x"}`)
+	h, err := g.WithoutProperty("missing")
+	expect(t, err == nil && h == g)
+
+	g, err = f.WithID("abc")
+	expect(t, err == nil && g.ID() == "abc")
+	g, err = g.WithMember("foo", map[string]int{"a": 1})
+	expect(t, err == nil)
+	expect(t, g.JSON() == `{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"id":"abc","properties":{"name":"a","address":{"city":"x","zip":1}},"foo":{"a":1}}`)
+	g, err = g.WithMember("a.b", 1)
+	expect(t, err == nil && gjson.Get(g.Members(), `a\.b`).Int() == 1)
+	g, err = g.WithoutMember("foo")
+	expect(t, err == nil && !gjson.Get(g.Members(), "foo").Exists())
+	g, err = g.WithoutMember("id")
+	expect(t, err == nil && g.ID() == nil)
+	_, err = f.WithMember("geometry", 1)
+	expect(t, err == errMemberInvalid)
+	// the "feature" member is left out
+	g, err = f.WithMember("feature", 1)
+	expect(t, err == nil && !gjson.Get(g.Members(), "feature").Exists())
+	expect(t, f.WithMembers(`{"id":2}`).JSON() == `{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"id":2,"properties":{}}`)
+
+	// features without members or properties
+	f = NewFeature(PO(1, 2), "")
+	g, err = f.WithProperty("a", 1)
+	expect(t, err == nil && g.Members() == `{"properties":{"a":1}}`)
+	g, err = NewFeature(PO(1, 2), `{"properties":null}`).WithProperty("a", 1)
+	expect(t, err == nil && g.Members() == `{"properties":{"a":1}}`)
+	g, err = f.WithoutMember("id")
+	expect(t, err == nil && g == f)
+}
+
+func TestFeatureWithMergedProperties(t *testing.T) {
+	f := NewFeature(PO(1, 2), `{"id":1,"properties":{"a":"b","c":{"d":"e","f":"g"},"h":[1],"m":{"n":null}}}`)
+	g, err := f.WithMergedProperties(`{"a":"z","c":{"f":null,"i":1},"h":null,"j":{"k":null,"l":2}}`)
+	expect(t, err == nil)
+	expect(t, g.Members() == `{"id":1,"properties":{"a":"z","c":{"d":"e","i":1},"m":{"n":null},"j":{"l":2}}}`)
+	g, err = NewFeature(PO(1, 2), "").WithMergedProperties(`{"a":1}`)
+	expect(t, err == nil && g.Members() == `{"properties":{"a":1}}`)
+	_, err = f.WithMergedProperties(`[1]`)
+	expect(t, err == errDataInvalid)
+	_, err = f.WithMergedProperties(`{`)
+	expect(t, err == errDataInvalid)
+}
+
+func TestFeatureWithoutShapeTypes(t *testing.T) {
+	opts := WithoutShapeTypes(nil)
+	for _, kind := range []string{"Circle", "Ellipse", "Sector", "Annulus"} {
+		data := `{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},"properties":{"type":"` + kind + `","radius":1000,"semi_major":2000,"semi_minor":1000,"inner_radius":500,"outer_radius":1000}}`
+		g := expectJSONOpts(t, data, nil, opts)
+		_, ok := g.(*Feature)
+		expect(t, ok)
+	}
+	// the original options are left alone
+	expect(t, !DefaultParseOptions.DisableCircleType)
+	expect(t, WithoutShapeTypes(&ParseOptions{IndexGeometry: 8}).IndexGeometry == 8)
+}
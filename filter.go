@@ -0,0 +1,496 @@
+package geojson
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tidwall/gjson"
+)
+
+// Filter is a compiled filter expression, which matches Features by their
+// properties and id.
+//
+// An expression compares the values at property paths, such as "name" or
+// "address.city", where "$id" is the id of the Feature and double quotes
+// are used for keys with other characters, such as "my key". The values are
+// numbers, 'strings', true, false, and null.
+//
+//	pop >= 1000 and (state = 'AZ' or state in ('NM', 'UT'))
+//	name like 'San %' and not capital
+//	area between 10 and 20.5
+//	zip exists and $id != 'x'
+//	note is not null
+//
+// The comparisons are =, ==, !=, <>, <, <=, >, >=, [not] in, [not] like,
+// [not] between, [not] exists, and is [not] null, where like uses % and _
+// as wildcards. A path by itself is true for a true value. Conditions are
+// combined with and, or, not, &&, ||, and !. Comparisons of a missing value,
+// or of values of different types, are false.
+type Filter struct {
+	expr  string
+	match filterFunc
+}
+
+// CompileFilter returns a compiled filter expression.
+func CompileFilter(expr string) (*Filter, error) {
+	c := filterCompiler{toks: lexFilter(expr)}
+	match, err := c.or()
+	if err != nil {
+		return nil, err
+	}
+	if tok := c.peek(); tok.kind != tokEOF {
+		return nil, tok.unexpected()
+	}
+	return &Filter{expr: expr, match: match}, nil
+}
+
+// String returns the expression of the filter
+func (f *Filter) String() string {
+	return f.expr
+}
+
+// Match returns true when an object matches the filter. Features, and the
+// special shapes that are parsed from Features, such as a Circle, are matched
+// by their properties and id. Other objects have no properties.
+func (f *Filter) Match(obj Object) bool {
+	switch g := obj.(type) {
+	case *Feature:
+		return f.match(g.props, g.id)
+	case *Circle:
+		return f.match(g.props, g.id)
+	case *ClippedCircle:
+		return f.match(g.circle.props, g.circle.id)
+	case *Ellipse:
+		return f.match(g.props, g.id)
+	case *Sector:
+		return f.match(g.props, g.id)
+	case *Annulus:
+		return f.match(g.props, g.id)
+	}
+	return f.match(nil, gjson.Result{})
+}
+
+type tokKind byte
+
+const (
+	tokEOF tokKind = iota
+	tokIdent
+	tokQuoted // a double quoted key
+	tokString
+	tokNumber
+	tokOp // an operator or parenthesis
+	tokInvalid
+)
+
+type filterTok struct {
+	kind tokKind
+	text string
+	pos  int
+}
+
+// is returns true when the token is an operator, or an unquoted keyword
+func (tok filterTok) is(text string) bool {
+	return (tok.kind == tokOp && tok.text == text) ||
+		(tok.kind == tokIdent && strings.EqualFold(tok.text, text))
+}
+
+func (tok filterTok) unexpected() error {
+	if tok.kind == tokEOF {
+		return fmt.Errorf(fmtErrFilterInvalid, "end", tok.pos)
+	}
+	return fmt.Errorf(fmtErrFilterInvalid, strconv.Quote(tok.text), tok.pos)
+}
+
+func isIdentByte(c byte, first bool) bool {
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' ||
+		c >= 'A' && c <= 'Z' || c >= 0x80 ||
+		(!first && (c == '.' || c >= '0' && c <= '9'))
+}
+
+func lexFilter(s string) []filterTok {
+	var toks []filterTok
+	for i := 0; i < len(s); {
+		c := s[i]
+		start := i
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+			continue
+		case c == '\'' || c == '"':
+			// quotes are escaped by doubling them
+			var text []byte
+			for i++; i < len(s); i++ {
+				if s[i] == c {
+					if i+1 < len(s) && s[i+1] == c {
+						i++
+					} else {
+						break
+					}
+				}
+				text = append(text, s[i])
+			}
+			if i == len(s) {
+				return append(toks, filterTok{tokInvalid, s[start:], start})
+			}
+			i++
+			kind := tokString
+			if c == '"' {
+				kind = tokQuoted
+			}
+			toks = append(toks, filterTok{kind, string(text), start})
+		case c >= '0' && c <= '9' || c == '.' || c == '-' || c == '+':
+			for i++; i < len(s) && (s[i] >= '0' && s[i] <= '9' ||
+				s[i] == '.' || s[i] == 'e' || s[i] == 'E' ||
+				((s[i] == '-' || s[i] == '+') &&
+					(s[i-1] == 'e' || s[i-1] == 'E'))); i++ {
+			}
+			toks = append(toks, filterTok{tokNumber, s[start:i], start})
+		case isIdentByte(c, true):
+			for i++; i < len(s) && isIdentByte(s[i], false); i++ {
+			}
+			toks = append(toks, filterTok{tokIdent, s[start:i], start})
+		default:
+			i++
+			if i < len(s) {
+				switch s[start : i+1] {
+				case "==", "!=", "<>", "<=", ">=", "&&", "||":
+					i++
+				}
+			}
+			toks = append(toks, filterTok{tokOp, s[start:i], start})
+		}
+	}
+	return append(toks, filterTok{tokEOF, "", len(s)})
+}
+
+type filterFunc func(props *Properties, id gjson.Result) bool
+
+type filterCompiler struct {
+	toks []filterTok
+	pos  int
+}
+
+func (c *filterCompiler) peek() filterTok {
+	return c.toks[c.pos]
+}
+
+func (c *filterCompiler) next() filterTok {
+	tok := c.toks[c.pos]
+	if tok.kind != tokEOF {
+		c.pos++
+	}
+	return tok
+}
+
+// accept consumes the next token when it's the operator or keyword
+func (c *filterCompiler) accept(text string) bool {
+	if c.peek().is(text) {
+		c.pos++
+		return true
+	}
+	return false
+}
+
+func (c *filterCompiler) expect(text string) error {
+	if !c.accept(text) {
+		return c.peek().unexpected()
+	}
+	return nil
+}
+
+func (c *filterCompiler) or() (filterFunc, error) {
+	left, err := c.and()
+	if err != nil {
+		return nil, err
+	}
+	for c.accept("or") || c.accept("||") {
+		a := left
+		b, err := c.and()
+		if err != nil {
+			return nil, err
+		}
+		left = func(props *Properties, id gjson.Result) bool {
+			return a(props, id) || b(props, id)
+		}
+	}
+	return left, nil
+}
+
+func (c *filterCompiler) and() (filterFunc, error) {
+	left, err := c.not()
+	if err != nil {
+		return nil, err
+	}
+	for c.accept("and") || c.accept("&&") {
+		a := left
+		b, err := c.not()
+		if err != nil {
+			return nil, err
+		}
+		left = func(props *Properties, id gjson.Result) bool {
+			return a(props, id) && b(props, id)
+		}
+	}
+	return left, nil
+}
+
+func (c *filterCompiler) not() (filterFunc, error) {
+	if c.accept("not") || c.accept("!") {
+		a, err := c.not()
+		if err != nil {
+			return nil, err
+		}
+		return func(props *Properties, id gjson.Result) bool {
+			return !a(props, id)
+		}, nil
+	}
+	if c.accept("(") {
+		a, err := c.or()
+		if err != nil {
+			return nil, err
+		}
+		return a, c.expect(")")
+	}
+	return c.comparison()
+}
+
+// path returns a function that returns the value at a path
+func (c *filterCompiler) path() (func(props *Properties, id gjson.Result,
+) gjson.Result, error) {
+	tok := c.next()
+	switch {
+	case tok.kind == tokIdent && tok.text == "$id":
+		return func(props *Properties, id gjson.Result) gjson.Result {
+			return id
+		}, nil
+	case tok.kind == tokIdent && !isFilterKeyword(tok.text):
+		path := tok.text
+		return func(props *Properties, id gjson.Result) gjson.Result {
+			return props.Get(path)
+		}, nil
+	case tok.kind == tokQuoted:
+		path := escapePath(tok.text)
+		return func(props *Properties, id gjson.Result) gjson.Result {
+			return props.Get(path)
+		}, nil
+	}
+	return nil, tok.unexpected()
+}
+
+func isFilterKeyword(s string) bool {
+	switch strings.ToLower(s) {
+	case "and", "or", "not", "in", "like", "between", "exists", "is",
+		"null", "true", "false":
+		return true
+	}
+	return false
+}
+
+// value returns a literal value as JSON
+func (c *filterCompiler) value() (gjson.Result, error) {
+	tok := c.next()
+	switch {
+	case tok.kind == tokString:
+		b, _ := json.Marshal(tok.text)
+		return gjson.ParseBytes(b), nil
+	case tok.kind == tokNumber:
+		if f, err := strconv.ParseFloat(tok.text, 64); err == nil {
+			return gjson.Result{Type: gjson.Number, Raw: tok.text, Num: f}, nil
+		}
+	case tok.is("true"), tok.is("false"), tok.is("null"):
+		return gjson.Parse(strings.ToLower(tok.text)), nil
+	}
+	return gjson.Result{}, tok.unexpected()
+}
+
+func (c *filterCompiler) comparison() (filterFunc, error) {
+	get, err := c.path()
+	if err != nil {
+		return nil, err
+	}
+	tok := c.peek()
+	var test func(value gjson.Result) bool
+	switch {
+	case tok.is("is"):
+		c.next()
+		not := c.accept("not")
+		if err := c.expect("null"); err != nil {
+			return nil, err
+		}
+		test = func(value gjson.Result) bool {
+			return (value.Type == gjson.Null) != not
+		}
+	case tok.is("="), tok.is("=="), tok.is("!="), tok.is("<>"),
+		tok.is("<"), tok.is("<="), tok.is(">"), tok.is(">="):
+		c.next()
+		lit, err := c.value()
+		if err != nil {
+			return nil, err
+		}
+		op := tok.text
+		test = func(value gjson.Result) bool {
+			cmp, ok := compareValues(value, lit)
+			switch op {
+			case "=", "==":
+				return ok && cmp == 0
+			case "!=", "<>":
+				return ok && cmp != 0
+			case "<":
+				return ok && cmp < 0
+			case "<=":
+				return ok && cmp <= 0
+			case ">":
+				return ok && cmp > 0
+			}
+			return ok && cmp >= 0
+		}
+	default:
+		not := c.accept("not")
+		if tok = c.peek(); tok.is("exists") || tok.is("in") ||
+			tok.is("like") || tok.is("between") {
+			c.next()
+		}
+		switch {
+		case tok.is("exists"):
+			test = func(value gjson.Result) bool {
+				return value.Exists()
+			}
+		case tok.is("in"):
+			if err := c.expect("("); err != nil {
+				return nil, err
+			}
+			var list []gjson.Result
+			for {
+				lit, err := c.value()
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, lit)
+				if !c.accept(",") {
+					break
+				}
+			}
+			if err := c.expect(")"); err != nil {
+				return nil, err
+			}
+			test = func(value gjson.Result) bool {
+				for _, lit := range list {
+					if cmp, ok := compareValues(value, lit); ok && cmp == 0 {
+						return true
+					}
+				}
+				return false
+			}
+		case tok.is("like"):
+			pattern := c.next()
+			if pattern.kind != tokString {
+				return nil, pattern.unexpected()
+			}
+			test = func(value gjson.Result) bool {
+				return value.Type == gjson.String &&
+					likeMatch(value.Str, pattern.text)
+			}
+		case tok.is("between"):
+			min, err := c.value()
+			if err != nil {
+				return nil, err
+			}
+			if err := c.expect("and"); err != nil {
+				return nil, err
+			}
+			max, err := c.value()
+			if err != nil {
+				return nil, err
+			}
+			test = func(value gjson.Result) bool {
+				cmin, ok1 := compareValues(value, min)
+				cmax, ok2 := compareValues(value, max)
+				return ok1 && ok2 && cmin >= 0 && cmax <= 0
+			}
+		default:
+			if not {
+				return nil, tok.unexpected()
+			}
+			// a path by itself
+			return func(props *Properties, id gjson.Result) bool {
+				return get(props, id).Type == gjson.True
+			}, nil
+		}
+		if not {
+			// a missing value does not match either way
+			exists := tok.is("exists")
+			inner := test
+			test = func(value gjson.Result) bool {
+				return (exists || value.Exists()) && !inner(value)
+			}
+		}
+	}
+	return func(props *Properties, id gjson.Result) bool {
+		return test(get(props, id))
+	}, nil
+}
+
+// compareValues compares values of the same type, and returns false when the
+// values can not be compared.
+func compareValues(a, b gjson.Result) (int, bool) {
+	switch {
+	case a.Type == gjson.Number && b.Type == gjson.Number:
+		if a.Num < b.Num {
+			return -1, true
+		} else if a.Num > b.Num {
+			return 1, true
+		}
+		return 0, true
+	case a.Type == gjson.String && b.Type == gjson.String:
+		return strings.Compare(a.Str, b.Str), true
+	case (a.Type == gjson.True || a.Type == gjson.False) &&
+		(b.Type == gjson.True || b.Type == gjson.False):
+		return int(a.Type) - int(b.Type), true
+	case a.Type == gjson.Null && b.Type == gjson.Null && a.Exists():
+		return 0, true
+	}
+	return 0, false
+}
+
+// likeMatch returns true when a string matches a pattern, where % matches
+// any characters and _ matches a single character.
+func likeMatch(s, pattern string) bool {
+	// the positions to return to after a failed match of a %
+	var starP, starS = -1, 0
+	var p, i int
+	for i < len(s) {
+		if p < len(pattern) {
+			switch pattern[p] {
+			case '%':
+				starP, starS = p, i
+				p++
+				continue
+			case '_':
+				_, size := utf8.DecodeRuneInString(s[i:])
+				i += size
+				p++
+				continue
+			default:
+				if pattern[p] == s[i] {
+					i++
+					p++
+					continue
+				}
+			}
+		}
+		if starP == -1 {
+			return false
+		}
+		// let the % match one more character
+		_, size := utf8.DecodeRuneInString(s[starS:])
+		starS += size
+		p, i = starP+1, starS
+	}
+	for p < len(pattern) && pattern[p] == '%' {
+		p++
+	}
+	return p == len(pattern)
+}
@@ -0,0 +1,125 @@
+package geojson
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	f := expectJSON(t, `{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"id":"a1","properties":{"name":"San Tan Valley","pop":99894,"area":36.9,"capital":false,"city":true,"state":"AZ","tags":["a","b"],"address":{"zip":"85140"},"note":null,"my key":"x"}}`, nil)
+	tests := []struct {
+		expr  string
+		match bool
+	}{
+		{`pop = 99894`, true},
+		{`pop == 99894.0`, true},
+		{`pop != 99894`, false},
+		{`pop <> 1`, true},
+		{`pop > 1e4 and pop < 1E5`, true},
+		{`pop >= 99894 && pop <= 99894`, true},
+		{`pop < 0`, false},
+		{`area between 36 and 37`, true},
+		{`area not between 36 and 37`, false},
+		{`area between -1 and +1`, false},
+		{`name = 'San Tan Valley'`, true},
+		{`name > 'Phoenix'`, true},
+		{`NAME = 'San Tan Valley'`, false},
+		{`name like 'San %'`, true},
+		{`name like '%Tan%'`, true},
+		{`name like 'S_n T_n Valley'`, true},
+		{`name like 'san%'`, false},
+		{`name not like 'Tan%'`, true},
+		{`state in ('NM', 'AZ')`, true},
+		{`state not in ('NM', 'AZ')`, false},
+		{`pop in (1, 99894)`, true},
+		{`capital = false and city = true`, true},
+		{`city and not capital`, true},
+		{`capital`, false},
+		{`!capital`, true},
+		{`address.zip = '85140'`, true},
+		{`tags.1 = 'b'`, true},
+		{`"my key" = 'x'`, true},
+		{`$id = 'a1'`, true},
+		{`zip exists`, false},
+		{`address.zip exists`, true},
+		{`zip not exists`, true},
+		{`note is null`, true},
+		{`note = null`, true},
+		{`zip is null`, true},
+		{`zip = null`, false},
+		{`note is not null`, false},
+		{`name is not null`, true},
+		// missing values and mixed types
+		{`zip != '1'`, false},
+		{`zip not in ('1')`, false},
+		{`pop = '99894'`, false},
+		{`pop != '99894'`, false},
+		{`name != 5`, false},
+		{`name <> true`, false},
+		{`note != 'x'`, false},
+		{`capital != true`, true},
+		{`name < 1`, false},
+		{`pop > 1 and (state = 'NM' or state = 'AZ')`, true},
+		{`pop > 1 and state = 'NM' or state = 'AZ'`, true},
+		{`pop < 1 and (state = 'NM' or state = 'AZ')`, false},
+		{`not (pop < 1 or state = 'NM')`, true},
+	}
+	for _, test := range tests {
+		filter, err := CompileFilter(test.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", test.expr, err)
+		}
+		if filter.Match(f) != test.match {
+			t.Fatalf("%s: expected %v", test.expr, test.match)
+		}
+		expect(t, filter.String() == test.expr)
+	}
+
+	// objects that are not features have no properties
+	filter, err := CompileFilter(`name not exists and not (pop > 1)`)
+	expect(t, err == nil)
+	expect(t, filter.Match(PO(1, 2)))
+	expect(t, filter.Match(NewFeature(PO(1, 2), "")))
+
+	// shapes keep the id and properties of their features
+	filter, err = CompileFilter(`name = 'a' and $id = 7`)
+	expect(t, err == nil)
+	for _, kind := range []string{"Circle", "Ellipse", "Sector", "Annulus"} {
+		g, err := Parse(`{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"id":7,"properties":{"type":"`+kind+`","name":"a","radius":100,"semi_major":200,"semi_minor":100,"inner_radius":50,"outer_radius":100}}`, nil)
+		expect(t, err == nil)
+		_, ok := g.(*Feature)
+		expect(t, !ok && filter.Match(g))
+	}
+	g, err := Parse(`{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"id":7,"properties":{"type":"Circle","radius":100,"clipper":{"type":"Polygon","coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]},"name":"a"}}`, nil)
+	expect(t, err == nil)
+	_, ok := g.(*ClippedCircle)
+	expect(t, ok && filter.Match(g))
+	expect(t, !filter.Match(NewCircle(P(1, 2), 100, 64)))
+
+	for _, expr := range []string{``, `pop >`, `pop = 'a`, `(pop = 1`,
+		`pop = 1)`, `pop in 1`, `pop in (1,)`, `name like 1`, `and = 1`,
+		`pop between 1 or 2`, `pop not = 1`, `pop = abc`, `pop = -`,
+		`note is 1`, `pop = 1 pop = 2`} {
+		_, err := CompileFilter(expr)
+		if err == nil || !strings.HasPrefix(err.Error(), "invalid filter") {
+			t.Fatalf("%s: expected an error", expr)
+		}
+	}
+	_, err = CompileFilter(`pop >= 1 and`)
+	expect(t, err.Error() == "invalid filter: unexpected end at offset 12")
+	_, err = CompileFilter(`pop = = 1`)
+	expect(t, err.Error() == `invalid filter: unexpected "=" at offset 6`)
+}
+
+func TestLikeMatch(t *testing.T) {
+	expect(t, likeMatch("", ""))
+	expect(t, likeMatch("", "%"))
+	expect(t, !likeMatch("", "_"))
+	expect(t, likeMatch("abc", "a%c"))
+	expect(t, likeMatch("abcbc", "a%bc"))
+	expect(t, !likeMatch("abcb", "a%bc"))
+	expect(t, likeMatch("héllo", "h_llo"))
+	expect(t, likeMatch("héllo", "%é%"))
+	expect(t, likeMatch("aaa", "%%a"))
+	expect(t, !likeMatch("abc", "ab"))
+}
@@ -0,0 +1,537 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package flatgeobuf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/geojson/geometry"
+	"github.com/tidwall/gjson"
+)
+
+// Reader reads the features of a FlatGeobuf file
+type Reader struct {
+	r              io.ReaderAt
+	opts           *geojson.ParseOptions
+	header         Header
+	geomType       byte
+	indexOffset    int64
+	featuresOffset int64
+}
+
+// NewReader returns a Reader of a FlatGeobuf file, after reading its header
+func NewReader(r io.ReaderAt, opts *geojson.ParseOptions) (*Reader, error) {
+	rd := &Reader{r: r, opts: opts}
+	var prefix [12]byte
+	if err := rd.readAt(prefix[:], 0); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(prefix[:3], magic[:3]) || prefix[3] != magic[3] ||
+		!bytes.Equal(prefix[4:7], magic[4:7]) {
+		return nil, ErrInvalidFile
+	}
+	size := binary.LittleEndian.Uint32(prefix[8:])
+	if size < 4 || size > maxHeaderSize {
+		return nil, ErrInvalidFile
+	}
+	buf := make([]byte, size)
+	if err := rd.readAt(buf, 12); err != nil {
+		return nil, err
+	}
+	if err := rd.readHeader(buf); err != nil {
+		return nil, err
+	}
+	rd.indexOffset = 12 + int64(size)
+	rd.featuresOffset = rd.indexOffset
+	if rd.header.IndexNodeSize > 0 && rd.header.FeaturesCount > 0 {
+		bounds := levelBounds(rd.header.FeaturesCount, rd.header.IndexNodeSize)
+		rd.featuresOffset += int64(bounds[0][1]) * nodeItemSize
+	}
+	return rd, nil
+}
+
+func (rd *Reader) readHeader(buf []byte) error {
+	t, err := rootTable(buf)
+	if err != nil {
+		return err
+	}
+	h := &rd.header
+	if h.Name, err = t.string(headerName); err != nil {
+		return err
+	}
+	envelope, err := t.float64s(headerEnvelope)
+	if err != nil {
+		return err
+	}
+	if len(envelope) >= 4 {
+		h.Envelope.Min = geometry.Point{X: envelope[0], Y: envelope[1]}
+		h.Envelope.Max = geometry.Point{X: envelope[2], Y: envelope[3]}
+	}
+	rd.geomType = t.uint8(headerGeometryType, geomUnknown)
+	h.HasZ = t.uint8(headerHasZ, 0) != 0
+	h.FeaturesCount = int(t.uint64(headerFeaturesCount, 0))
+	h.IndexNodeSize = int(t.uint16(headerIndexNodeSize, 16))
+	if h.FeaturesCount < 0 || h.IndexNodeSize == 1 {
+		return ErrInvalidFile
+	}
+	columns, err := t.tables(headerColumns)
+	if err != nil {
+		return err
+	}
+	for _, c := range columns {
+		name, err := c.string(columnName)
+		if err != nil {
+			return err
+		}
+		typ := ColumnType(c.uint8(columnType, 0))
+		if typ > ColumnBinary {
+			return ErrInvalidFile
+		}
+		h.Columns = append(h.Columns, Column{Name: name, Type: typ})
+	}
+	return nil
+}
+
+// readAt fills buf from the offset, where a short read is an invalid file
+func (rd *Reader) readAt(buf []byte, off int64) error {
+	n, err := rd.r.ReadAt(buf, off)
+	if n == len(buf) {
+		return nil
+	}
+	if err == nil || err == io.EOF {
+		return ErrInvalidFile
+	}
+	return err
+}
+
+// Header returns the header of the file
+func (rd *Reader) Header() Header {
+	return rd.header
+}
+
+// Features iterates over every feature in the order of the file. Features
+// without a geometry are skipped.
+func (rd *Reader) Features(iter func(f *geojson.Feature) bool) error {
+	off := rd.featuresOffset
+	count := rd.header.FeaturesCount
+	for i := 0; count == 0 || i < count; i++ {
+		if count == 0 {
+			// the number of features is unknown, so read to the end
+			var b [1]byte
+			if n, _ := rd.r.ReadAt(b[:], off); n == 0 {
+				return nil
+			}
+		}
+		f, size, err := rd.readFeature(off)
+		if err != nil {
+			return err
+		}
+		off += size
+		if f != nil && !iter(f) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// Search iterates over the features that intersect a rectangle, using the
+// index of the file to only read those features. Features are in the order
+// of the file.
+func (rd *Reader) Search(rect geometry.Rect,
+	iter func(f *geojson.Feature) bool,
+) error {
+	if rd.header.IndexNodeSize == 0 || rd.header.FeaturesCount == 0 {
+		return rd.Features(func(f *geojson.Feature) bool {
+			if !f.Rect().IntersectsRect(rect) {
+				return true
+			}
+			return iter(f)
+		})
+	}
+	offsets, err := rd.searchIndex(rect)
+	if err != nil {
+		return err
+	}
+	for _, off := range offsets {
+		f, _, err := rd.readFeature(rd.featuresOffset + int64(off))
+		if err != nil {
+			return err
+		}
+		if f != nil && !iter(f) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// searchIndex returns the sorted feature offsets of the leaf nodes that
+// intersect a rectangle.
+func (rd *Reader) searchIndex(rect geometry.Rect) ([]uint64, error) {
+	size := rd.header.IndexNodeSize
+	bounds := levelBounds(rd.header.FeaturesCount, size)
+	leaves := bounds[0][0]
+	type entry struct{ index, level int }
+	stack := []entry{{0, len(bounds) - 1}}
+	var offsets []uint64
+	var buf []byte
+	for len(stack) > 0 {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		end := e.index + size
+		if end > bounds[e.level][1] {
+			end = bounds[e.level][1]
+		}
+		buf = append(buf[:0], make([]byte, (end-e.index)*nodeItemSize)...)
+		err := rd.readAt(buf, rd.indexOffset+int64(e.index)*nodeItemSize)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < end-e.index; i++ {
+			node, off := decodeNode(buf[i*nodeItemSize:])
+			if !node.IntersectsRect(rect) {
+				continue
+			}
+			if e.index >= leaves {
+				offsets = append(offsets, off)
+				continue
+			}
+			child := bounds[e.level-1]
+			if off < uint64(child[0]) || off >= uint64(child[1]) {
+				return nil, ErrInvalidFile
+			}
+			stack = append(stack, entry{int(off), e.level - 1})
+		}
+	}
+	sort.Slice(offsets, func(i, j int) bool {
+		return offsets[i] < offsets[j]
+	})
+	return offsets, nil
+}
+
+func decodeNode(b []byte) (geometry.Rect, uint64) {
+	var rect geometry.Rect
+	rect.Min.X = math.Float64frombits(binary.LittleEndian.Uint64(b[0:]))
+	rect.Min.Y = math.Float64frombits(binary.LittleEndian.Uint64(b[8:]))
+	rect.Max.X = math.Float64frombits(binary.LittleEndian.Uint64(b[16:]))
+	rect.Max.Y = math.Float64frombits(binary.LittleEndian.Uint64(b[24:]))
+	return rect, binary.LittleEndian.Uint64(b[32:])
+}
+
+// readFeature returns the feature at an offset and its size in the file.
+// The feature is nil when it has no geometry.
+func (rd *Reader) readFeature(off int64) (*geojson.Feature, int64, error) {
+	var prefix [4]byte
+	if err := rd.readAt(prefix[:], off); err != nil {
+		return nil, 0, err
+	}
+	size := int64(binary.LittleEndian.Uint32(prefix[:]))
+	if size > 0 {
+		// check the end of the feature before reading all of it
+		if err := rd.readAt(prefix[:1], off+4+size-1); err != nil {
+			return nil, 0, err
+		}
+	}
+	buf := make([]byte, size)
+	if err := rd.readAt(buf, off+4); err != nil {
+		return nil, 0, err
+	}
+	f, err := rd.decodeFeature(buf)
+	return f, int64(len(buf)) + 4, err
+}
+
+func (rd *Reader) decodeFeature(buf []byte) (*geojson.Feature, error) {
+	t, err := rootTable(buf)
+	if err != nil {
+		return nil, err
+	}
+	g, ok, err := t.table(featureGeometry)
+	if !ok || err != nil {
+		return nil, err
+	}
+	geom, err := appendGeometry(nil, g, rd.geomType, rd.header.HasZ)
+	if err != nil {
+		return nil, err
+	}
+	props, err := t.bytes(featureProperties)
+	if err != nil {
+		return nil, err
+	}
+	members, err := rd.appendMembers(nil, props)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := geojson.Parse(string(geom), rd.opts)
+	if err != nil {
+		return nil, err
+	}
+	return geojson.NewFeature(obj, string(members)), nil
+}
+
+// appendMembers appends the "properties" member of the property values
+func (rd *Reader) appendMembers(dst, props []byte) ([]byte, error) {
+	dst = append(dst, `{"properties":{`...)
+	for i := 0; len(props) > 0; i++ {
+		if len(props) < 2 {
+			return nil, ErrInvalidFile
+		}
+		col := int(binary.LittleEndian.Uint16(props))
+		props = props[2:]
+		if col >= len(rd.header.Columns) {
+			return nil, ErrInvalidFile
+		}
+		c := rd.header.Columns[col]
+		size := valueSize(c.Type)
+		if size == 0 {
+			if len(props) < 4 {
+				return nil, ErrInvalidFile
+			}
+			size = 4 + int(binary.LittleEndian.Uint32(props))
+		}
+		if size < 0 || size > len(props) {
+			return nil, ErrInvalidFile
+		}
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = appendString(dst, c.Name)
+		dst = append(dst, ':')
+		dst = appendValue(dst, c.Type, props[:size])
+		props = props[size:]
+	}
+	return append(dst, "}}"...), nil
+}
+
+// valueSize returns the size of a value, or zero for values that have their
+// size before them.
+func valueSize(typ ColumnType) int {
+	switch typ {
+	case ColumnByte, ColumnUByte, ColumnBool:
+		return 1
+	case ColumnShort, ColumnUShort:
+		return 2
+	case ColumnInt, ColumnUInt, ColumnFloat:
+		return 4
+	case ColumnLong, ColumnULong, ColumnDouble:
+		return 8
+	}
+	return 0
+}
+
+func appendValue(dst []byte, typ ColumnType, b []byte) []byte {
+	le := binary.LittleEndian
+	switch typ {
+	case ColumnByte:
+		return strconv.AppendInt(dst, int64(int8(b[0])), 10)
+	case ColumnUByte:
+		return strconv.AppendUint(dst, uint64(b[0]), 10)
+	case ColumnBool:
+		return strconv.AppendBool(dst, b[0] != 0)
+	case ColumnShort:
+		return strconv.AppendInt(dst, int64(int16(le.Uint16(b))), 10)
+	case ColumnUShort:
+		return strconv.AppendUint(dst, uint64(le.Uint16(b)), 10)
+	case ColumnInt:
+		return strconv.AppendInt(dst, int64(int32(le.Uint32(b))), 10)
+	case ColumnUInt:
+		return strconv.AppendUint(dst, uint64(le.Uint32(b)), 10)
+	case ColumnLong:
+		return strconv.AppendInt(dst, int64(le.Uint64(b)), 10)
+	case ColumnULong:
+		return strconv.AppendUint(dst, le.Uint64(b), 10)
+	case ColumnFloat:
+		return appendFloat(dst, float64(math.Float32frombits(le.Uint32(b))), 32)
+	case ColumnDouble:
+		return appendFloat(dst, math.Float64frombits(le.Uint64(b)), 64)
+	case ColumnJSON:
+		if gjson.ValidBytes(b[4:]) {
+			return append(dst, b[4:]...)
+		}
+	case ColumnBinary:
+		return appendString(dst, base64.StdEncoding.EncodeToString(b[4:]))
+	}
+	return appendString(dst, string(b[4:]))
+}
+
+func appendString(dst []byte, s string) []byte {
+	b, _ := json.Marshal(s)
+	return append(dst, b...)
+}
+
+func appendFloat(dst []byte, f float64, bitSize int) []byte {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return append(dst, "null"...)
+	}
+	return strconv.AppendFloat(dst, f, 'f', -1, bitSize)
+}
+
+// geom is a decoded Geometry table
+type geom struct {
+	typ   byte
+	xy, z []float64
+	ends  []uint32
+	parts []table
+}
+
+func readGeometry(t table, typ byte, hasZ bool) (geom, error) {
+	g := geom{typ: t.uint8(geometryType, geomUnknown)}
+	if g.typ == geomUnknown {
+		g.typ = typ
+	}
+	var err error
+	if g.xy, err = t.float64s(geometryXY); err != nil {
+		return g, err
+	}
+	if len(g.xy)%2 != 0 {
+		return g, ErrInvalidFile
+	}
+	if hasZ {
+		if g.z, err = t.float64s(geometryZ); err != nil {
+			return g, err
+		}
+		if len(g.z) != len(g.xy)/2 {
+			g.z = nil
+		}
+	}
+	if g.ends, err = t.uint32s(geometryEnds); err != nil {
+		return g, err
+	}
+	g.parts, err = t.tables(geometryParts)
+	return g, err
+}
+
+// appendGeometry appends the GeoJSON of a Geometry table
+func appendGeometry(dst []byte, t table, typ byte, hasZ bool,
+) ([]byte, error) {
+	g, err := readGeometry(t, typ, hasZ)
+	if err != nil {
+		return nil, err
+	}
+	switch g.typ {
+	case geomPoint:
+		if len(g.xy) != 2 {
+			return nil, ErrInvalidFile
+		}
+		dst = append(dst, `{"type":"Point","coordinates":`...)
+		dst = g.appendPosition(dst, 0)
+	case geomLineString:
+		dst = append(dst, `{"type":"LineString","coordinates":`...)
+		dst = g.appendPositions(dst, 0, len(g.xy)/2)
+	case geomMultiPoint:
+		dst = append(dst, `{"type":"MultiPoint","coordinates":`...)
+		dst = g.appendPositions(dst, 0, len(g.xy)/2)
+	case geomPolygon:
+		dst = append(dst, `{"type":"Polygon","coordinates":`...)
+		if dst, err = g.appendRings(dst); err != nil {
+			return nil, err
+		}
+	case geomMultiLineString:
+		dst = append(dst, `{"type":"MultiLineString","coordinates":`...)
+		if dst, err = g.appendRings(dst); err != nil {
+			return nil, err
+		}
+	case geomMultiPolygon:
+		dst = append(dst, `{"type":"MultiPolygon","coordinates":[`...)
+		for i, part := range g.parts {
+			pg, err := readGeometry(part, geomPolygon, hasZ)
+			if err != nil {
+				return nil, err
+			}
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			if dst, err = pg.appendRings(dst); err != nil {
+				return nil, err
+			}
+		}
+		dst = append(dst, ']')
+	case geomGeometryCollection:
+		dst = append(dst, `{"type":"GeometryCollection","geometries":[`...)
+		for i, part := range g.parts {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			if dst, err = appendGeometry(dst, part, geomUnknown,
+				hasZ); err != nil {
+				return nil, err
+			}
+		}
+		dst = append(dst, ']')
+	default:
+		return nil, ErrInvalidFile
+	}
+	return append(dst, '}'), nil
+}
+
+func (g geom) appendPosition(dst []byte, i int) []byte {
+	dst = append(dst, '[')
+	dst = appendFloat(dst, g.xy[i*2], 64)
+	dst = append(dst, ',')
+	dst = appendFloat(dst, g.xy[i*2+1], 64)
+	if g.z != nil {
+		dst = append(dst, ',')
+		dst = appendFloat(dst, g.z[i], 64)
+	}
+	return append(dst, ']')
+}
+
+func (g geom) appendPositions(dst []byte, start, end int) []byte {
+	dst = append(dst, '[')
+	for i := start; i < end; i++ {
+		if i > start {
+			dst = append(dst, ',')
+		}
+		dst = g.appendPosition(dst, i)
+	}
+	return append(dst, ']')
+}
+
+// appendRings appends the rings or lines of a geometry, which end at the
+// point indexes of its ends.
+func (g geom) appendRings(dst []byte) ([]byte, error) {
+	n := len(g.xy) / 2
+	ends := g.ends
+	if len(ends) == 0 {
+		ends = []uint32{uint32(n)}
+	}
+	dst = append(dst, '[')
+	var start int
+	for i, end := range ends {
+		if int(end) < start || int(end) > n {
+			return nil, ErrInvalidFile
+		}
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = g.appendPositions(dst, start, int(end))
+		start = int(end)
+	}
+	return append(dst, ']'), nil
+}
+
+// Decode returns the features of a FlatGeobuf file as a FeatureCollection
+func Decode(data []byte, opts *geojson.ParseOptions,
+) (*geojson.FeatureCollection, error) {
+	rd, err := NewReader(bytes.NewReader(data), opts)
+	if err != nil {
+		return nil, err
+	}
+	var objs []geojson.Object
+	err = rd.Features(func(f *geojson.Feature) bool {
+		objs = append(objs, f)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return geojson.NewFeatureCollection(objs), nil
+}
@@ -0,0 +1,418 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package flatgeobuf
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/geojson/geometry"
+	"github.com/tidwall/gjson"
+)
+
+type item struct {
+	geom    gjson.Result
+	props   gjson.Result
+	rect    geometry.Rect
+	hilbert uint32
+}
+
+type encoder struct {
+	columns  []Column
+	index    map[string]int
+	geomType byte
+	hasZ     bool
+}
+
+// Encode returns a FlatGeobuf file of a FeatureCollection. The geometry and
+// the "properties" member of each feature are written, with the columns
+// inferred from the property values. When the options have an index node
+// size the features are sorted along a Hilbert curve and indexed by a packed
+// R-tree. The file is in WGS84.
+func Encode(fc *geojson.FeatureCollection, opts *EncodeOptions) []byte {
+	if opts == nil {
+		opts = DefaultEncodeOptions
+	}
+	children := fc.Children()
+	items := make([]item, len(children))
+	for i, child := range children {
+		var members string
+		if f, ok := child.(*geojson.Feature); ok {
+			members = f.Members()
+			child = f.Base()
+		}
+		if p, ok := child.(interface{ Primative() geojson.Object }); ok {
+			child = p.Primative()
+		}
+		items[i] = item{
+			geom:  gjson.Parse(child.JSON()),
+			props: gjson.Get(members, "properties"),
+			rect:  child.Rect(),
+		}
+	}
+	var e encoder
+	e.inferColumns(items)
+	for i, it := range items {
+		typ := geomTypes[it.geom.Get("type").String()]
+		if i == 0 {
+			e.geomType = typ
+		} else if typ != e.geomType {
+			e.geomType = geomUnknown
+		}
+	}
+	var envelope geometry.Rect
+	for i, it := range items {
+		if i == 0 {
+			envelope = it.rect
+		} else {
+			envelope = expand(envelope, it.rect)
+		}
+	}
+	nodeSize := opts.IndexNodeSize
+	if len(items) == 0 || nodeSize < 0 {
+		nodeSize = 0
+	} else if nodeSize == 1 {
+		nodeSize = 2
+	} else if nodeSize > math.MaxUint16 {
+		nodeSize = math.MaxUint16
+	}
+	if nodeSize > 0 {
+		sortHilbert(items, envelope)
+	}
+
+	var features []byte
+	offsets := make([]uint64, len(items))
+	rects := make([]geometry.Rect, len(items))
+	for i, it := range items {
+		offsets[i] = uint64(len(features))
+		rects[i] = it.rect
+		features = append(features, e.encodeFeature(it)...)
+	}
+
+	dst := append([]byte{}, magic[:]...)
+	dst = append(dst, e.encodeHeader(opts.Name, envelope, len(items),
+		nodeSize)...)
+	if nodeSize > 0 {
+		dst = appendIndex(dst, rects, offsets, nodeSize)
+	}
+	return append(dst, features...)
+}
+
+func expand(rect, other geometry.Rect) geometry.Rect {
+	rect.Min.X = math.Min(rect.Min.X, other.Min.X)
+	rect.Min.Y = math.Min(rect.Min.Y, other.Min.Y)
+	rect.Max.X = math.Max(rect.Max.X, other.Max.X)
+	rect.Max.Y = math.Max(rect.Max.Y, other.Max.Y)
+	return rect
+}
+
+// sortHilbert sorts items by the Hilbert values of their centers
+func sortHilbert(items []item, envelope geometry.Rect) {
+	width := envelope.Max.X - envelope.Min.X
+	height := envelope.Max.Y - envelope.Min.Y
+	for i := range items {
+		center := items[i].rect.Center()
+		var x, y uint32
+		if width > 0 {
+			x = uint32(math.MaxUint16 * (center.X - envelope.Min.X) / width)
+		}
+		if height > 0 {
+			y = uint32(math.MaxUint16 * (center.Y - envelope.Min.Y) / height)
+		}
+		items[i].hilbert = hilbert(x, y)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].hilbert < items[j].hilbert
+	})
+}
+
+// appendIndex appends the nodes of a packed R-tree. Leaf nodes refer to the
+// offsets of features and the other nodes refer to their first child.
+func appendIndex(dst []byte, rects []geometry.Rect, offsets []uint64,
+	nodeSize int,
+) []byte {
+	bounds := levelBounds(len(rects), nodeSize)
+	numNodes := bounds[0][1]
+	nodes := make([]geometry.Rect, numNodes)
+	nodeOffsets := make([]uint64, numNodes)
+	copy(nodes[bounds[0][0]:], rects)
+	copy(nodeOffsets[bounds[0][0]:], offsets)
+	for level := 0; level < len(bounds)-1; level++ {
+		parent := bounds[level+1][0]
+		for pos := bounds[level][0]; pos < bounds[level][1]; pos += nodeSize {
+			end := pos + nodeSize
+			if end > bounds[level][1] {
+				end = bounds[level][1]
+			}
+			rect := nodes[pos]
+			for i := pos + 1; i < end; i++ {
+				rect = expand(rect, nodes[i])
+			}
+			nodes[parent] = rect
+			nodeOffsets[parent] = uint64(pos)
+			parent++
+		}
+	}
+	var b [nodeItemSize]byte
+	for i, rect := range nodes {
+		binary.LittleEndian.PutUint64(b[0:], math.Float64bits(rect.Min.X))
+		binary.LittleEndian.PutUint64(b[8:], math.Float64bits(rect.Min.Y))
+		binary.LittleEndian.PutUint64(b[16:], math.Float64bits(rect.Max.X))
+		binary.LittleEndian.PutUint64(b[24:], math.Float64bits(rect.Max.Y))
+		binary.LittleEndian.PutUint64(b[32:], nodeOffsets[i])
+		dst = append(dst, b[:]...)
+	}
+	return dst
+}
+
+func columnTypeOf(value gjson.Result) (ColumnType, bool) {
+	switch value.Type {
+	case gjson.True, gjson.False:
+		return ColumnBool, true
+	case gjson.Number:
+		if !strings.ContainsAny(value.Raw, ".eE") {
+			if _, err := strconv.ParseInt(value.Raw, 10, 64); err == nil {
+				return ColumnLong, true
+			}
+		}
+		return ColumnDouble, true
+	case gjson.String:
+		return ColumnString, true
+	case gjson.JSON:
+		return ColumnJSON, true
+	}
+	return 0, false
+}
+
+// inferColumns adds a column for each property in the order they are first
+// found. Columns with values of different types are JSON, except for
+// integers and floats, which are doubles.
+func (e *encoder) inferColumns(items []item) {
+	e.index = make(map[string]int)
+	var typed []bool
+	for _, it := range items {
+		it.props.ForEach(func(key, value gjson.Result) bool {
+			i, ok := e.index[key.String()]
+			if !ok {
+				i = len(e.columns)
+				e.index[key.String()] = i
+				e.columns = append(e.columns,
+					Column{Name: key.String(), Type: ColumnString})
+				typed = append(typed, false)
+			}
+			typ, ok := columnTypeOf(value)
+			switch {
+			case !ok || typ == e.columns[i].Type:
+			case !typed[i]:
+				e.columns[i].Type = typ
+				typed[i] = true
+			case (typ == ColumnLong && e.columns[i].Type == ColumnDouble) ||
+				(typ == ColumnDouble && e.columns[i].Type == ColumnLong):
+				e.columns[i].Type = ColumnDouble
+			default:
+				e.columns[i].Type = ColumnJSON
+			}
+			return true
+		})
+	}
+}
+
+func (e *encoder) encodeHeader(name string, envelope geometry.Rect,
+	count, nodeSize int,
+) []byte {
+	var b builder
+	var nameOff, envelopeOff, columnsOff int
+	if name != "" {
+		nameOff = b.createString(name)
+	}
+	if count > 0 {
+		envelopeOff = b.createFloat64s([]float64{
+			envelope.Min.X, envelope.Min.Y, envelope.Max.X, envelope.Max.Y,
+		})
+	}
+	if len(e.columns) > 0 {
+		columns := make([]int, len(e.columns))
+		for i, c := range e.columns {
+			off := b.createString(c.Name)
+			b.startTable()
+			b.addOffset(columnName, off)
+			b.addUint8(columnType, byte(c.Type))
+			columns[i] = b.endTable()
+		}
+		columnsOff = b.createOffsets(columns)
+	}
+	org := b.createString("EPSG")
+	b.startTable()
+	b.addOffset(crsOrg, org)
+	b.addUint32(crsCode, 4326)
+	crs := b.endTable()
+
+	b.startTable()
+	b.addUint64(headerFeaturesCount, uint64(count))
+	if nameOff != 0 {
+		b.addOffset(headerName, nameOff)
+	}
+	if envelopeOff != 0 {
+		b.addOffset(headerEnvelope, envelopeOff)
+	}
+	if columnsOff != 0 {
+		b.addOffset(headerColumns, columnsOff)
+	}
+	b.addOffset(headerCrs, crs)
+	b.addUint16(headerIndexNodeSize, uint16(nodeSize))
+	b.addUint8(headerGeometryType, e.geomType)
+	if e.hasZ {
+		b.addUint8(headerHasZ, 1)
+	}
+	return b.finish(b.endTable())
+}
+
+func (e *encoder) encodeFeature(it item) []byte {
+	var b builder
+	geom := e.encodeGeometry(&b, geomTypes[it.geom.Get("type").String()],
+		it.geom, e.geomType == geomUnknown)
+	var propsOff int
+	if props := e.encodeProperties(it.props); len(props) > 0 {
+		propsOff = b.createBytes(props)
+	}
+	b.startTable()
+	b.addOffset(featureGeometry, geom)
+	if propsOff != 0 {
+		b.addOffset(featureProperties, propsOff)
+	}
+	return b.finish(b.endTable())
+}
+
+// encodeGeometry adds a Geometry table for GeoJSON geometry. The parts of
+// MultiPolygons and GeometryCollections are Geometry tables that always have
+// a type.
+func (e *encoder) encodeGeometry(b *builder, typ byte, g gjson.Result,
+	withType bool,
+) int {
+	var xy, z []float64
+	var ends []uint32
+	var parts []int
+	var hasZ bool
+	addPositions := func(positions []gjson.Result) {
+		for _, pos := range positions {
+			vals := pos.Array()
+			if len(vals) < 2 {
+				continue
+			}
+			xy = append(xy, vals[0].Float(), vals[1].Float())
+			var zval float64
+			if len(vals) > 2 {
+				zval = vals[2].Float()
+				hasZ = true
+			}
+			z = append(z, zval)
+		}
+	}
+	coords := g.Get("coordinates")
+	switch typ {
+	case geomPoint:
+		addPositions([]gjson.Result{coords})
+	case geomLineString, geomMultiPoint:
+		addPositions(coords.Array())
+	case geomPolygon, geomMultiLineString:
+		for _, ring := range coords.Array() {
+			addPositions(ring.Array())
+			ends = append(ends, uint32(len(xy)/2))
+		}
+		if len(ends) < 2 {
+			ends = nil
+		}
+	case geomMultiPolygon:
+		for _, poly := range coords.Array() {
+			parts = append(parts, e.encodeGeometry(b, geomPolygon,
+				gjson.Parse(`{"coordinates":`+poly.Raw+`}`), true))
+		}
+	case geomGeometryCollection:
+		for _, child := range g.Get("geometries").Array() {
+			parts = append(parts, e.encodeGeometry(b,
+				geomTypes[child.Get("type").String()], child, true))
+		}
+	}
+	var partsOff, endsOff, xyOff, zOff int
+	if len(parts) > 0 {
+		partsOff = b.createOffsets(parts)
+	}
+	if len(ends) > 0 {
+		endsOff = b.createUint32s(ends)
+	}
+	if len(xy) > 0 {
+		xyOff = b.createFloat64s(xy)
+	}
+	if hasZ {
+		zOff = b.createFloat64s(z)
+		e.hasZ = true
+	}
+	b.startTable()
+	if partsOff != 0 {
+		b.addOffset(geometryParts, partsOff)
+	}
+	if endsOff != 0 {
+		b.addOffset(geometryEnds, endsOff)
+	}
+	if xyOff != 0 {
+		b.addOffset(geometryXY, xyOff)
+	}
+	if zOff != 0 {
+		b.addOffset(geometryZ, zOff)
+	}
+	if withType {
+		b.addUint8(geometryType, typ)
+	}
+	return b.endTable()
+}
+
+// encodeProperties returns the column index and value of each property that
+// is not null.
+func (e *encoder) encodeProperties(props gjson.Result) []byte {
+	var dst []byte
+	props.ForEach(func(key, value gjson.Result) bool {
+		if value.Type == gjson.Null {
+			return true
+		}
+		i := e.index[key.String()]
+		dst = appendUint16(dst, uint16(i))
+		switch e.columns[i].Type {
+		case ColumnBool:
+			if value.Bool() {
+				dst = append(dst, 1)
+			} else {
+				dst = append(dst, 0)
+			}
+		case ColumnLong:
+			dst = appendUint64(dst, uint64(value.Int()))
+		case ColumnDouble:
+			dst = appendUint64(dst, math.Float64bits(value.Float()))
+		case ColumnString:
+			dst = appendUint32(dst, uint32(len(value.String())))
+			dst = append(dst, value.String()...)
+		default:
+			dst = appendUint32(dst, uint32(len(value.Raw)))
+			dst = append(dst, value.Raw...)
+		}
+		return true
+	})
+	return dst
+}
+
+func appendUint16(dst []byte, v uint16) []byte {
+	return append(dst, byte(v), byte(v>>8))
+}
+
+func appendUint32(dst []byte, v uint32) []byte {
+	return append(dst, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+func appendUint64(dst []byte, v uint64) []byte {
+	return appendUint32(appendUint32(dst, uint32(v)), uint32(v>>32))
+}
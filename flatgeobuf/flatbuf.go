@@ -0,0 +1,342 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package flatgeobuf
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// builder builds a flatbuffer from back to front, which places the objects
+// that a table refers to after the table. The bytes are kept in reverse
+// order, so the offset of an object from the end of the buffer is the
+// length at the time it was added.
+type builder struct {
+	rev        []byte
+	minalign   int
+	tableStart int
+	fields     []fieldLoc
+}
+
+type fieldLoc struct {
+	slot int
+	off  int
+}
+
+func (b *builder) offset() int {
+	return len(b.rev)
+}
+
+// prep pads the buffer so that it is aligned to size after additional bytes
+// are added.
+func (b *builder) prep(size, additional int) {
+	if size > b.minalign {
+		b.minalign = size
+	}
+	for (len(b.rev)+additional)%size != 0 {
+		b.rev = append(b.rev, 0)
+	}
+}
+
+func (b *builder) putUint16(v uint16) {
+	b.rev = append(b.rev, byte(v>>8), byte(v))
+}
+
+func (b *builder) putUint32(v uint32) {
+	b.rev = append(b.rev, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+func (b *builder) putUint64(v uint64) {
+	b.putUint32(uint32(v >> 32))
+	b.putUint32(uint32(v))
+}
+
+func (b *builder) putBytes(p []byte) {
+	for i := len(p) - 1; i >= 0; i-- {
+		b.rev = append(b.rev, p[i])
+	}
+}
+
+// putOffset adds an offset that refers to an object that was already added
+func (b *builder) putOffset(off int) {
+	b.prep(4, 0)
+	b.putUint32(uint32(len(b.rev) + 4 - off))
+}
+
+func (b *builder) createString(s string) int {
+	b.prep(4, len(s)+1)
+	b.rev = append(b.rev, 0)
+	b.putBytes([]byte(s))
+	b.putUint32(uint32(len(s)))
+	return b.offset()
+}
+
+func (b *builder) createBytes(p []byte) int {
+	b.prep(4, len(p))
+	b.putBytes(p)
+	b.putUint32(uint32(len(p)))
+	return b.offset()
+}
+
+func (b *builder) createFloat64s(vals []float64) int {
+	b.prep(4, len(vals)*8)
+	b.prep(8, len(vals)*8)
+	for i := len(vals) - 1; i >= 0; i-- {
+		b.putUint64(math.Float64bits(vals[i]))
+	}
+	b.putUint32(uint32(len(vals)))
+	return b.offset()
+}
+
+func (b *builder) createUint32s(vals []uint32) int {
+	b.prep(4, len(vals)*4)
+	for i := len(vals) - 1; i >= 0; i-- {
+		b.putUint32(vals[i])
+	}
+	b.putUint32(uint32(len(vals)))
+	return b.offset()
+}
+
+func (b *builder) createOffsets(offs []int) int {
+	b.prep(4, len(offs)*4)
+	for i := len(offs) - 1; i >= 0; i-- {
+		b.putOffset(offs[i])
+	}
+	b.putUint32(uint32(len(offs)))
+	return b.offset()
+}
+
+func (b *builder) startTable() {
+	b.fields = b.fields[:0]
+	b.tableStart = b.offset()
+}
+
+func (b *builder) slot(slot int) {
+	b.fields = append(b.fields, fieldLoc{slot, b.offset()})
+}
+
+func (b *builder) addUint8(slot int, v uint8) {
+	b.rev = append(b.rev, v)
+	b.slot(slot)
+}
+
+func (b *builder) addUint16(slot int, v uint16) {
+	b.prep(2, 0)
+	b.putUint16(v)
+	b.slot(slot)
+}
+
+func (b *builder) addUint32(slot int, v uint32) {
+	b.prep(4, 0)
+	b.putUint32(v)
+	b.slot(slot)
+}
+
+func (b *builder) addUint64(slot int, v uint64) {
+	b.prep(8, 0)
+	b.putUint64(v)
+	b.slot(slot)
+}
+
+func (b *builder) addOffset(slot int, off int) {
+	b.putOffset(off)
+	b.slot(slot)
+}
+
+// endTable adds the vtable of the table and returns the table offset
+func (b *builder) endTable() int {
+	b.prep(4, 0)
+	b.putUint32(0)
+	table := b.offset()
+	var n int
+	for _, f := range b.fields {
+		if f.slot >= n {
+			n = f.slot + 1
+		}
+	}
+	vtable := make([]uint16, n)
+	for _, f := range b.fields {
+		vtable[f.slot] = uint16(table - f.off)
+	}
+	for i := n - 1; i >= 0; i-- {
+		b.putUint16(vtable[i])
+	}
+	b.putUint16(uint16(table - b.tableStart))
+	b.putUint16(uint16(4 + n*2))
+	// the vtable is before the table
+	binary.BigEndian.PutUint32(b.rev[table-4:table], uint32(b.offset()-table))
+	return table
+}
+
+// finish returns the buffer with a root table and a size prefix
+func (b *builder) finish(root int) []byte {
+	b.prep(b.minalign, 8)
+	b.putOffset(root)
+	b.putUint32(uint32(len(b.rev)))
+	buf := make([]byte, len(b.rev))
+	for i, c := range b.rev {
+		buf[len(buf)-1-i] = c
+	}
+	return buf
+}
+
+// table is a flatbuffer table at pos. Reads outside of the buffer return
+// ErrInvalidFile.
+type table struct {
+	buf []byte
+	pos int
+}
+
+func rootTable(buf []byte) (table, error) {
+	if len(buf) < 4 {
+		return table{}, ErrInvalidFile
+	}
+	t := table{buf, int(binary.LittleEndian.Uint32(buf))}
+	if t.pos < 0 || t.pos > len(buf)-4 {
+		return table{}, ErrInvalidFile
+	}
+	return t, nil
+}
+
+// field returns the position of a field, or zero when it is not present
+func (t table) field(slot int) int {
+	vt := t.pos - int(int32(binary.LittleEndian.Uint32(t.buf[t.pos:])))
+	if vt < 0 || vt > len(t.buf)-4 {
+		return 0
+	}
+	o := 4 + slot*2
+	if o+2 > int(binary.LittleEndian.Uint16(t.buf[vt:])) ||
+		vt+o+2 > len(t.buf) {
+		return 0
+	}
+	fo := int(binary.LittleEndian.Uint16(t.buf[vt+o:]))
+	if fo == 0 {
+		return 0
+	}
+	return t.pos + fo
+}
+
+// scalar returns the bytes of a scalar field, or nil when it is not present
+func (t table) scalar(slot, size int) []byte {
+	pos := t.field(slot)
+	if pos == 0 || pos > len(t.buf)-size {
+		return nil
+	}
+	return t.buf[pos : pos+size]
+}
+
+func (t table) uint8(slot int, def uint8) uint8 {
+	if b := t.scalar(slot, 1); b != nil {
+		return b[0]
+	}
+	return def
+}
+
+func (t table) uint16(slot int, def uint16) uint16 {
+	if b := t.scalar(slot, 2); b != nil {
+		return binary.LittleEndian.Uint16(b)
+	}
+	return def
+}
+
+func (t table) uint64(slot int, def uint64) uint64 {
+	if b := t.scalar(slot, 8); b != nil {
+		return binary.LittleEndian.Uint64(b)
+	}
+	return def
+}
+
+// indirect returns the position of the object that a field refers to, or
+// zero when it is not present.
+func (t table) indirect(slot int) (int, error) {
+	pos := t.field(slot)
+	if pos == 0 {
+		return 0, nil
+	}
+	if pos > len(t.buf)-4 {
+		return 0, ErrInvalidFile
+	}
+	pos += int(binary.LittleEndian.Uint32(t.buf[pos:]))
+	if pos < 0 || pos > len(t.buf)-4 {
+		return 0, ErrInvalidFile
+	}
+	return pos, nil
+}
+
+// vector returns the position and length of a vector field
+func (t table) vector(slot, elemSize int) (pos, n int, err error) {
+	pos, err = t.indirect(slot)
+	if pos == 0 || err != nil {
+		return 0, 0, err
+	}
+	n = int(binary.LittleEndian.Uint32(t.buf[pos:]))
+	pos += 4
+	if n < 0 || n > (len(t.buf)-pos)/elemSize {
+		return 0, 0, ErrInvalidFile
+	}
+	return pos, n, nil
+}
+
+func (t table) bytes(slot int) ([]byte, error) {
+	pos, n, err := t.vector(slot, 1)
+	if err != nil {
+		return nil, err
+	}
+	return t.buf[pos : pos+n], nil
+}
+
+func (t table) string(slot int) (string, error) {
+	b, err := t.bytes(slot)
+	return string(b), err
+}
+
+func (t table) float64s(slot int) ([]float64, error) {
+	pos, n, err := t.vector(slot, 8)
+	if err != nil || n == 0 {
+		return nil, err
+	}
+	vals := make([]float64, n)
+	for i := range vals {
+		vals[i] = math.Float64frombits(
+			binary.LittleEndian.Uint64(t.buf[pos+i*8:]))
+	}
+	return vals, nil
+}
+
+func (t table) uint32s(slot int) ([]uint32, error) {
+	pos, n, err := t.vector(slot, 4)
+	if err != nil || n == 0 {
+		return nil, err
+	}
+	vals := make([]uint32, n)
+	for i := range vals {
+		vals[i] = binary.LittleEndian.Uint32(t.buf[pos+i*4:])
+	}
+	return vals, nil
+}
+
+func (t table) table(slot int) (table, bool, error) {
+	pos, err := t.indirect(slot)
+	if pos == 0 || err != nil {
+		return table{}, false, err
+	}
+	return table{t.buf, pos}, true, nil
+}
+
+func (t table) tables(slot int) ([]table, error) {
+	pos, n, err := t.vector(slot, 4)
+	if err != nil || n == 0 {
+		return nil, err
+	}
+	tables := make([]table, n)
+	for i := range tables {
+		p := pos + i*4
+		tables[i] = table{t.buf, p + int(binary.LittleEndian.Uint32(t.buf[p:]))}
+		if tables[i].pos < 0 || tables[i].pos > len(t.buf)-4 {
+			return nil, ErrInvalidFile
+		}
+	}
+	return tables, nil
+}
@@ -0,0 +1,197 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+// Package flatgeobuf reads and writes FlatGeobuf files, which are
+// FeatureCollections in flatbuffers with an optional packed Hilbert R-tree.
+package flatgeobuf
+
+import (
+	"errors"
+
+	"github.com/tidwall/geojson/geometry"
+)
+
+// ErrInvalidFile is returned when data is not a valid FlatGeobuf file
+var ErrInvalidFile = errors.New("invalid flatgeobuf file")
+
+// magic is the start of a file, with the major version 3
+var magic = [8]byte{'f', 'g', 'b', 3, 'f', 'g', 'b', 0}
+
+// maxHeaderSize is the largest header that is read
+const maxHeaderSize = 10 * 1024 * 1024
+
+// nodeItemSize is the size of an index node, which is a bbox and an offset
+const nodeItemSize = 40
+
+// Geometry types
+const (
+	geomUnknown            = 0
+	geomPoint              = 1
+	geomLineString         = 2
+	geomPolygon            = 3
+	geomMultiPoint         = 4
+	geomMultiLineString    = 5
+	geomMultiPolygon       = 6
+	geomGeometryCollection = 7
+)
+
+var geomTypes = map[string]byte{
+	"Point":              geomPoint,
+	"LineString":         geomLineString,
+	"Polygon":            geomPolygon,
+	"MultiPoint":         geomMultiPoint,
+	"MultiLineString":    geomMultiLineString,
+	"MultiPolygon":       geomMultiPolygon,
+	"GeometryCollection": geomGeometryCollection,
+}
+
+// Header table slots
+const (
+	headerName          = 0
+	headerEnvelope      = 1
+	headerGeometryType  = 2
+	headerHasZ          = 3
+	headerColumns       = 7
+	headerFeaturesCount = 8
+	headerIndexNodeSize = 9
+	headerCrs           = 10
+)
+
+// Column, Crs, Feature, and Geometry table slots
+const (
+	columnName = 0
+	columnType = 1
+
+	crsOrg  = 0
+	crsCode = 1
+
+	featureGeometry   = 0
+	featureProperties = 1
+
+	geometryEnds  = 0
+	geometryXY    = 1
+	geometryZ     = 2
+	geometryType  = 6
+	geometryParts = 7
+)
+
+// ColumnType is the type of the values of a column
+type ColumnType byte
+
+// Column types
+const (
+	ColumnByte ColumnType = iota
+	ColumnUByte
+	ColumnBool
+	ColumnShort
+	ColumnUShort
+	ColumnInt
+	ColumnUInt
+	ColumnLong
+	ColumnULong
+	ColumnFloat
+	ColumnDouble
+	ColumnString
+	ColumnJSON
+	ColumnDateTime
+	ColumnBinary
+)
+
+// Column is a feature property
+type Column struct {
+	Name string
+	Type ColumnType
+}
+
+// Header is the header of a file
+type Header struct {
+	Name          string
+	Envelope      geometry.Rect
+	HasZ          bool
+	FeaturesCount int
+	IndexNodeSize int
+	Columns       []Column
+}
+
+// EncodeOptions ...
+type EncodeOptions struct {
+	// Name is the name of the dataset
+	Name string
+	// IndexNodeSize is the number of children of each node of the index. A
+	// size of zero writes no index.
+	IndexNodeSize int
+}
+
+// DefaultEncodeOptions ...
+var DefaultEncodeOptions = &EncodeOptions{
+	IndexNodeSize: 16,
+}
+
+// levelBounds returns the range of nodes of each level of a packed R-tree,
+// from the leaves up to the root. The root is the first node.
+func levelBounds(numItems, nodeSize int) [][2]int {
+	n := numItems
+	numNodes := n
+	levelNumNodes := []int{n}
+	for {
+		n = (n + nodeSize - 1) / nodeSize
+		numNodes += n
+		levelNumNodes = append(levelNumNodes, n)
+		if n == 1 {
+			break
+		}
+	}
+	bounds := make([][2]int, len(levelNumNodes))
+	n = numNodes
+	for i, size := range levelNumNodes {
+		bounds[i] = [2]int{n - size, n}
+		n -= size
+	}
+	return bounds
+}
+
+// hilbert returns the position of x, y on a Hilbert curve of order 16
+func hilbert(x, y uint32) uint32 {
+	a := x ^ y
+	b := 0xFFFF ^ a
+	c := 0xFFFF ^ (x | y)
+	d := x & (y ^ 0xFFFF)
+
+	A := a | (b >> 1)
+	B := (a >> 1) ^ a
+	C := ((c >> 1) ^ (b & (d >> 1))) ^ c
+	D := ((a & (c >> 1)) ^ (d >> 1)) ^ d
+
+	a, b, c, d = A, B, C, D
+	A = (a & (a >> 2)) ^ (b & (b >> 2))
+	B = (a & (b >> 2)) ^ (b & ((a ^ b) >> 2))
+	C ^= (a & (c >> 2)) ^ (b & (d >> 2))
+	D ^= (b & (c >> 2)) ^ ((a ^ b) & (d >> 2))
+
+	a, b, c, d = A, B, C, D
+	A = (a & (a >> 4)) ^ (b & (b >> 4))
+	B = (a & (b >> 4)) ^ (b & ((a ^ b) >> 4))
+	C ^= (a & (c >> 4)) ^ (b & (d >> 4))
+	D ^= (b & (c >> 4)) ^ ((a ^ b) & (d >> 4))
+
+	a, b, c, d = A, B, C, D
+	C ^= (a & (c >> 8)) ^ (b & (d >> 8))
+	D ^= (b & (c >> 8)) ^ ((a ^ b) & (d >> 8))
+
+	a = C ^ (C >> 1)
+	b = D ^ (D >> 1)
+
+	i0 := x ^ y
+	i1 := b | (0xFFFF ^ (i0 | a))
+	return interleave(i1)<<1 | interleave(i0)
+}
+
+// interleave spreads the low 16 bits of v to the even bits
+func interleave(v uint32) uint32 {
+	v = (v | (v << 8)) & 0x00FF00FF
+	v = (v | (v << 4)) & 0x0F0F0F0F
+	v = (v | (v << 2)) & 0x33333333
+	v = (v | (v << 1)) & 0x55555555
+	return v
+}
@@ -0,0 +1,173 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package flatgeobuf
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/geojson/geometry"
+)
+
+func expect(t testing.TB, what bool) {
+	t.Helper()
+	if !what {
+		t.Fatal("expection failure")
+	}
+}
+
+func parseFC(t *testing.T, json string) *geojson.FeatureCollection {
+	t.Helper()
+	obj, err := geojson.Parse(json, nil)
+	expect(t, err == nil)
+	return obj.(*geojson.FeatureCollection)
+}
+
+func TestRoundTrip(t *testing.T) {
+	fc := parseFC(t, `{"type":"FeatureCollection","features":[
+		{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2,3]},"properties":{"name":"a","n":1,"f":1.5,"ok":true,"tags":["x"]}},
+		{"type":"Feature","geometry":{"type":"LineString","coordinates":[[0,0],[1,1]]},"properties":{"name":"b","n":2.25,"mixed":1}},
+		{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[2,2],[4,2],[4,4],[2,2]]]},"properties":{"mixed":"two","empty":null}},
+		{"type":"Feature","geometry":{"type":"MultiPoint","coordinates":[[5,5],[6,6]]},"properties":{}},
+		{"type":"Feature","geometry":{"type":"MultiLineString","coordinates":[[[0,0],[1,1]],[[2,2],[3,3]]]},"properties":{}},
+		{"type":"Feature","geometry":{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,0]]],[[[5,5],[6,5],[6,6],[5,5]]]]},"properties":{}},
+		{"type":"Feature","geometry":{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[7,7]},{"type":"LineString","coordinates":[[8,8],[9,9]]}]},"properties":{}}
+	]}`)
+	for _, opts := range []*EncodeOptions{nil, {Name: "test"}} {
+		data := Encode(fc, opts)
+		expect(t, bytes.Equal(data[:8], magic[:]))
+		rd, err := NewReader(bytes.NewReader(data), nil)
+		expect(t, err == nil)
+		header := rd.Header()
+		expect(t, header.FeaturesCount == 7)
+		expect(t, header.HasZ)
+		expect(t, header.Envelope == geometry.Rect{
+			Min: geometry.Point{X: 0, Y: 0}, Max: geometry.Point{X: 10, Y: 10}})
+		expect(t, len(header.Columns) == 7)
+		types := map[string]ColumnType{}
+		for _, c := range header.Columns {
+			types[c.Name] = c.Type
+		}
+		expect(t, types["name"] == ColumnString)
+		expect(t, types["n"] == ColumnDouble)
+		expect(t, types["f"] == ColumnDouble)
+		expect(t, types["ok"] == ColumnBool)
+		expect(t, types["tags"] == ColumnJSON)
+		expect(t, types["mixed"] == ColumnJSON)
+		expect(t, types["empty"] == ColumnString)
+
+		decoded, err := Decode(data, nil)
+		expect(t, err == nil)
+		children := decoded.Children()
+		expect(t, len(children) == 7)
+		// every feature is found, with the same geometry and properties
+		for _, orig := range fc.Children() {
+			var found bool
+			for _, child := range children {
+				if child.(*geojson.Feature).Base().JSON() ==
+					orig.(*geojson.Feature).Base().JSON() {
+					found = true
+					break
+				}
+			}
+			expect(t, found)
+		}
+		if opts == nil {
+			expect(t, header.IndexNodeSize == 16 && header.Name == "")
+		} else {
+			expect(t, header.IndexNodeSize == 0 && header.Name == "test")
+			// the order of the features is kept, and null values are left out
+			for i, child := range children {
+				if i != 2 {
+					expect(t, child.JSON() == fc.Children()[i].JSON())
+				}
+			}
+			expect(t, children[2].(*geojson.Feature).Members() ==
+				`{"properties":{"mixed":"two"}}`)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	seed := time.Now().UnixNano()
+	rand.Seed(seed)
+	var objs []geojson.Object
+	for i := 0; i < 1000; i++ {
+		x, y := rand.Float64()*360-180, rand.Float64()*180-90
+		objs = append(objs, geojson.NewFeature(geojson.NewRect(geometry.Rect{
+			Min: geometry.Point{X: x, Y: y},
+			Max: geometry.Point{X: x + rand.Float64(), Y: y + rand.Float64()},
+		}), `{"properties":{"i":`+string(rune('0'+i%10))+`}}`))
+	}
+	fc := geojson.NewFeatureCollection(objs)
+	for _, nodeSize := range []int{0, 2, 16} {
+		data := Encode(fc, &EncodeOptions{IndexNodeSize: nodeSize})
+		rd, err := NewReader(bytes.NewReader(data), nil)
+		expect(t, err == nil)
+		for i := 0; i < 50; i++ {
+			x, y := rand.Float64()*360-180, rand.Float64()*180-90
+			rect := geometry.Rect{
+				Min: geometry.Point{X: x, Y: y},
+				Max: geometry.Point{X: x + 20, Y: y + 10},
+			}
+			var expected int
+			for _, obj := range objs {
+				if obj.Rect().IntersectsRect(rect) {
+					expected++
+				}
+			}
+			var count int
+			err := rd.Search(rect, func(f *geojson.Feature) bool {
+				expect(t, f.Rect().IntersectsRect(rect))
+				count++
+				return true
+			})
+			expect(t, err == nil)
+			if count != expected {
+				t.Fatalf("seed %d: expected %d features, got %d", seed,
+					expected, count)
+			}
+		}
+		// stop early
+		var count int
+		rd.Search(geometry.Rect{
+			Min: geometry.Point{X: -180, Y: -90},
+			Max: geometry.Point{X: 180, Y: 90},
+		}, func(f *geojson.Feature) bool {
+			count++
+			return count < 10
+		})
+		expect(t, count == 10)
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	fc := parseFC(t, `{"type":"FeatureCollection","features":[
+		{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":{"a":1}}
+	]}`)
+	data := Encode(fc, nil)
+	_, err := NewReader(bytes.NewReader(data[:10]), nil)
+	expect(t, err == ErrInvalidFile)
+	_, err = NewReader(bytes.NewReader(append([]byte("fgb\x02"), data[4:]...)),
+		nil)
+	expect(t, err == ErrInvalidFile)
+	_, err = Decode(data[:len(data)-3], nil)
+	expect(t, err == ErrInvalidFile)
+	// every truncated or corrupted file is an error, and never a panic
+	for i := 8; i < len(data); i++ {
+		Decode(data[:i], nil)
+		corrupt := append([]byte{}, data...)
+		corrupt[i] ^= 0xFF
+		Decode(corrupt, nil)
+	}
+
+	// an empty collection
+	data = Encode(geojson.NewFeatureCollection(nil), nil)
+	decoded, err := Decode(data, nil)
+	expect(t, err == nil && len(decoded.Children()) == 0)
+}
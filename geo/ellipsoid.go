@@ -0,0 +1,149 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geo
+
+import (
+	"math"
+)
+
+// WGS84 ellipsoid
+const (
+	wgs84A = 6378137.0         // semi-major axis in meters
+	wgs84F = 1 / 298.257223563 // flattening
+	wgs84B = wgs84A * (1 - wgs84F)
+)
+
+// InverseWGS84 solves the inverse geodesic problem on the WGS84 ellipsoid
+// using Vincenty's formulae. It returns the distance in meters between two
+// points, and the initial and final bearings in degrees.
+// The formulae may not converge for nearly antipodal points, in which case
+// the spherical solution is returned.
+func InverseWGS84(latA, lonA, latB, lonB float64) (
+	meters, initialBearing, finalBearing float64,
+) {
+	// see https://www.movable-type.co.uk/scripts/latlong-vincenty.html
+	φ1 := latA * radians
+	φ2 := latB * radians
+	L := (lonB - lonA) * radians
+	tanU1 := (1 - wgs84F) * math.Tan(φ1)
+	cosU1 := 1 / math.Sqrt(1+tanU1*tanU1)
+	sinU1 := tanU1 * cosU1
+	tanU2 := (1 - wgs84F) * math.Tan(φ2)
+	cosU2 := 1 / math.Sqrt(1+tanU2*tanU2)
+	sinU2 := tanU2 * cosU2
+
+	var sinλ, cosλ, sinσ, cosσ, σ, sinα, cosSqα, cos2σm float64
+	λ := L
+	converged := false
+	for i := 0; i < 200; i++ {
+		sinλ, cosλ = math.Sin(λ), math.Cos(λ)
+		x := cosU1*sinU2 - sinU1*cosU2*cosλ
+		sinσ = math.Sqrt(cosU2*sinλ*cosU2*sinλ + x*x)
+		if sinσ == 0 {
+			// coincident points
+			return 0, 0, 0
+		}
+		cosσ = sinU1*sinU2 + cosU1*cosU2*cosλ
+		σ = math.Atan2(sinσ, cosσ)
+		sinα = cosU1 * cosU2 * sinλ / sinσ
+		cosSqα = 1 - sinα*sinα
+		if cosSqα != 0 {
+			cos2σm = cosσ - 2*sinU1*sinU2/cosSqα
+		} else {
+			// equatorial line
+			cos2σm = 0
+		}
+		C := wgs84F / 16 * cosSqα * (4 + wgs84F*(4-3*cosSqα))
+		λʹ := λ
+		λ = L + (1-C)*wgs84F*sinα*
+			(σ+C*sinσ*(cos2σm+C*cosσ*(-1+2*cos2σm*cos2σm)))
+		if math.Abs(λ-λʹ) < 1e-12 {
+			converged = true
+			break
+		}
+	}
+	if !converged {
+		return DistanceTo(latA, lonA, latB, lonB),
+			BearingTo(latA, lonA, latB, lonB),
+			math.Mod(BearingTo(latB, lonB, latA, lonA)+180, 360)
+	}
+	uSq := cosSqα * (wgs84A*wgs84A - wgs84B*wgs84B) / (wgs84B * wgs84B)
+	A := 1 + uSq/16384*(4096+uSq*(-768+uSq*(320-175*uSq)))
+	B := uSq / 1024 * (256 + uSq*(-128+uSq*(74-47*uSq)))
+	Δσ := B * sinσ * (cos2σm + B/4*(cosσ*(-1+2*cos2σm*cos2σm)-
+		B/6*cos2σm*(-3+4*sinσ*sinσ)*(-3+4*cos2σm*cos2σm)))
+	meters = wgs84B * A * (σ - Δσ)
+	α1 := math.Atan2(cosU2*sinλ, cosU1*sinU2-sinU1*cosU2*cosλ)
+	α2 := math.Atan2(cosU1*sinλ, -sinU1*cosU2+cosU1*sinU2*cosλ)
+	initialBearing = math.Mod(α1*degrees+360, 360)
+	finalBearing = math.Mod(α2*degrees+360, 360)
+	return meters, initialBearing, finalBearing
+}
+
+// DirectWGS84 solves the direct geodesic problem on the WGS84 ellipsoid
+// using Vincenty's formulae. It returns the destination from a point based
+// on a distance and initial bearing, and the final bearing in degrees.
+func DirectWGS84(lat, lon, meters, bearingDegrees float64) (
+	destLat, destLon, finalBearing float64,
+) {
+	// see https://www.movable-type.co.uk/scripts/latlong-vincenty.html
+	φ1 := lat * radians
+	λ1 := lon * radians
+	α1 := bearingDegrees * radians
+	sinα1, cosα1 := math.Sin(α1), math.Cos(α1)
+	tanU1 := (1 - wgs84F) * math.Tan(φ1)
+	cosU1 := 1 / math.Sqrt(1+tanU1*tanU1)
+	sinU1 := tanU1 * cosU1
+	σ1 := math.Atan2(tanU1, cosα1)
+	sinα := cosU1 * sinα1
+	cosSqα := 1 - sinα*sinα
+	uSq := cosSqα * (wgs84A*wgs84A - wgs84B*wgs84B) / (wgs84B * wgs84B)
+	A := 1 + uSq/16384*(4096+uSq*(-768+uSq*(320-175*uSq)))
+	B := uSq / 1024 * (256 + uSq*(-128+uSq*(74-47*uSq)))
+
+	var sinσ, cosσ, cos2σm float64
+	σ := meters / (wgs84B * A)
+	for i := 0; i < 100; i++ {
+		cos2σm = math.Cos(2*σ1 + σ)
+		sinσ, cosσ = math.Sin(σ), math.Cos(σ)
+		Δσ := B * sinσ * (cos2σm + B/4*(cosσ*(-1+2*cos2σm*cos2σm)-
+			B/6*cos2σm*(-3+4*sinσ*sinσ)*(-3+4*cos2σm*cos2σm)))
+		σʹ := σ
+		σ = meters/(wgs84B*A) + Δσ
+		if math.Abs(σ-σʹ) < 1e-12 {
+			break
+		}
+	}
+	sinσ, cosσ = math.Sin(σ), math.Cos(σ)
+	cos2σm = math.Cos(2*σ1 + σ)
+	x := sinU1*sinσ - cosU1*cosσ*cosα1
+	φ2 := math.Atan2(sinU1*cosσ+cosU1*sinσ*cosα1,
+		(1-wgs84F)*math.Sqrt(sinα*sinα+x*x))
+	λ := math.Atan2(sinσ*sinα1, cosU1*cosσ-sinU1*sinσ*cosα1)
+	C := wgs84F / 16 * cosSqα * (4 + wgs84F*(4-3*cosSqα))
+	L := λ - (1-C)*wgs84F*sinα*
+		(σ+C*sinσ*(cos2σm+C*cosσ*(-1+2*cos2σm*cos2σm)))
+	λ2 := λ1 + L
+	λ2 = math.Mod(λ2+3*math.Pi, 2*math.Pi) - math.Pi // normalise to -180..+180°
+	α2 := math.Atan2(sinα, -x)
+	return φ2 * degrees, λ2 * degrees, math.Mod(α2*degrees+360, 360)
+}
+
+// authalicLatitude converts a geodetic latitude on the WGS84 ellipsoid into
+// a latitude on the sphere that has the same surface area.
+func authalicLatitude(lat float64) float64 {
+	e := math.Sqrt(wgs84F * (2 - wgs84F))
+	q := func(sinφ float64) float64 {
+		esinφ := e * sinφ
+		return (1 - e*e) * (sinφ/(1-esinφ*esinφ) -
+			1/(2*e)*math.Log((1-esinφ)/(1+esinφ)))
+	}
+	qp := q(1)
+	return math.Asin(q(math.Sin(lat*radians))/qp) * degrees
+}
+
+// authalicRadius is the radius of the sphere that has the same surface area
+// as the WGS84 ellipsoid.
+const authalicRadius = 6371007.1809
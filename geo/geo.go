@@ -85,6 +85,51 @@ func BearingTo(latA, lonA, latB, lonB float64) float64 {
 	return math.Mod(θ*degrees+360, 360)
 }
 
+// DistanceToSegment returns the shortest distance in meters from a point to
+// the segment running from point 'A' to point 'B'. Like the edges of a
+// GeoJSON geometry, the segment is a straight line in lat/lon space.
+func DistanceToSegment(lat, lon, latA, lonA, latB, lonB float64) (
+	meters float64,
+) {
+	haversineAt := func(t float64) float64 {
+		return Haversine(lat, lon, latA+(latB-latA)*t, lonA+(lonB-lonA)*t)
+	}
+	_, h := SegmentMin(haversineAt)
+	return DistanceFromHaversine(h)
+}
+
+// SegmentMin uses a golden-section search to find the t from 0 to 1 where
+// f(t) is the smallest, such as the closest point along a segment, and
+// returns t and f(t). The f function must have a single minimum from 0 to 1.
+func SegmentMin(f func(t float64) float64) (t, value float64) {
+	const invφ = 0.6180339887498949
+	a, b := 0.0, 1.0
+	c, d := b-invφ*(b-a), a+invφ*(b-a)
+	fc, fd := f(c), f(d)
+	for i := 0; i < 64; i++ {
+		if fc < fd {
+			b, d, fd = d, c, fc
+			c = b - invφ*(b-a)
+			fc = f(c)
+		} else {
+			a, c, fc = c, d, fd
+			d = a + invφ*(b-a)
+			fd = f(d)
+		}
+	}
+	t, value = c, fc
+	if fd < value {
+		t, value = d, fd
+	}
+	if f0 := f(0); f0 < value {
+		t, value = 0, f0
+	}
+	if f1 := f(1); f1 < value {
+		t, value = 1, f1
+	}
+	return t, value
+}
+
 // RectFromCenter calculates the bounding box surrounding a circle.
 func RectFromCenter(lat, lon, meters float64) (
 	minLat, minLon, maxLat, maxLon float64,
@@ -155,6 +155,108 @@ func TestNormalizeDistance(t *testing.T) {
 	}
 }
 
+func TestDistanceToSegment(t *testing.T) {
+	// point beside a meridian segment
+	value := DistanceToSegment(5, 1, 0, 0, 10, 0)
+	expect := math.Asin(math.Cos(5*radians)*math.Sin(1*radians)) * earthRadius
+	if math.Abs(value-expect) > 1e-6 {
+		t.Fatalf("expected '%v', got '%v'", expect, value)
+	}
+	// point past the end of the segment
+	value = DistanceToSegment(20, 0, 0, 0, 10, 0)
+	expect = DistanceTo(20, 0, 10, 0)
+	if math.Abs(value-expect) > 1e-6 {
+		t.Fatalf("expected '%v', got '%v'", expect, value)
+	}
+	// degenerate segment
+	value = DistanceToSegment(1, 1, 3, 3, 3, 3)
+	expect = DistanceTo(1, 1, 3, 3)
+	if value != expect {
+		t.Fatalf("expected '%v', got '%v'", expect, value)
+	}
+	// compare against points sampled along the segment
+	for i := 0; i < 1000; i++ {
+		lat := rand.Float64()*20 - 10
+		lon := rand.Float64()*20 - 10
+		latA := rand.Float64()*20 - 10
+		lonA := rand.Float64()*20 - 10
+		latB := rand.Float64()*20 - 10
+		lonB := rand.Float64()*20 - 10
+		dist := DistanceTo(latA, lonA, latB, lonB)
+		min := math.Inf(+1)
+		const steps = 1000
+		for j := 0; j <= steps; j++ {
+			t := float64(j) / steps
+			min = math.Min(min, DistanceTo(lat, lon,
+				latA+(latB-latA)*t, lonA+(lonB-lonA)*t))
+		}
+		value := DistanceToSegment(lat, lon, latA, lonA, latB, lonB)
+		if value > min+1e-6 || value < min-dist/steps {
+			t.Fatalf("expected about '%v', got '%v'", min, value)
+		}
+	}
+}
+
+func TestUnits(t *testing.T) {
+	tests := []struct {
+		abbr   string
+		unit   Unit
+		meters float64
+	}{
+		{"m", Meters, 1},
+		{"km", Kilometers, 1000},
+		{"mi", Miles, 1609.344},
+		{"ft", Feet, 0.3048},
+		{"yd", Yards, 0.9144},
+		{"nmi", NauticalMiles, 1852},
+	}
+	for _, tt := range tests {
+		unit, ok := ParseUnit(tt.abbr)
+		if !ok || unit != tt.unit {
+			t.Fatalf("expected '%v', got '%v'", tt.unit, unit)
+		}
+		if unit.String() != tt.abbr {
+			t.Fatalf("expected '%v', got '%v'", tt.abbr, unit.String())
+		}
+		if unit.ToMeters(2) != tt.meters*2 {
+			t.Fatalf("expected '%v', got '%v'", tt.meters*2, unit.ToMeters(2))
+		}
+		if unit.FromMeters(tt.meters*2) != 2 {
+			t.Fatalf("expected '%v', got '%v'", 2, unit.FromMeters(tt.meters*2))
+		}
+	}
+	if _, ok := ParseUnit("parsecs"); ok {
+		t.Fatal("expected false")
+	}
+	if value := ConvertDistance(1, Miles, Feet); math.Abs(value-5280) > 1e-9 {
+		t.Fatalf("expected '%v', got '%v'", 5280, value)
+	}
+	if value := ConvertDistance(3, Yards, Feet); math.Abs(value-9) > 1e-9 {
+		t.Fatalf("expected '%v', got '%v'", 9, value)
+	}
+	if value := ConvertDistance(1, NauticalMiles, Kilometers); value != 1.852 {
+		t.Fatalf("expected '%v', got '%v'", 1.852, value)
+	}
+	// invalid units are not written or read as text
+	if s := Unit(100).String(); s != "" {
+		t.Fatalf("expected '', got '%v'", s)
+	}
+	if _, err := Unit(100).MarshalText(); err == nil {
+		t.Fatal("expected error")
+	}
+	var unit Unit
+	if err := unit.UnmarshalText([]byte("Unknown")); err == nil {
+		t.Fatal("expected error")
+	}
+	if text, err := Miles.MarshalText(); err != nil || string(text) != "mi" {
+		t.Fatalf("expected 'mi', got '%s'", text)
+	}
+	if err := unit.UnmarshalText([]byte("nmi")); err != nil ||
+		unit != NauticalMiles {
+		t.Fatalf("expected '%v', got '%v'", NauticalMiles, unit)
+	}
+}
+
 type point struct {
 	lat, lon float64
 }
@@ -190,3 +292,290 @@ func BenchmarkDistanceTo(b *testing.B) {
 		DistanceTo(pointA.lat, pointA.lon, points[i].lat, points[i].lon)
 	}
 }
+
+func TestGreatCirclePath(t *testing.T) {
+	near := func(value, expect, epsilon float64) {
+		t.Helper()
+		if math.Abs(value-expect) > epsilon {
+			t.Fatalf("expected '%v', got '%v'", expect, value)
+		}
+	}
+	// Cambridge to Paris
+	latA, lonA := 52.205, 0.119
+	latB, lonB := 48.857, 2.351
+	lat, lon := Midpoint(latA, lonA, latB, lonB)
+	near(lat, 50.5363, 1e-4)
+	near(lon, 1.2746, 1e-4)
+	lat2, lon2 := IntermediatePoint(latA, lonA, latB, lonB, 0.5)
+	near(lat2, lat, 1e-12)
+	near(lon2, lon, 1e-12)
+	lat, lon = IntermediatePoint(latA, lonA, latB, lonB, 0.25)
+	near(lat, 51.3721, 1e-4)
+	near(lon, 0.7073, 1e-4)
+	lat, lon = IntermediatePoint(latA, lonA, latB, lonB, 0)
+	near(lat, latA, 1e-12)
+	near(lon, lonA, 1e-12)
+	lat, lon = IntermediatePoint(latA, lonA, latB, lonB, 1)
+	near(lat, latB, 1e-12)
+	near(lon, lonB, 1e-12)
+	lat, lon = IntermediatePoint(latA, lonA, latA, lonA, 0.5)
+	near(lat, latA, 0)
+	near(lon, lonA, 0)
+
+	// cross-track and along-track
+	value := CrossTrackDistance(53.2611, -0.7972, 53.3206, -1.7297, 53.1887, 0.1334)
+	near(value, -307.5, 0.1)
+	value = AlongTrackDistance(53.2611, -0.7972, 53.3206, -1.7297, 53.1887, 0.1334)
+	near(value, 62331, 1)
+	value = AlongTrackDistance(0, -1, 0, 0, 0, 1)
+	near(value, -DistanceTo(0, -1, 0, 0), 1e-6)
+
+	// intersection
+	lat, lon, ok := Intersection(51.8853, 0.2545, 108.547, 49.0034, 2.5735, 32.435)
+	if !ok {
+		t.Fatal("expected true")
+	}
+	near(lat, 50.9078, 1e-4)
+	near(lon, 4.5084, 1e-4)
+	if _, _, ok := Intersection(0, 0, 90, 0, 10, 90); ok {
+		t.Fatal("expected false")
+	}
+	if _, _, ok := Intersection(0, 0, 0, 0, 10, 180); ok {
+		t.Fatal("expected false")
+	}
+
+	// random paths
+	var crossings int
+	for i := 0; i < 1000; i++ {
+		latA := rand.Float64()*160 - 80
+		lonA := rand.Float64()*360 - 180
+		latB := rand.Float64()*160 - 80
+		lonB := rand.Float64()*360 - 180
+		dist := DistanceTo(latA, lonA, latB, lonB)
+		f := rand.Float64()
+		lat, lon := IntermediatePoint(latA, lonA, latB, lonB, f)
+		near(DistanceTo(latA, lonA, lat, lon), dist*f, 1e-3)
+		near(DistanceTo(lat, lon, latB, lonB), dist*(1-f), 1e-3)
+		near(CrossTrackDistance(lat, lon, latA, lonA, latB, lonB), 0, 1e-3)
+		near(AlongTrackDistance(lat, lon, latA, lonA, latB, lonB), dist*f, 1e-3)
+		// two paths toward the same point cross at that point
+		latC := rand.Float64()*160 - 80
+		lonC := rand.Float64()*360 - 180
+		lat2, lon2, ok := Intersection(latA, lonA,
+			BearingTo(latA, lonA, latC, lonC), latB, lonB,
+			BearingTo(latB, lonB, latC, lonC))
+		crossing := BearingTo(latC, lonC, latA, lonA) -
+			BearingTo(latC, lonC, latB, lonB)
+		if math.Abs(math.Sin(crossing*radians)) > 0.01 {
+			// paths that are not nearly parallel
+			if !ok {
+				t.Fatalf("expected a crossing of %v,%v and %v,%v at %v,%v",
+					latA, lonA, latB, lonB, latC, lonC)
+			}
+			near(DistanceTo(latC, lonC, lat2, lon2), 0, 1e-3)
+			crossings++
+		}
+	}
+	if crossings < 900 {
+		t.Fatalf("expected at least 900 crossings, got %d", crossings)
+	}
+}
+
+func TestRhumbLine(t *testing.T) {
+	near := func(value, expect, epsilon float64) {
+		t.Helper()
+		if math.Abs(value-expect) > epsilon {
+			t.Fatalf("expected '%v', got '%v'", expect, value)
+		}
+	}
+	// Dover to Calais
+	latA, lonA := 51.127, 1.338
+	latB, lonB := 50.964, 1.853
+	dist := RhumbDistanceTo(latA, lonA, latB, lonB)
+	near(dist, 40307.7, 0.1)
+	bearing := RhumbBearingTo(latA, lonA, latB, lonB)
+	near(bearing, 116.72, 0.01)
+	lat, lon := RhumbDestinationPoint(latA, lonA, dist, bearing)
+	near(lat, latB, 1e-9)
+	near(lon, lonB, 1e-9)
+
+	// along the equator and across the antimeridian
+	near(RhumbDistanceTo(0, 179, 0, -179), DistanceTo(0, 179, 0, -179), 1e-6)
+	near(RhumbBearingTo(0, 179, 0, -179), 90, 1e-9)
+	lat, lon = RhumbDestinationPoint(10, 179, RhumbDistanceTo(10, 179, 10, -179), 90)
+	near(lat, 10, 1e-9)
+	near(lon, -179, 1e-9)
+
+	// random rhumb lines
+	for i := 0; i < 1000; i++ {
+		latA := rand.Float64()*160 - 80
+		lonA := rand.Float64()*360 - 180
+		latB := rand.Float64()*160 - 80
+		lonB := rand.Float64()*360 - 180
+		dist := RhumbDistanceTo(latA, lonA, latB, lonB)
+		if dist < DistanceTo(latA, lonA, latB, lonB)-1e-6 {
+			t.Fatal("rhumb line is shorter than great circle")
+		}
+		bearing := RhumbBearingTo(latA, lonA, latB, lonB)
+		lat, lon := RhumbDestinationPoint(latA, lonA, dist, bearing)
+		near(lat, latB, 1e-6)
+		near(lon, lonB, 1e-6)
+	}
+}
+
+func TestWGS84(t *testing.T) {
+	dms := func(d, m, s float64) float64 { return d + m/60 + s/3600 }
+	// Flinders Peak to Buninyong, from Vincenty's paper
+	latA, lonA := -dms(37, 57, 3.72030), dms(144, 25, 29.52440)
+	latB, lonB := -dms(37, 39, 10.15610), dms(143, 55, 35.38390)
+	meters, initial, final := InverseWGS84(latA, lonA, latB, lonB)
+	assert(t, math.Abs(meters-54972.271) < 0.001)
+	assert(t, math.Abs(initial-dms(306, 52, 5.37)) < 1e-5)
+	assert(t, math.Abs(final-dms(307, 10, 25.07)) < 1e-5)
+	lat, lon, final := DirectWGS84(latA, lonA, 54972.271, dms(306, 52, 5.37))
+	assert(t, math.Abs(lat-latB) < 1e-7)
+	assert(t, math.Abs(lon-lonB) < 1e-7)
+	assert(t, math.Abs(final-dms(307, 10, 25.07)) < 1e-5)
+
+	// one degree of latitude at the equator and at the pole
+	meters, _, _ = InverseWGS84(0, 0, 1, 0)
+	assert(t, math.Abs(meters-110574.389) < 0.001)
+	meters, _, _ = InverseWGS84(89, 0, 90, 0)
+	assert(t, math.Abs(meters-111693.865) < 0.01)
+
+	// coincident and nearly antipodal points
+	meters, _, _ = InverseWGS84(10, 20, 10, 20)
+	assert(t, meters == 0)
+	meters, _, _ = InverseWGS84(0, 0, 0.5, 179.7)
+	assert(t, meters > 19e6 && meters < 20.1e6)
+
+	// round trips
+	for i := 0; i < 1000; i++ {
+		lat := rand.Float64()*160 - 80
+		lon := rand.Float64()*360 - 180
+		meters := rand.Float64() * 1e6
+		bearing := rand.Float64() * 360
+		lat2, lon2, _ := DirectWGS84(lat, lon, meters, bearing)
+		meters2, bearing2, _ := InverseWGS84(lat, lon, lat2, lon2)
+		assert(t, math.Abs(meters-meters2) < 1e-3)
+		// the bearing of a short distance is less precise, by about the
+		// position error over the distance.
+		assert(t, math.Abs(bearingDiff(bearing, bearing2)) < 1e-6+1e-2/meters)
+	}
+}
+
+func bearingDiff(a, b float64) float64 {
+	return math.Mod(a-b+540, 360) - 180
+}
+
+func TestModel(t *testing.T) {
+	assert(t, Spherical.String() == "Spherical")
+	assert(t, Ellipsoidal.String() == "Ellipsoidal")
+	assert(t, Spherical.DistanceTo(0, 0, 1, 0) == DistanceTo(0, 0, 1, 0))
+	meters, _, _ := InverseWGS84(0, 0, 1, 0)
+	assert(t, Ellipsoidal.DistanceTo(0, 0, 1, 0) == meters)
+	lat, lon := Ellipsoidal.DestinationPoint(0, 0, meters, 0)
+	assert(t, math.Abs(lat-1) < 1e-9 && math.Abs(lon) < 1e-9)
+	assert(t, math.Abs(Ellipsoidal.BearingTo(0, 0, 0, 1)-90) < 1e-9)
+
+	// segment distances
+	assert(t, math.Abs(Ellipsoidal.DistanceToSegment(0.5, 0, 0, -1, 0, 1)-
+		Ellipsoidal.DistanceTo(0.5, 0, 0, 0)) < 1e-6)
+	assert(t, Spherical.DistanceToSegment(0.5, 0, 0, -1, 0, 1) ==
+		DistanceToSegment(0.5, 0, 0, -1, 0, 1))
+
+	// the northern hemisphere covers half of the earth
+	lats := []float64{0, 0, 90, 90, 0}
+	lons := []float64{-180, 180, 180, -180, -180}
+	half := Spherical.RingArea(lats, lons)
+	assert(t, math.Abs(half-2*math.Pi*earthRadius*earthRadius) < 1)
+	half = Ellipsoidal.RingArea(lats, lons)
+	assert(t, math.Abs(half-255032810.9e6) < 1e6)
+	assert(t, math.Abs(Spherical.CapArea(math.Pi*earthRadius/2)-
+		2*math.Pi*earthRadius*earthRadius) < 1)
+	assert(t, Spherical.RingArea(lats[:2], lons[:2]) == 0)
+}
+
+func TestProjection(t *testing.T) {
+	near := func(value, expect, epsilon float64) {
+		t.Helper()
+		if math.Abs(value-expect) > epsilon {
+			t.Fatalf("expected '%v', got '%v'", expect, value)
+		}
+	}
+	// EPSG:4326
+	x, y := LonLat{}.Forward(33, -112)
+	assert(t, x == -112 && y == 33)
+	lat, lon := LonLat{}.Inverse(-112, 33)
+	assert(t, lat == 33 && lon == -112)
+
+	// EPSG:3857
+	x, y = WebMercator{}.Forward(MaxMercatorLatitude, 180)
+	near(x, 20037508.342789244, 1e-6)
+	near(y, 20037508.342789244, 1e-6)
+	x, y = WebMercator{}.Forward(90, -180)
+	near(x, -20037508.342789244, 1e-6)
+	near(y, 20037508.342789244, 1e-6)
+	lat, lon = WebMercator{}.Inverse(0, 0)
+	assert(t, lat == 0 && lon == 0)
+
+	// UTM
+	// 48°51′29.5″N, 2°17′40.2″E
+	lat, lon = 48+51.0/60+29.5/3600, 2+17.0/60+40.2/3600
+	utm := UTMZone(lat, lon)
+	assert(t, utm == UTM{Zone: 31} && utm.EPSG() == 32631)
+	x, y = utm.Forward(lat, lon)
+	near(x, 448252, 1)
+	near(y, 5411933, 1)
+	x, y = utm.Forward(0, 3)
+	near(x, 500000, 1e-6)
+	near(y, 0, 1e-6)
+	utm = UTMZone(-33.8688, 151.2093)
+	assert(t, utm == UTM{Zone: 56, South: true} && utm.EPSG() == 32756)
+	x, y = utm.Forward(-33.8688, 151.2093)
+	near(x, 334369, 1)
+	near(y, 6250948, 1)
+	assert(t, UTMZone(60, 5).Zone == 32)
+	assert(t, UTMZone(78, 15).Zone == 33)
+	assert(t, UTMZone(0, 180).Zone == 60)
+	assert(t, UTMZone(0, -180).Zone == 1)
+
+	// EPSG codes
+	for _, code := range []int{4326, 3857, 32601, 32660, 32701, 32760} {
+		proj, ok := EPSG(code)
+		assert(t, ok)
+		if utm, ok := proj.(UTM); ok {
+			assert(t, utm.EPSG() == code)
+		}
+	}
+	_, ok := EPSG(2154)
+	assert(t, !ok)
+
+	// round trips
+	for i := 0; i < 1000; i++ {
+		lat := rand.Float64()*160 - 80
+		lon := rand.Float64()*360 - 180
+		for _, proj := range []Projection{
+			LonLat{}, WebMercator{}, UTMZone(lat, lon),
+		} {
+			x, y := proj.Forward(lat, lon)
+			lat2, lon2 := proj.Inverse(x, y)
+			near(lat2, lat, 1e-9)
+			near(lon2, lon, 1e-9)
+			// inverted projections swap the directions
+			inv := Invert(proj)
+			lat3, lon3 := inv.Forward(y, x)
+			assert(t, lat3 == lon2 && lon3 == lat2)
+			x2, y2 := inv.Inverse(lon, lat)
+			assert(t, x2 == y && y2 == x)
+			assert(t, Invert(inv) == proj)
+		}
+	}
+}
+
+func assert(t *testing.T, cond bool) {
+	t.Helper()
+	if !cond {
+		t.Fatal("assertion failed")
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geo
+
+import (
+	"math"
+)
+
+// Model is the shape of the earth used for measuring distances and areas.
+type Model byte
+
+// Model types
+const (
+	// Spherical treats the earth as a sphere with a radius of 6371 km. This
+	// is the fastest model, and is accurate to about 0.5%.
+	Spherical Model = iota
+	// Ellipsoidal uses the WGS84 ellipsoid, which is accurate to within a
+	// millimeter but is slower to compute.
+	Ellipsoidal
+)
+
+// String returns the name of the model
+func (model Model) String() string {
+	switch model {
+	case Spherical:
+		return "Spherical"
+	case Ellipsoidal:
+		return "Ellipsoidal"
+	}
+	return "Unknown"
+}
+
+// DistanceTo returns the distance in meters between two points.
+func (model Model) DistanceTo(latA, lonA, latB, lonB float64) (meters float64) {
+	if model == Ellipsoidal {
+		meters, _, _ = InverseWGS84(latA, lonA, latB, lonB)
+		return meters
+	}
+	return DistanceTo(latA, lonA, latB, lonB)
+}
+
+// DestinationPoint return the destination from a point based on a
+// distance and bearing.
+func (model Model) DestinationPoint(lat, lon, meters, bearingDegrees float64) (
+	destLat, destLon float64,
+) {
+	if model == Ellipsoidal {
+		destLat, destLon, _ = DirectWGS84(lat, lon, meters, bearingDegrees)
+		return destLat, destLon
+	}
+	return DestinationPoint(lat, lon, meters, bearingDegrees)
+}
+
+// BearingTo returns the (initial) bearing from point 'A' to point 'B'.
+func (model Model) BearingTo(latA, lonA, latB, lonB float64) float64 {
+	if model == Ellipsoidal {
+		_, bearing, _ := InverseWGS84(latA, lonA, latB, lonB)
+		return bearing
+	}
+	return BearingTo(latA, lonA, latB, lonB)
+}
+
+// DistanceToSegment returns the distance in meters from a point to the
+// closest point of the segment from 'A' to 'B'.
+func (model Model) DistanceToSegment(lat, lon, latA, lonA, latB, lonB float64,
+) (meters float64) {
+	if model == Ellipsoidal {
+		_, meters = SegmentMin(func(t float64) float64 {
+			meters, _, _ := InverseWGS84(lat, lon,
+				latA+(latB-latA)*t, lonA+(lonB-lonA)*t)
+			return meters
+		})
+		return meters
+	}
+	return DistanceToSegment(lat, lon, latA, lonA, latB, lonB)
+}
+
+// RingArea returns the area in square meters enclosed by a ring of points.
+// The ring may be open or closed, and may wind in either direction.
+func (model Model) RingArea(lats, lons []float64) (sqMeters float64) {
+	n := len(lats)
+	if n > 1 && lats[0] == lats[n-1] && lons[0] == lons[n-1] {
+		n--
+	}
+	if n < 3 {
+		return 0
+	}
+	radius := earthRadius
+	lat := func(i int) float64 { return lats[i] }
+	if model == Ellipsoidal {
+		// Areas on the ellipsoid are preserved when the latitudes are mapped
+		// onto the authalic sphere.
+		radius = authalicRadius
+		lat = func(i int) float64 { return authalicLatitude(lats[i]) }
+	}
+	// see https://trs.jpl.nasa.gov/handle/2014/41271
+	var total float64
+	for i := 0; i < n; i++ {
+		lower := (i + n - 1) % n
+		upper := (i + 1) % n
+		total += (lons[upper] - lons[lower]) * radians *
+			math.Sin(lat(i)*radians)
+	}
+	return math.Abs(total * radius * radius / 2)
+}
+
+// CapArea returns the area in square meters of a circle with a radius in
+// meters.
+func (model Model) CapArea(meters float64) (sqMeters float64) {
+	radius := earthRadius
+	if model == Ellipsoidal {
+		radius = authalicRadius
+	}
+	meters = math.Min(math.Max(meters, 0), math.Pi*radius)
+	return 2 * math.Pi * radius * radius * (1 - math.Cos(meters/radius))
+}
@@ -0,0 +1,201 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geo
+
+import (
+	"math"
+)
+
+// see https://www.movable-type.co.uk/scripts/latlong.html
+
+// normalizeLon normalises a longitude in radians to -180..+180°
+func normalizeLon(λ float64) float64 {
+	return math.Mod(λ+3*math.Pi, 2*math.Pi) - math.Pi
+}
+
+// IntermediatePoint returns the point at a fraction of the way along the
+// great circle path from point 'A' to point 'B'. A fraction of 0 is point 'A'
+// and a fraction of 1 is point 'B'.
+func IntermediatePoint(latA, lonA, latB, lonB, fraction float64) (
+	lat, lon float64,
+) {
+	φ1, λ1 := latA*radians, lonA*radians
+	φ2, λ2 := latB*radians, lonB*radians
+	δ := DistanceTo(latA, lonA, latB, lonB) / earthRadius
+	if δ == 0 {
+		return latA, lonA
+	}
+	a := math.Sin((1-fraction)*δ) / math.Sin(δ)
+	b := math.Sin(fraction*δ) / math.Sin(δ)
+	x := a*math.Cos(φ1)*math.Cos(λ1) + b*math.Cos(φ2)*math.Cos(λ2)
+	y := a*math.Cos(φ1)*math.Sin(λ1) + b*math.Cos(φ2)*math.Sin(λ2)
+	z := a*math.Sin(φ1) + b*math.Sin(φ2)
+	φ3 := math.Atan2(z, math.Sqrt(x*x+y*y))
+	λ3 := math.Atan2(y, x)
+	return φ3 * degrees, normalizeLon(λ3) * degrees
+}
+
+// Midpoint returns the point half way along the great circle path from
+// point 'A' to point 'B'.
+func Midpoint(latA, lonA, latB, lonB float64) (lat, lon float64) {
+	φ1, λ1 := latA*radians, lonA*radians
+	φ2 := latB * radians
+	Δλ := (lonB - lonA) * radians
+	bx := math.Cos(φ2) * math.Cos(Δλ)
+	by := math.Cos(φ2) * math.Sin(Δλ)
+	x := math.Cos(φ1) + bx
+	φ3 := math.Atan2(math.Sin(φ1)+math.Sin(φ2), math.Sqrt(x*x+by*by))
+	λ3 := λ1 + math.Atan2(by, x)
+	return φ3 * degrees, normalizeLon(λ3) * degrees
+}
+
+// CrossTrackDistance returns the distance in meters from a point to the
+// great circle path that runs through point 'A' and point 'B'. The distance
+// is negative when the point is to the left of the path.
+func CrossTrackDistance(lat, lon, latA, lonA, latB, lonB float64) (
+	meters float64,
+) {
+	δ13 := DistanceTo(latA, lonA, lat, lon) / earthRadius
+	θ13 := BearingTo(latA, lonA, lat, lon) * radians
+	θ12 := BearingTo(latA, lonA, latB, lonB) * radians
+	δxt := math.Asin(math.Sin(δ13) * math.Sin(θ13-θ12))
+	return δxt * earthRadius
+}
+
+// AlongTrackDistance returns the distance in meters from point 'A' to the
+// point on the great circle path through point 'A' and point 'B' that is
+// closest to a point. The distance is negative when the closest point is
+// behind point 'A'.
+func AlongTrackDistance(lat, lon, latA, lonA, latB, lonB float64) (
+	meters float64,
+) {
+	δ13 := DistanceTo(latA, lonA, lat, lon) / earthRadius
+	θ13 := BearingTo(latA, lonA, lat, lon) * radians
+	θ12 := BearingTo(latA, lonA, latB, lonB) * radians
+	δxt := math.Asin(math.Sin(δ13) * math.Sin(θ13-θ12))
+	cosδat := math.Cos(δ13) / math.Cos(δxt)
+	δat := math.Acos(math.Max(-1, math.Min(1, cosδat)))
+	if math.Cos(θ12-θ13) < 0 {
+		δat = -δat
+	}
+	return δat * earthRadius
+}
+
+// Intersection returns the point where two great circle paths cross. Each
+// path is defined by a starting point and an initial bearing in degrees.
+// Returns false when the paths are the same, or when the nearest crossing
+// is behind both starting points.
+func Intersection(latA, lonA, bearingA, latB, lonB, bearingB float64) (
+	lat, lon float64, ok bool,
+) {
+	φ1, λ1 := latA*radians, lonA*radians
+	φ2, λ2 := latB*radians, lonB*radians
+	θ13, θ23 := bearingA*radians, bearingB*radians
+	Δλ := λ2 - λ1
+
+	// Every angle is found with atan2, because the acos and asin of values
+	// near ±1 lose the precision of paths that are nearly north-south or
+	// points that are nearly antipodal.
+
+	// angular distance between the starting points
+	cx := math.Cos(φ1)*math.Sin(φ2) - math.Sin(φ1)*math.Cos(φ2)*math.Cos(Δλ)
+	cy := math.Cos(φ2) * math.Sin(Δλ)
+	δ12 := math.Atan2(math.Sqrt(cx*cx+cy*cy),
+		math.Sin(φ1)*math.Sin(φ2)+math.Cos(φ1)*math.Cos(φ2)*math.Cos(Δλ))
+	if math.Abs(δ12) < 1e-12 {
+		// coincident starting points
+		return latA, lonA, true
+	}
+
+	// initial bearings from each starting point to the other
+	θ12 := math.Atan2(math.Sin(Δλ)*math.Cos(φ2), cx)
+	θ21 := math.Atan2(-math.Sin(Δλ)*math.Cos(φ1),
+		math.Cos(φ2)*math.Sin(φ1)-math.Sin(φ2)*math.Cos(φ1)*math.Cos(Δλ))
+
+	α1 := θ13 - θ12 // angle 2-1-3
+	α2 := θ21 - θ23 // angle 1-2-3
+	if math.Abs(math.Sin(α1)) < 1e-12 && math.Abs(math.Sin(α2)) < 1e-12 {
+		// infinite intersections
+		return 0, 0, false
+	}
+	if math.Sin(α1)*math.Sin(α2) < 0 {
+		// ambiguous intersection
+		return 0, 0, false
+	}
+	cosα3 := -math.Cos(α1)*math.Cos(α2) +
+		math.Sin(α1)*math.Sin(α2)*math.Cos(δ12)
+	δ13 := math.Atan2(math.Sin(δ12)*math.Sin(α1)*math.Sin(α2),
+		math.Cos(α2)+math.Cos(α1)*cosα3)
+
+	// move along the first path as a vector from the center of the earth
+	north := [3]float64{-math.Sin(φ1) * math.Cos(λ1),
+		-math.Sin(φ1) * math.Sin(λ1), math.Cos(φ1)}
+	east := [3]float64{-math.Sin(λ1), math.Cos(λ1), 0}
+	start := [3]float64{math.Cos(φ1) * math.Cos(λ1),
+		math.Cos(φ1) * math.Sin(λ1), math.Sin(φ1)}
+	var p [3]float64
+	for i := range p {
+		dir := north[i]*math.Cos(θ13) + east[i]*math.Sin(θ13)
+		p[i] = start[i]*math.Cos(δ13) + dir*math.Sin(δ13)
+	}
+	φ3 := math.Atan2(p[2], math.Sqrt(p[0]*p[0]+p[1]*p[1]))
+	λ3 := math.Atan2(p[1], p[0])
+	return φ3 * degrees, normalizeLon(λ3) * degrees, true
+}
+
+// rhumbStretch returns the ratio of the latitude difference to the
+// difference of the mercator projected latitudes.
+func rhumbStretch(φ1, φ2 float64) float64 {
+	Δψ := math.Log(math.Tan(math.Pi/4+φ2/2) / math.Tan(math.Pi/4+φ1/2))
+	if math.Abs(Δψ) > 1e-12 {
+		return (φ2 - φ1) / Δψ
+	}
+	// east-west line
+	return math.Cos(φ1)
+}
+
+// RhumbDistanceTo returns the distance in meters along a rhumb line, which is
+// a path of constant bearing, from point 'A' to point 'B'.
+func RhumbDistanceTo(latA, lonA, latB, lonB float64) (meters float64) {
+	φ1, φ2 := latA*radians, latB*radians
+	Δφ := φ2 - φ1
+	Δλ := normalizeLon((lonB - lonA) * radians) // take the shorter way
+	q := rhumbStretch(φ1, φ2)
+	return math.Sqrt(Δφ*Δφ+q*q*Δλ*Δλ) * earthRadius
+}
+
+// RhumbBearingTo returns the constant bearing of the rhumb line from point
+// 'A' to point 'B'.
+func RhumbBearingTo(latA, lonA, latB, lonB float64) float64 {
+	φ1, φ2 := latA*radians, latB*radians
+	Δλ := normalizeLon((lonB - lonA) * radians) // take the shorter way
+	Δψ := math.Log(math.Tan(math.Pi/4+φ2/2) / math.Tan(math.Pi/4+φ1/2))
+	θ := math.Atan2(Δλ, Δψ)
+	return math.Mod(θ*degrees+360, 360)
+}
+
+// RhumbDestinationPoint returns the destination from a point after
+// travelling a distance along a rhumb line with a constant bearing.
+func RhumbDestinationPoint(lat, lon, meters, bearingDegrees float64) (
+	destLat, destLon float64,
+) {
+	φ1, λ1 := lat*radians, lon*radians
+	θ := bearingDegrees * radians
+	δ := meters / earthRadius // angular distance in radians
+	Δφ := δ * math.Cos(θ)
+	φ2 := φ1 + Δφ
+	if math.Abs(φ2) > math.Pi/2 {
+		// passed a pole, so go back the other way
+		if φ2 > 0 {
+			φ2 = math.Pi - φ2
+		} else {
+			φ2 = -math.Pi - φ2
+		}
+	}
+	q := rhumbStretch(φ1, φ2)
+	Δλ := δ * math.Sin(θ) / q
+	λ2 := λ1 + Δλ
+	return φ2 * degrees, normalizeLon(λ2) * degrees
+}
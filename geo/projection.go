@@ -0,0 +1,241 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geo
+
+import (
+	"math"
+)
+
+// Projection converts lat/lon coordinates to and from the x/y coordinates of
+// a projected coordinate reference system.
+type Projection interface {
+	// Forward converts a lat/lon coordinate into a projected coordinate
+	Forward(lat, lon float64) (x, y float64)
+	// Inverse converts a projected coordinate into a lat/lon coordinate
+	Inverse(x, y float64) (lat, lon float64)
+}
+
+// EPSG returns the projection for an EPSG code. The supported codes are 4326
+// (WGS84 lon/lat), 3857 (Web Mercator), 32601-32660 (UTM north zones), and
+// 32701-32760 (UTM south zones).
+func EPSG(code int) (proj Projection, ok bool) {
+	switch {
+	case code == 4326:
+		return LonLat{}, true
+	case code == 3857:
+		return WebMercator{}, true
+	case code >= 32601 && code <= 32660:
+		return UTM{Zone: code - 32600}, true
+	case code >= 32701 && code <= 32760:
+		return UTM{Zone: code - 32700, South: true}, true
+	}
+	return nil, false
+}
+
+// Invert returns a projection that swaps the forward and inverse
+// conversions of another projection.
+func Invert(proj Projection) Projection {
+	if inv, ok := proj.(inverted); ok {
+		return inv.proj
+	}
+	return inverted{proj}
+}
+
+type inverted struct{ proj Projection }
+
+func (inv inverted) Forward(lat, lon float64) (x, y float64) {
+	// lat/lon and y/x switch places when projected by the other projection
+	y, x = inv.proj.Inverse(lon, lat)
+	return x, y
+}
+
+func (inv inverted) Inverse(x, y float64) (lat, lon float64) {
+	lon, lat = inv.proj.Forward(y, x)
+	return lat, lon
+}
+
+// LonLat is the WGS84 geographic coordinate system (EPSG:4326), where x is
+// the longitude and y is the latitude.
+type LonLat struct{}
+
+// Forward ...
+func (LonLat) Forward(lat, lon float64) (x, y float64) {
+	return lon, lat
+}
+
+// Inverse ...
+func (LonLat) Inverse(x, y float64) (lat, lon float64) {
+	return y, x
+}
+
+// WebMercator is the spherical Mercator projection used by web maps
+// (EPSG:3857), with coordinates in meters.
+type WebMercator struct{}
+
+// MaxMercatorLatitude is the latitude where Web Mercator becomes square
+const MaxMercatorLatitude = 85.05112877980659
+
+// Forward ...
+func (WebMercator) Forward(lat, lon float64) (x, y float64) {
+	lat = math.Max(-MaxMercatorLatitude, math.Min(MaxMercatorLatitude, lat))
+	x = wgs84A * lon * radians
+	y = wgs84A * math.Log(math.Tan(math.Pi/4+lat*radians/2))
+	return x, y
+}
+
+// Inverse ...
+func (WebMercator) Inverse(x, y float64) (lat, lon float64) {
+	lon = x / wgs84A * degrees
+	lat = (2*math.Atan(math.Exp(y/wgs84A)) - math.Pi/2) * degrees
+	return lat, lon
+}
+
+// UTM is a Universal Transverse Mercator zone on the WGS84 ellipsoid, with
+// coordinates in meters. Zones are numbered 1 to 60.
+type UTM struct {
+	Zone  int
+	South bool
+}
+
+// UTMZone returns the UTM zone for a lat/lon coordinate, including the
+// exceptions for Norway and Svalbard.
+func UTMZone(lat, lon float64) UTM {
+	zone := int(math.Floor((lon+180)/6)) + 1
+	if zone < 1 {
+		zone = 1
+	} else if zone > 60 {
+		zone = 60
+	}
+	if lat >= 56 && lat < 64 && lon >= 3 && lon < 12 {
+		// Norway
+		zone = 32
+	}
+	if lat >= 72 && lon >= 0 && lon < 42 {
+		// Svalbard
+		switch {
+		case lon < 9:
+			zone = 31
+		case lon < 21:
+			zone = 33
+		case lon < 33:
+			zone = 35
+		default:
+			zone = 37
+		}
+	}
+	return UTM{Zone: zone, South: lat < 0}
+}
+
+// EPSG returns the EPSG code of the zone
+func (utm UTM) EPSG() int {
+	if utm.South {
+		return 32700 + utm.Zone
+	}
+	return 32600 + utm.Zone
+}
+
+const (
+	utmScale         = 0.9996
+	utmFalseEasting  = 500e3
+	utmFalseNorthing = 10000e3
+)
+
+// utmSeries are the Krüger series coefficients for the transverse Mercator
+// projection to sixth order in the third flattening.
+// see https://www.movable-type.co.uk/scripts/latlong-utm-mgrs.html
+var utmA, utmAlpha, utmBeta = func() (float64, [7]float64, [7]float64) {
+	n := wgs84F / (2 - wgs84F)
+	n2, n3, n4, n5, n6 := n*n, n*n*n, n*n*n*n, n*n*n*n*n, n*n*n*n*n*n
+	A := wgs84A / (1 + n) * (1 + n2/4 + n4/64 + n6/256)
+	alpha := [7]float64{0,
+		1.0/2*n - 2.0/3*n2 + 5.0/16*n3 + 41.0/180*n4 - 127.0/288*n5 +
+			7891.0/37800*n6,
+		13.0/48*n2 - 3.0/5*n3 + 557.0/1440*n4 + 281.0/630*n5 -
+			1983433.0/1935360*n6,
+		61.0/240*n3 - 103.0/140*n4 + 15061.0/26880*n5 +
+			167603.0/181440*n6,
+		49561.0/161280*n4 - 179.0/168*n5 + 6601661.0/7257600*n6,
+		34729.0/80640*n5 - 3418889.0/1995840*n6,
+		212378941.0 / 319334400 * n6,
+	}
+	beta := [7]float64{0,
+		1.0/2*n - 2.0/3*n2 + 37.0/96*n3 - 1.0/360*n4 - 81.0/512*n5 +
+			96199.0/604800*n6,
+		1.0/48*n2 + 1.0/15*n3 - 437.0/1440*n4 + 46.0/105*n5 -
+			1118711.0/3870720*n6,
+		17.0/480*n3 - 37.0/840*n4 - 209.0/4480*n5 + 5569.0/90720*n6,
+		4397.0/161280*n4 - 11.0/504*n5 - 830251.0/7257600*n6,
+		4583.0/161280*n5 - 108847.0/3991680*n6,
+		20648693.0 / 638668800 * n6,
+	}
+	return A, alpha, beta
+}()
+
+// centralMeridian returns the central meridian of the zone in radians
+func (utm UTM) centralMeridian() float64 {
+	return float64((utm.Zone-1)*6-180+3) * radians
+}
+
+// Forward ...
+func (utm UTM) Forward(lat, lon float64) (x, y float64) {
+	e := math.Sqrt(wgs84F * (2 - wgs84F))
+	φ := lat * radians
+	λ := lon*radians - utm.centralMeridian()
+	cosλ, sinλ := math.Cos(λ), math.Sin(λ)
+
+	// conformal latitude
+	τ := math.Tan(φ)
+	σ := math.Sinh(e * math.Atanh(e*τ/math.Sqrt(1+τ*τ)))
+	τʹ := τ*math.Sqrt(1+σ*σ) - σ*math.Sqrt(1+τ*τ)
+
+	ξʹ := math.Atan2(τʹ, cosλ)
+	ηʹ := math.Asinh(sinλ / math.Sqrt(τʹ*τʹ+cosλ*cosλ))
+	ξ, η := ξʹ, ηʹ
+	for j := 1; j <= 6; j++ {
+		ξ += utmAlpha[j] * math.Sin(2*float64(j)*ξʹ) * math.Cosh(2*float64(j)*ηʹ)
+		η += utmAlpha[j] * math.Cos(2*float64(j)*ξʹ) * math.Sinh(2*float64(j)*ηʹ)
+	}
+	x = utmScale*utmA*η + utmFalseEasting
+	y = utmScale * utmA * ξ
+	if utm.South {
+		y += utmFalseNorthing
+	}
+	return x, y
+}
+
+// Inverse ...
+func (utm UTM) Inverse(x, y float64) (lat, lon float64) {
+	e := math.Sqrt(wgs84F * (2 - wgs84F))
+	x -= utmFalseEasting
+	if utm.South {
+		y -= utmFalseNorthing
+	}
+	η := x / (utmScale * utmA)
+	ξ := y / (utmScale * utmA)
+	ξʹ, ηʹ := ξ, η
+	for j := 1; j <= 6; j++ {
+		ξʹ -= utmBeta[j] * math.Sin(2*float64(j)*ξ) * math.Cosh(2*float64(j)*η)
+		ηʹ -= utmBeta[j] * math.Cos(2*float64(j)*ξ) * math.Sinh(2*float64(j)*η)
+	}
+	sinhηʹ := math.Sinh(ηʹ)
+	sinξʹ, cosξʹ := math.Sin(ξʹ), math.Cos(ξʹ)
+	τʹ := sinξʹ / math.Sqrt(sinhηʹ*sinhηʹ+cosξʹ*cosξʹ)
+
+	// iterate to the geodetic latitude from the conformal latitude
+	τi := τʹ
+	for i := 0; i < 100; i++ {
+		σi := math.Sinh(e * math.Atanh(e*τi/math.Sqrt(1+τi*τi)))
+		τiʹ := τi*math.Sqrt(1+σi*σi) - σi*math.Sqrt(1+τi*τi)
+		δτi := (τʹ - τiʹ) / math.Sqrt(1+τiʹ*τiʹ) *
+			(1 + (1-e*e)*τi*τi) / ((1 - e*e) * math.Sqrt(1+τi*τi))
+		τi += δτi
+		if math.Abs(δτi) < 1e-12 {
+			break
+		}
+	}
+	φ := math.Atan(τi)
+	λ := math.Atan2(sinhηʹ, cosξʹ) + utm.centralMeridian()
+	return φ * degrees, normalizeLon(λ) * degrees
+}
@@ -0,0 +1,102 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geo
+
+import "errors"
+
+// errUnitInvalid is returned when an invalid unit is written or read as text
+var errUnitInvalid = errors.New("invalid unit")
+
+// Unit is a unit of distance
+type Unit byte
+
+// Unit types
+const (
+	Meters Unit = iota
+	Kilometers
+	Miles
+	Feet
+	Yards
+	NauticalMiles
+)
+
+// unitInfo is the abbreviation and the number of meters for each unit
+var unitInfo = [...]struct {
+	abbr   string
+	meters float64
+}{
+	Meters:        {"m", 1},
+	Kilometers:    {"km", 1000},
+	Miles:         {"mi", 1609.344},
+	Feet:          {"ft", 0.3048},
+	Yards:         {"yd", 0.9144},
+	NauticalMiles: {"nmi", 1852},
+}
+
+// ParseUnit returns the unit for an abbreviation such as "m", "km", "mi",
+// "ft", "yd", or "nmi".
+func ParseUnit(abbr string) (unit Unit, ok bool) {
+	for i, info := range unitInfo {
+		if info.abbr == abbr {
+			return Unit(i), true
+		}
+	}
+	return 0, false
+}
+
+// String returns the abbreviation of the unit, or an empty string for an
+// invalid unit.
+func (unit Unit) String() string {
+	if int(unit) < len(unitInfo) {
+		return unitInfo[unit].abbr
+	}
+	return ""
+}
+
+// MarshalText returns the abbreviation of the unit, or an error for an
+// invalid unit.
+func (unit Unit) MarshalText() ([]byte, error) {
+	if int(unit) >= len(unitInfo) {
+		return nil, errUnitInvalid
+	}
+	return []byte(unitInfo[unit].abbr), nil
+}
+
+// UnmarshalText sets the unit of an abbreviation, or returns an error when
+// it's not a unit.
+func (unit *Unit) UnmarshalText(text []byte) error {
+	u, ok := ParseUnit(string(text))
+	if !ok {
+		return errUnitInvalid
+	}
+	*unit = u
+	return nil
+}
+
+// Meters returns the number of meters in one unit
+func (unit Unit) Meters() float64 {
+	if int(unit) < len(unitInfo) {
+		return unitInfo[unit].meters
+	}
+	return 0
+}
+
+// ToMeters converts a distance in the unit to meters
+func (unit Unit) ToMeters(distance float64) (meters float64) {
+	return distance * unit.Meters()
+}
+
+// FromMeters converts a distance in meters to the unit
+func (unit Unit) FromMeters(meters float64) (distance float64) {
+	return meters / unit.Meters()
+}
+
+// ConvertDistance converts a distance from one unit to another.
+func ConvertDistance(distance float64, from, to Unit) float64 {
+	if from == to {
+		return distance
+	}
+	return to.FromMeters(from.ToMeters(distance))
+}
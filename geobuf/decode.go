@@ -0,0 +1,391 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geobuf
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/gjson"
+)
+
+type decoder struct {
+	keys  []string
+	dims  int
+	scale float64
+}
+
+// Decode returns the object of Geobuf data
+func Decode(data []byte, opts *geojson.ParseOptions) (geojson.Object, error) {
+	d := decoder{dims: 2}
+	precision := 6
+	var kind int
+	var body []byte
+	err := readFields(data, func(field, wire int, v uint64, b []byte) error {
+		switch {
+		case field == dataKeys && wire == wireBytes:
+			d.keys = append(d.keys, string(b))
+		case field == dataDimensions && wire == wireVarint:
+			d.dims = int(v)
+		case field == dataPrecision && wire == wireVarint:
+			precision = int(v)
+		case (field == dataFeatureCollection || field == dataFeature ||
+			field == dataGeometry) && wire == wireBytes:
+			kind, body = field, b
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if kind == 0 || d.dims < 2 || d.dims > 16 || precision < 0 ||
+		precision > 20 {
+		return nil, ErrInvalidData
+	}
+	d.scale = math.Pow10(precision)
+	var dst []byte
+	switch kind {
+	case dataFeatureCollection:
+		dst, err = d.appendCollection(nil, body)
+	case dataFeature:
+		dst, err = d.appendFeature(nil, body)
+	default:
+		dst, err = d.appendGeometry(nil, body)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return geojson.Parse(string(dst), opts)
+}
+
+func appendString(dst []byte, s string) []byte {
+	b, _ := json.Marshal(s)
+	return append(dst, b...)
+}
+
+func appendFloat(dst []byte, f float64) []byte {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return append(dst, "null"...)
+	}
+	return strconv.AppendFloat(dst, f, 'f', -1, 64)
+}
+
+// decodeValue returns a Value message as JSON
+func decodeValue(data []byte) ([]byte, error) {
+	value := []byte("null")
+	err := readFields(data, func(field, wire int, v uint64, b []byte) error {
+		switch field {
+		case valueString:
+			value = appendString(nil, string(b))
+		case valueDouble:
+			value = appendFloat(nil, math.Float64frombits(v))
+		case valuePosInt:
+			value = strconv.AppendUint(nil, v, 10)
+		case valueNegInt:
+			value = strconv.AppendUint([]byte{'-'}, v, 10)
+		case valueBool:
+			value = strconv.AppendBool(nil, v != 0)
+		case valueJSON:
+			if !gjson.ValidBytes(b) {
+				return ErrInvalidData
+			}
+			value = b
+		}
+		return nil
+	})
+	return value, err
+}
+
+// props are the keyed values of a message. The values that are referred to
+// by indexes are the values since the previous indexes.
+type props struct {
+	values        [][]byte
+	properties    []byte
+	custom        []byte
+	hasProperties bool
+	customKeys    map[string]bool
+}
+
+func (d *decoder) readProps(p *props, field, wire int, b []byte) error {
+	if wire != wireBytes {
+		return nil
+	}
+	switch field {
+	case fieldValues:
+		value, err := decodeValue(b)
+		if err != nil {
+			return err
+		}
+		p.values = append(p.values, value)
+	case fieldProperties, fieldCustomProperties:
+		indexes, err := readPacked(b)
+		if err != nil {
+			return err
+		}
+		if len(indexes)%2 != 0 {
+			return ErrInvalidData
+		}
+		dst := &p.custom
+		if field == fieldProperties {
+			dst = &p.properties
+			p.hasProperties = true
+		}
+		for i := 0; i < len(indexes); i += 2 {
+			if indexes[i] >= uint64(len(d.keys)) ||
+				indexes[i+1] >= uint64(len(p.values)) {
+				return ErrInvalidData
+			}
+			key := d.keys[indexes[i]]
+			if field == fieldCustomProperties {
+				if p.customKeys == nil {
+					p.customKeys = make(map[string]bool)
+				}
+				p.customKeys[key] = true
+			}
+			if len(*dst) > 0 {
+				*dst = append(*dst, ',')
+			}
+			*dst = appendString(*dst, key)
+			*dst = append(*dst, ':')
+			*dst = append(*dst, p.values[indexes[i+1]]...)
+		}
+		p.values = p.values[:0]
+	}
+	return nil
+}
+
+func (p *props) appendCustom(dst []byte) []byte {
+	if len(p.custom) > 0 {
+		dst = append(dst, ',')
+		dst = append(dst, p.custom...)
+	}
+	return dst
+}
+
+func (d *decoder) appendCollection(dst, data []byte) ([]byte, error) {
+	dst = append(dst, `{"type":"FeatureCollection","features":[`...)
+	var p props
+	var count int
+	err := readFields(data, func(field, wire int, v uint64, b []byte) error {
+		if field == collectionFeatures && wire == wireBytes {
+			if count > 0 {
+				dst = append(dst, ',')
+			}
+			count++
+			var err error
+			dst, err = d.appendFeature(dst, b)
+			return err
+		}
+		return d.readProps(&p, field, wire, b)
+	})
+	if err != nil {
+		return nil, err
+	}
+	dst = append(dst, ']')
+	return append(p.appendCustom(dst), '}'), nil
+}
+
+func (d *decoder) appendFeature(dst, data []byte) ([]byte, error) {
+	var p props
+	var geom, id []byte
+	err := readFields(data, func(field, wire int, v uint64, b []byte) error {
+		var err error
+		switch {
+		case field == featureGeometry && wire == wireBytes:
+			geom, err = d.appendGeometry(nil, b)
+		case field == featureID && wire == wireBytes:
+			id = appendString(nil, string(b))
+		case field == featureIntID && wire == wireVarint:
+			id = strconv.AppendInt(nil, unzigzag(v), 10)
+		default:
+			err = d.readProps(&p, field, wire, b)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if geom == nil {
+		return nil, ErrInvalidData
+	}
+	dst = append(dst, `{"type":"Feature","geometry":`...)
+	dst = append(dst, geom...)
+	if id != nil {
+		dst = append(dst, `,"id":`...)
+		dst = append(dst, id...)
+	}
+	if p.hasProperties || !p.customKeys["properties"] {
+		dst = append(dst, `,"properties":{`...)
+		dst = append(dst, p.properties...)
+		dst = append(dst, '}')
+	}
+	return append(p.appendCustom(dst), '}'), nil
+}
+
+func (d *decoder) appendGeometry(dst, data []byte) ([]byte, error) {
+	var p props
+	var typ uint64
+	var lengths []uint64
+	var coords []int64
+	var geoms [][]byte
+	err := readFields(data, func(field, wire int, v uint64, b []byte) error {
+		switch {
+		case field == geometryType && wire == wireVarint:
+			typ = v
+		case field == geometryLengths && wire == wireBytes:
+			var err error
+			lengths, err = readPacked(b)
+			return err
+		case field == geometryCoords && wire == wireBytes:
+			vals, err := readPacked(b)
+			for _, v := range vals {
+				coords = append(coords, unzigzag(v))
+			}
+			return err
+		case field == geometryGeometries && wire == wireBytes:
+			geom, err := d.appendGeometry(nil, b)
+			geoms = append(geoms, geom)
+			return err
+		default:
+			return d.readProps(&p, field, wire, b)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if typ >= uint64(len(geomTypes)) || len(coords)%d.dims != 0 {
+		return nil, ErrInvalidData
+	}
+	dst = append(dst, `{"type":"`...)
+	dst = append(dst, geomTypes[typ]...)
+	dst = append(dst, '"')
+	if geomTypes[typ] == "GeometryCollection" {
+		dst = append(dst, `,"geometries":[`...)
+		for i, geom := range geoms {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = append(dst, geom...)
+		}
+		dst = append(dst, ']')
+	} else {
+		dst = append(dst, `,"coordinates":`...)
+		if dst, err = d.appendCoords(dst, geomTypes[typ], lengths,
+			coords); err != nil {
+			return nil, err
+		}
+	}
+	return append(p.appendCustom(dst), '}'), nil
+}
+
+func (d *decoder) appendCoords(dst []byte, typ string, lengths []uint64,
+	coords []int64,
+) ([]byte, error) {
+	// next returns the coordinates of the next n positions
+	next := func(n uint64) ([]int64, error) {
+		if n > uint64(len(coords)/d.dims) {
+			return nil, ErrInvalidData
+		}
+		line := coords[:int(n)*d.dims]
+		coords = coords[len(line):]
+		return line, nil
+	}
+	switch typ {
+	case "Point":
+		if len(coords) != d.dims {
+			return nil, ErrInvalidData
+		}
+		return d.appendPosition(dst, coords), nil
+	case "MultiPoint", "LineString":
+		return d.appendLine(dst, coords, false), nil
+	case "MultiLineString", "Polygon":
+		closed := typ == "Polygon"
+		if len(lengths) == 0 {
+			dst = append(dst, '[')
+			dst = d.appendLine(dst, coords, closed)
+			return append(dst, ']'), nil
+		}
+		dst = append(dst, '[')
+		for i, n := range lengths {
+			line, err := next(n)
+			if err != nil {
+				return nil, err
+			}
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = d.appendLine(dst, line, closed)
+		}
+		return append(dst, ']'), nil
+	}
+	// MultiPolygon
+	if len(lengths) == 0 {
+		dst = append(dst, "[["...)
+		dst = d.appendLine(dst, coords, true)
+		return append(dst, "]]"...), nil
+	}
+	dst = append(dst, '[')
+	for i, j := uint64(0), 1; i < lengths[0]; i++ {
+		if j >= len(lengths) || lengths[j] > uint64(len(lengths)-j-1) {
+			return nil, ErrInvalidData
+		}
+		rings := lengths[j+1 : j+1+int(lengths[j])]
+		j += len(rings) + 1
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = append(dst, '[')
+		for k, n := range rings {
+			line, err := next(n)
+			if err != nil {
+				return nil, err
+			}
+			if k > 0 {
+				dst = append(dst, ',')
+			}
+			dst = d.appendLine(dst, line, true)
+		}
+		dst = append(dst, ']')
+	}
+	return append(dst, ']'), nil
+}
+
+func (d *decoder) appendPosition(dst []byte, vals []int64) []byte {
+	dst = append(dst, '[')
+	for i, v := range vals {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = appendFloat(dst, float64(v)/d.scale)
+	}
+	return append(dst, ']')
+}
+
+// appendLine appends the positions of delta-encoded coordinates. A closed
+// ring ends with its first position.
+func (d *decoder) appendLine(dst []byte, coords []int64, closed bool) []byte {
+	sum := make([]int64, d.dims)
+	var first []int64
+	dst = append(dst, '[')
+	for i := 0; i < len(coords); i += d.dims {
+		for j := range sum {
+			sum[j] += coords[i+j]
+		}
+		if i > 0 {
+			dst = append(dst, ',')
+		} else {
+			first = append(first, sum...)
+		}
+		dst = d.appendPosition(dst, sum)
+	}
+	if closed && first != nil {
+		dst = append(dst, ',')
+		dst = d.appendPosition(dst, first)
+	}
+	return append(dst, ']')
+}
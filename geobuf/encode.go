@@ -0,0 +1,311 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geobuf
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/gjson"
+)
+
+type encoder struct {
+	keys      map[string]int
+	order     []string
+	dims      int
+	precision int
+	scale     float64
+	maxAbs    float64 // largest absolute coordinate
+}
+
+// Encode returns the Geobuf of an object. The ids and properties of
+// Features, and the foreign members of every object, such as "bbox", are
+// kept. Geometries in the features of a FeatureCollection become Features.
+// All positions have the most dimensions of any position, where missing
+// values are zero.
+func Encode(obj geojson.Object, opts *EncodeOptions) []byte {
+	if opts == nil {
+		opts = DefaultEncodeOptions
+	}
+	maxPrecision := opts.MaxPrecision
+	if maxPrecision < 0 {
+		maxPrecision = 0
+	} else if maxPrecision > 15 {
+		maxPrecision = 15
+	}
+	data := gjson.Parse(obj.JSON())
+	e := encoder{keys: make(map[string]int), dims: 2}
+	e.analyze(data, maxPrecision)
+	// the scaled coordinates must fit in an int64
+	for e.precision > 0 && e.maxAbs*math.Pow10(e.precision) >= 1<<63 {
+		e.precision--
+	}
+	e.scale = math.Pow10(e.precision)
+	var field int
+	var body []byte
+	switch data.Get("type").String() {
+	case "FeatureCollection":
+		field, body = dataFeatureCollection, e.encodeCollection(data)
+	case "Feature":
+		field, body = dataFeature, e.encodeFeature(data)
+	default:
+		field, body = dataGeometry, e.encodeGeometry(data)
+	}
+	var dst []byte
+	for _, key := range e.order {
+		dst = appendBytesField(dst, dataKeys, []byte(key))
+	}
+	if e.dims != 2 {
+		dst = appendVarintField(dst, dataDimensions, uint64(e.dims))
+	}
+	if e.precision != 6 {
+		dst = appendVarintField(dst, dataPrecision, uint64(e.precision))
+	}
+	return appendBytesField(dst, field, body)
+}
+
+// analyze finds the dimensions and the precision of the coordinates
+func (e *encoder) analyze(g gjson.Result, maxPrecision int) {
+	switch g.Get("type").String() {
+	case "FeatureCollection":
+		for _, f := range g.Get("features").Array() {
+			e.analyze(f, maxPrecision)
+		}
+	case "Feature":
+		e.analyze(g.Get("geometry"), maxPrecision)
+	case "GeometryCollection":
+		for _, child := range g.Get("geometries").Array() {
+			e.analyze(child, maxPrecision)
+		}
+	default:
+		e.analyzeCoords(g.Get("coordinates"), maxPrecision)
+	}
+}
+
+func (e *encoder) analyzeCoords(coords gjson.Result, maxPrecision int) {
+	vals := coords.Array()
+	if len(vals) == 0 || vals[0].Type != gjson.Number {
+		for _, child := range vals {
+			e.analyzeCoords(child, maxPrecision)
+		}
+		return
+	}
+	if len(vals) > e.dims {
+		e.dims = len(vals)
+	}
+	for _, v := range vals {
+		x := v.Float()
+		e.maxAbs = math.Max(e.maxAbs, math.Abs(x))
+		for e.precision < maxPrecision {
+			scale := math.Pow10(e.precision)
+			if math.Round(x*scale)/scale == x {
+				break
+			}
+			e.precision++
+		}
+	}
+}
+
+func (e *encoder) key(key string) uint64 {
+	i, ok := e.keys[key]
+	if !ok {
+		i = len(e.order)
+		e.keys[key] = i
+		e.order = append(e.order, key)
+	}
+	return uint64(i)
+}
+
+// appendProps appends the values of an object, followed by the key and
+// value indexes in a field.
+func (e *encoder) appendProps(dst []byte, obj gjson.Result, field int,
+	skip func(key string) bool,
+) []byte {
+	var indexes []uint64
+	obj.ForEach(func(key, value gjson.Result) bool {
+		if skip != nil && skip(key.String()) {
+			return true
+		}
+		dst = appendBytesField(dst, fieldValues, encodeValue(value))
+		indexes = append(indexes, e.key(key.String()), uint64(len(indexes)/2))
+		return true
+	})
+	if len(indexes) > 0 {
+		dst = appendPackedField(dst, field, indexes)
+	}
+	return dst
+}
+
+func isInteger(value gjson.Result) bool {
+	return value.Type == gjson.Number && !strings.ContainsAny(value.Raw, ".eE")
+}
+
+func encodeValue(value gjson.Result) []byte {
+	switch value.Type {
+	case gjson.String:
+		return appendBytesField(nil, valueString, []byte(value.Str))
+	case gjson.True:
+		return appendVarintField(nil, valueBool, 1)
+	case gjson.False:
+		return appendVarintField(nil, valueBool, 0)
+	case gjson.Number:
+		if isInteger(value) {
+			if value.Raw[0] == '-' {
+				n, err := strconv.ParseUint(value.Raw[1:], 10, 64)
+				if err == nil {
+					return appendVarintField(nil, valueNegInt, n)
+				}
+			} else if n, err := strconv.ParseUint(value.Raw, 10, 64); err == nil {
+				return appendVarintField(nil, valuePosInt, n)
+			}
+		}
+		return appendFixed64Field(nil, valueDouble,
+			math.Float64bits(value.Float()))
+	}
+	return appendBytesField(nil, valueJSON, []byte(value.Raw))
+}
+
+func (e *encoder) encodeCollection(g gjson.Result) []byte {
+	var dst []byte
+	for _, f := range g.Get("features").Array() {
+		dst = appendBytesField(dst, collectionFeatures, e.encodeFeature(f))
+	}
+	return e.appendProps(dst, g, fieldCustomProperties, func(key string) bool {
+		return key == "type" || key == "features"
+	})
+}
+
+func (e *encoder) encodeFeature(g gjson.Result) []byte {
+	if g.Get("type").String() != "Feature" {
+		return appendBytesField(nil, featureGeometry, e.encodeGeometry(g))
+	}
+	dst := appendBytesField(nil, featureGeometry,
+		e.encodeGeometry(g.Get("geometry")))
+	// ids that are not strings or integers, and properties that are not
+	// objects, are custom properties
+	var customID bool
+	if id := g.Get("id"); id.Type == gjson.String {
+		dst = appendBytesField(dst, featureID, []byte(id.Str))
+	} else if n, err := strconv.ParseInt(id.Raw, 10, 64); err == nil &&
+		isInteger(id) {
+		dst = appendVarintField(dst, featureIntID, zigzag(n))
+	} else {
+		customID = id.Exists()
+	}
+	props := g.Get("properties")
+	if props.IsObject() {
+		dst = e.appendProps(dst, props, fieldProperties, nil)
+	}
+	return e.appendProps(dst, g, fieldCustomProperties, func(key string) bool {
+		switch key {
+		case "type", "geometry":
+			return true
+		case "id":
+			return !customID
+		case "properties":
+			return props.IsObject()
+		}
+		return false
+	})
+}
+
+func (e *encoder) encodeGeometry(g gjson.Result) []byte {
+	typ := g.Get("type").String()
+	for i, name := range geomTypes {
+		if name == typ {
+			// types are always written, even as zero
+			dst := appendVarintField(nil, geometryType, uint64(i))
+			dst = e.appendCoords(dst, typ, g)
+			return e.appendProps(dst, g, fieldCustomProperties,
+				func(key string) bool {
+					return key == "type" || key == "coordinates" ||
+						key == "geometries"
+				})
+		}
+	}
+	return nil
+}
+
+func (e *encoder) appendCoords(dst []byte, typ string, g gjson.Result,
+) []byte {
+	coords := g.Get("coordinates")
+	var lengths, vals []uint64
+	switch typ {
+	case "Point":
+		vals = e.appendLine(vals, []gjson.Result{coords}, false)
+	case "MultiPoint", "LineString":
+		vals = e.appendLine(vals, coords.Array(), false)
+	case "MultiLineString", "Polygon":
+		closed := typ == "Polygon"
+		lines := coords.Array()
+		for _, line := range lines {
+			points := line.Array()
+			if len(lines) != 1 {
+				n := len(points)
+				if closed {
+					n--
+				}
+				lengths = append(lengths, uint64(n))
+			}
+			vals = e.appendLine(vals, points, closed)
+		}
+	case "MultiPolygon":
+		polys := coords.Array()
+		single := len(polys) == 1 && len(polys[0].Array()) == 1
+		if !single {
+			lengths = append(lengths, uint64(len(polys)))
+		}
+		for _, poly := range polys {
+			rings := poly.Array()
+			if !single {
+				lengths = append(lengths, uint64(len(rings)))
+			}
+			for _, ring := range rings {
+				points := ring.Array()
+				if !single {
+					lengths = append(lengths, uint64(len(points)-1))
+				}
+				vals = e.appendLine(vals, points, true)
+			}
+		}
+	case "GeometryCollection":
+		for _, child := range g.Get("geometries").Array() {
+			dst = appendBytesField(dst, geometryGeometries,
+				e.encodeGeometry(child))
+		}
+	}
+	if len(lengths) > 0 {
+		dst = appendPackedField(dst, geometryLengths, lengths)
+	}
+	if len(vals) > 0 {
+		dst = appendPackedField(dst, geometryCoords, vals)
+	}
+	return dst
+}
+
+// appendLine appends the delta-encoded coordinates of a line. The last
+// position of a closed ring is left out.
+func (e *encoder) appendLine(vals []uint64, line []gjson.Result, closed bool,
+) []uint64 {
+	if closed && len(line) > 0 {
+		line = line[:len(line)-1]
+	}
+	sum := make([]int64, e.dims)
+	for _, pos := range line {
+		coords := pos.Array()
+		for j := range sum {
+			var x float64
+			if j < len(coords) {
+				x = coords[j].Float()
+			}
+			n := int64(math.Round(x * e.scale))
+			vals = append(vals, zigzag(n-sum[j]))
+			sum[j] = n
+		}
+	}
+	return vals
+}
@@ -0,0 +1,70 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+// Package geobuf encodes and decodes Geobuf, which is GeoJSON in protocol
+// buffers with delta-encoded integer coordinates and indexed keys.
+package geobuf
+
+import "errors"
+
+// ErrInvalidData is returned when data is not valid Geobuf
+var ErrInvalidData = errors.New("invalid geobuf data")
+
+// EncodeOptions ...
+type EncodeOptions struct {
+	// MaxPrecision is the largest number of decimal digits of coordinates.
+	// Coordinates with more digits are rounded. The precision of the data is
+	// the fewest digits that keep all of the coordinates, up to 15.
+	MaxPrecision int
+}
+
+// DefaultEncodeOptions ...
+var DefaultEncodeOptions = &EncodeOptions{
+	MaxPrecision: 15,
+}
+
+// Data fields
+const (
+	dataKeys              = 1
+	dataDimensions        = 2
+	dataPrecision         = 3
+	dataFeatureCollection = 4
+	dataFeature           = 5
+	dataGeometry          = 6
+)
+
+// Feature, FeatureCollection, and Geometry fields
+const (
+	featureGeometry = 1
+	featureID       = 11
+	featureIntID    = 12
+
+	collectionFeatures = 1
+
+	geometryType       = 1
+	geometryLengths    = 2
+	geometryCoords     = 3
+	geometryGeometries = 4
+
+	// shared by all messages
+	fieldValues           = 13
+	fieldProperties       = 14
+	fieldCustomProperties = 15
+)
+
+// Value fields
+const (
+	valueString = 1
+	valueDouble = 2
+	valuePosInt = 3
+	valueNegInt = 4
+	valueBool   = 5
+	valueJSON   = 6
+)
+
+// geomTypes are the geometry types in the order of their values
+var geomTypes = []string{
+	"Point", "MultiPoint", "LineString", "MultiLineString", "Polygon",
+	"MultiPolygon", "GeometryCollection",
+}
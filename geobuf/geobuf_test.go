@@ -0,0 +1,121 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geobuf
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/geojson/geometry"
+)
+
+func expect(t testing.TB, what bool) {
+	t.Helper()
+	if !what {
+		t.Fatal("expection failure")
+	}
+}
+
+func roundTrip(t *testing.T, json string, opts *EncodeOptions) string {
+	t.Helper()
+	obj, err := geojson.Parse(json, nil)
+	expect(t, err == nil)
+	decoded, err := Decode(Encode(obj, opts), nil)
+	expect(t, err == nil)
+	return decoded.JSON()
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []string{
+		`{"type":"Point","coordinates":[1,2]}`,
+		`{"type":"Point","coordinates":[-1.5,2.25,100],"bbox":[-1.5,2.25,-1.5,2.25],"foo":{"a":[1,2]}}`,
+		`{"type":"MultiPoint","coordinates":[[1,2],[3,4],[-5,-6]]}`,
+		`{"type":"LineString","coordinates":[[-122.419416,37.774929],[-122.5,37.8]]}`,
+		`{"type":"MultiLineString","coordinates":[[[0,0],[1,1]],[[2,2],[3,3],[4,4]]]}`,
+		`{"type":"MultiLineString","coordinates":[[[0,0],[1,1]]]}`,
+		`{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[2,2],[4,2],[4,4],[2,2]]]}`,
+		`{"type":"Polygon","coordinates":[[[0,0,1],[10,0,2],[10,10,3],[0,0,1]]]}`,
+		`{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,0]]]]}`,
+		`{"type":"MultiPolygon","coordinates":[[[[0,0],[10,0],[10,10],[0,0]],[[2,1],[3,1],[3,2],[2,1]]],[[[20,20],[21,20],[21,21],[20,20]]]]}`,
+		`{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2]},{"type":"LineString","coordinates":[[8,8],[9,9]],"name":"line"}]}`,
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"id":"a","properties":{"s":"str","pos":10,"neg":-20,"f":1.5,"t":true,"n":null,"o":{"k":[1,"v"]}}}`,
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"id":-99,"properties":{}}`,
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":{},"id":1.5,"title":"custom"}`,
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":null}`,
+		`{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"id":1,"properties":{"a":1}},{"type":"Feature","geometry":{"type":"LineString","coordinates":[[0,0],[1,1]]},"properties":{"a":"two","b":false}}],"bbox":[0,0,1,2]}`,
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},"properties":{"type":"Circle","radius":1000,"radius_units":"m"}}`,
+	}
+	for _, json := range tests {
+		if out := roundTrip(t, json, nil); out != json {
+			t.Fatalf("expected '%s', got '%s'", json, out)
+		}
+	}
+
+	// coordinates keep their digits by default
+	for _, json := range []string{
+		`{"type":"Point","coordinates":[1.12345678,2.1]}`,
+		`{"type":"Point","coordinates":[-122.419415512345,37.7749291234567,1234.56789]}`,
+		`{"type":"LineString","coordinates":[[0.000000001,-0.1],[179.99999999999,-89.123456789012]]}`,
+	} {
+		if out := roundTrip(t, json, nil); out != json {
+			t.Fatalf("expected '%s', got '%s'", json, out)
+		}
+	}
+	// large coordinates are rounded to fit
+	expect(t, roundTrip(t, `{"type":"Point","coordinates":[0.1,2,12345678.123456789]}`,
+		nil) == `{"type":"Point","coordinates":[0.1,2,12345678.12345679]}`)
+	// coordinates are rounded to the max precision
+	expect(t, roundTrip(t, `{"type":"Point","coordinates":[1.123456789,2]}`,
+		&EncodeOptions{MaxPrecision: 6}) ==
+		`{"type":"Point","coordinates":[1.123457,2]}`)
+	expect(t, roundTrip(t, `{"type":"Point","coordinates":[1.123456789,2]}`,
+		&EncodeOptions{MaxPrecision: 9}) ==
+		`{"type":"Point","coordinates":[1.123456789,2]}`)
+	// missing dimensions are zero
+	expect(t, roundTrip(t, `{"type":"MultiPoint","coordinates":[[1,2,3],[4,5]]}`,
+		nil) == `{"type":"MultiPoint","coordinates":[[1,2,3],[4,5,0]]}`)
+	// rects are polygons
+	rect := geojson.NewRect(geometry.Rect{
+		Min: geometry.Point{X: 1, Y: 2}, Max: geometry.Point{X: 3, Y: 4},
+	})
+	obj, err := Decode(Encode(rect, nil), nil)
+	expect(t, err == nil && obj.JSON() == rect.JSON())
+}
+
+func TestEncoding(t *testing.T) {
+	// a point with integer coordinates has a precision of zero
+	data := Encode(geojson.NewPoint(geometry.Point{X: 1, Y: 2}), nil)
+	expect(t, bytes.Equal(data, []byte{
+		0x18, 0x00, // precision
+		0x32, 0x06, // geometry
+		0x08, 0x00, // type
+		0x1a, 0x02, 0x02, 0x04, // coordinates
+	}))
+	// keys are shared by every feature
+	obj, _ := geojson.Parse(`{"type":"FeatureCollection","features":[
+		{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":{"name":"a"}},
+		{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":{"name":"b"}}
+	]}`, nil)
+	data = Encode(obj, nil)
+	expect(t, bytes.Count(data, []byte("name")) == 1)
+}
+
+func TestInvalid(t *testing.T) {
+	obj, _ := geojson.Parse(`{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]]]},"properties":{"a":1}}`, nil)
+	data := Encode(obj, nil)
+	_, err := Decode(nil, nil)
+	expect(t, err == ErrInvalidData)
+	_, err = Decode(data[:len(data)-1], nil)
+	expect(t, err == ErrInvalidData)
+	// every corrupted message is an error or an object, and never a panic
+	for i := range data {
+		for _, b := range []byte{0x00, 0x07, 0x7f, 0xff} {
+			corrupt := append([]byte{}, data...)
+			corrupt[i] = b
+			Decode(corrupt, nil)
+		}
+	}
+}
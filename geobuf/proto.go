@@ -0,0 +1,140 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geobuf
+
+import (
+	"encoding/binary"
+)
+
+// Protocol buffer wire types
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+func appendVarint(dst []byte, v uint64) []byte {
+	for v >= 0x80 {
+		dst = append(dst, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(dst, byte(v))
+}
+
+func appendKey(dst []byte, field, wire int) []byte {
+	return appendVarint(dst, uint64(field<<3|wire))
+}
+
+func appendVarintField(dst []byte, field int, v uint64) []byte {
+	return appendVarint(appendKey(dst, field, wireVarint), v)
+}
+
+func appendFixed64Field(dst []byte, field int, v uint64) []byte {
+	dst = appendKey(dst, field, wireFixed64)
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], v)
+	return append(dst, b[:]...)
+}
+
+func appendBytesField(dst []byte, field int, b []byte) []byte {
+	dst = appendKey(dst, field, wireBytes)
+	dst = appendVarint(dst, uint64(len(b)))
+	return append(dst, b...)
+}
+
+func appendPackedField(dst []byte, field int, vals []uint64) []byte {
+	var b []byte
+	for _, v := range vals {
+		b = appendVarint(b, v)
+	}
+	return appendBytesField(dst, field, b)
+}
+
+func readVarint(data []byte) (v uint64, n int) {
+	for shift := uint(0); shift < 64; shift += 7 {
+		if n >= len(data) {
+			return 0, 0
+		}
+		b := data[n]
+		n++
+		v |= uint64(b&0x7f) << shift
+		if b < 0x80 {
+			return v, n
+		}
+	}
+	return 0, 0
+}
+
+// readFields calls iter for every field in a message. Varint and fixed
+// fields are passed as v, and length-delimited fields are passed as b.
+func readFields(data []byte,
+	iter func(field, wire int, v uint64, b []byte) error,
+) error {
+	for len(data) > 0 {
+		key, n := readVarint(data)
+		if n == 0 {
+			return ErrInvalidData
+		}
+		data = data[n:]
+		field, wire := int(key>>3), int(key&7)
+		var v uint64
+		var b []byte
+		switch wire {
+		case wireVarint:
+			v, n = readVarint(data)
+			if n == 0 {
+				return ErrInvalidData
+			}
+		case wireFixed64:
+			if len(data) < 8 {
+				return ErrInvalidData
+			}
+			v, n = binary.LittleEndian.Uint64(data), 8
+		case wireBytes:
+			var size uint64
+			size, n = readVarint(data)
+			if n == 0 || size > uint64(len(data)-n) {
+				return ErrInvalidData
+			}
+			b = data[n : n+int(size)]
+			n += int(size)
+		case wireFixed32:
+			if len(data) < 4 {
+				return ErrInvalidData
+			}
+			v, n = uint64(binary.LittleEndian.Uint32(data)), 4
+		default:
+			return ErrInvalidData
+		}
+		data = data[n:]
+		if err := iter(field, wire, v, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readPacked returns the values of a packed repeated field
+func readPacked(data []byte) ([]uint64, error) {
+	var vals []uint64
+	for len(data) > 0 {
+		v, n := readVarint(data)
+		if n == 0 {
+			return nil, ErrInvalidData
+		}
+		vals = append(vals, v)
+		data = data[n:]
+	}
+	return vals, nil
+}
+
+func zigzag(n int64) uint64 {
+	return uint64((n << 1) ^ (n >> 63))
+}
+
+func unzigzag(v uint64) int64 {
+	return int64(v>>1) ^ -int64(v&1)
+}
@@ -0,0 +1,80 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geohash
+
+import (
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/geojson/geometry"
+)
+
+// Cell is a geohash in a covering
+type Cell struct {
+	Hash string
+	// Interior is true when the object contains the entire cell, and false
+	// when the cell is on the boundary of the object.
+	Interior bool
+}
+
+// Cover returns the geohashes that cover an object. Cells that are inside
+// of the object are kept as large as possible, but no larger than
+// minPrecision, while cells on the boundary of the object are divided down
+// to maxPrecision. Use the same min and max precision for a covering where
+// every cell has the same precision.
+func Cover(obj geojson.Object, minPrecision, maxPrecision int) []Cell {
+	if minPrecision < 1 {
+		minPrecision = 1
+	}
+	if maxPrecision < minPrecision {
+		maxPrecision = minPrecision
+	}
+	if obj.Empty() {
+		return nil
+	}
+	c := coverer{obj: obj, rect: obj.Rect(), min: minPrecision,
+		max: maxPrecision}
+	c.cover("")
+	return c.cells
+}
+
+type coverer struct {
+	obj      geojson.Object
+	rect     geometry.Rect
+	min, max int
+	cells    []Cell
+}
+
+// cover visits the children of a geohash
+func (c *coverer) cover(parent string) {
+	for i := 0; i < len(base32); i++ {
+		hash := parent + base32[i:i+1]
+		rect, _ := Rect(hash)
+		if !c.rect.IntersectsRect(rect) {
+			continue
+		}
+		cell := geojson.NewRect(rect)
+		if !c.obj.Intersects(cell) {
+			continue
+		}
+		if c.obj.Contains(cell) {
+			c.interior(hash)
+		} else if len(hash) >= c.max {
+			c.cells = append(c.cells, Cell{Hash: hash})
+		} else {
+			c.cover(hash)
+		}
+	}
+}
+
+// interior adds an interior cell, which is divided when it's larger than the
+// minimum precision.
+func (c *coverer) interior(hash string) {
+	if len(hash) >= c.min {
+		c.cells = append(c.cells, Cell{Hash: hash, Interior: true})
+		return
+	}
+	for i := 0; i < len(base32); i++ {
+		c.interior(hash + base32[i:i+1])
+	}
+}
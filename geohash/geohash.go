@@ -0,0 +1,207 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geohash
+
+import (
+	"errors"
+	"math"
+
+	"github.com/tidwall/geojson/geometry"
+)
+
+var (
+	// ErrInvalidHash is returned when a geohash is empty or contains
+	// characters that are not in the geohash alphabet.
+	ErrInvalidHash = errors.New("invalid geohash")
+	// ErrInvalidDirection is returned for an unknown direction
+	ErrInvalidDirection = errors.New("invalid direction")
+)
+
+// base32 is the geohash alphabet
+const base32 = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// decodeMap maps characters to their 5-bit values, or -1 for invalid
+// characters.
+var decodeMap = func() (m [256]int8) {
+	for i := range m {
+		m[i] = -1
+	}
+	for i := 0; i < len(base32); i++ {
+		m[base32[i]] = int8(i)
+	}
+	return m
+}()
+
+// Encode returns the geohash of a point with the number of characters of
+// precision.
+func Encode(lat, lon float64, precision int) string {
+	if precision < 1 {
+		precision = 1
+	}
+	minLat, maxLat := -90.0, 90.0
+	minLon, maxLon := -180.0, 180.0
+	hash := make([]byte, precision)
+	even := true
+	for i := 0; i < precision; i++ {
+		var ch byte
+		for bit := 4; bit >= 0; bit-- {
+			if even {
+				mid := (minLon + maxLon) / 2
+				if lon >= mid {
+					ch |= 1 << uint(bit)
+					minLon = mid
+				} else {
+					maxLon = mid
+				}
+			} else {
+				mid := (minLat + maxLat) / 2
+				if lat >= mid {
+					ch |= 1 << uint(bit)
+					minLat = mid
+				} else {
+					maxLat = mid
+				}
+			}
+			even = !even
+		}
+		hash[i] = base32[ch]
+	}
+	return string(hash)
+}
+
+// Rect returns the rectangle covered by a geohash
+func Rect(hash string) (geometry.Rect, error) {
+	if hash == "" {
+		return geometry.Rect{}, ErrInvalidHash
+	}
+	minLat, maxLat := -90.0, 90.0
+	minLon, maxLon := -180.0, 180.0
+	even := true
+	for i := 0; i < len(hash); i++ {
+		ch := decodeMap[hash[i]]
+		if ch < 0 {
+			return geometry.Rect{}, ErrInvalidHash
+		}
+		for bit := 4; bit >= 0; bit-- {
+			on := ch&(1<<uint(bit)) != 0
+			if even {
+				mid := (minLon + maxLon) / 2
+				if on {
+					minLon = mid
+				} else {
+					maxLon = mid
+				}
+			} else {
+				mid := (minLat + maxLat) / 2
+				if on {
+					minLat = mid
+				} else {
+					maxLat = mid
+				}
+			}
+			even = !even
+		}
+	}
+	return geometry.Rect{
+		Min: geometry.Point{X: minLon, Y: minLat},
+		Max: geometry.Point{X: maxLon, Y: maxLat},
+	}, nil
+}
+
+// Decode returns the center point of a geohash
+func Decode(hash string) (lat, lon float64, err error) {
+	rect, err := Rect(hash)
+	if err != nil {
+		return 0, 0, err
+	}
+	center := rect.Center()
+	return center.Y, center.X, nil
+}
+
+// Direction is a compass direction from a geohash to one of its neighbors
+type Direction byte
+
+// Directions
+const (
+	North Direction = iota
+	NorthEast
+	East
+	SouthEast
+	South
+	SouthWest
+	West
+	NorthWest
+)
+
+func (dir Direction) String() string {
+	switch dir {
+	case North:
+		return "North"
+	case NorthEast:
+		return "NorthEast"
+	case East:
+		return "East"
+	case SouthEast:
+		return "SouthEast"
+	case South:
+		return "South"
+	case SouthWest:
+		return "SouthWest"
+	case West:
+		return "West"
+	case NorthWest:
+		return "NorthWest"
+	}
+	return "Unknown"
+}
+
+// offsets are the number of cells to move for each direction
+var offsets = [...][2]float64{
+	North:     {0, 1},
+	NorthEast: {1, 1},
+	East:      {1, 0},
+	SouthEast: {1, -1},
+	South:     {0, -1},
+	SouthWest: {-1, -1},
+	West:      {-1, 0},
+	NorthWest: {-1, 1},
+}
+
+// Neighbor returns the geohash of the same precision that is next to a
+// geohash in a direction. Neighbors wrap around the antimeridian. Returns an
+// empty string when there is no neighbor past a pole.
+func Neighbor(hash string, dir Direction) (string, error) {
+	rect, err := Rect(hash)
+	if err != nil {
+		return "", err
+	}
+	if int(dir) >= len(offsets) {
+		return "", ErrInvalidDirection
+	}
+	width := rect.Max.X - rect.Min.X
+	height := rect.Max.Y - rect.Min.Y
+	center := rect.Center()
+	lon := center.X + offsets[dir][0]*width
+	lat := center.Y + offsets[dir][1]*height
+	if lat > 90 || lat < -90 {
+		return "", nil
+	}
+	lon = math.Mod(lon+540, 360) - 180
+	return Encode(lat, lon, len(hash)), nil
+}
+
+// Neighbors returns the eight geohashes around a geohash, in the order of
+// the directions from North to NorthWest.
+func Neighbors(hash string) ([8]string, error) {
+	var neighbors [8]string
+	for dir := North; dir <= NorthWest; dir++ {
+		neighbor, err := Neighbor(hash, dir)
+		if err != nil {
+			return neighbors, err
+		}
+		neighbors[dir] = neighbor
+	}
+	return neighbors, nil
+}
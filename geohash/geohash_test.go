@@ -0,0 +1,169 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geohash
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/geojson/geometry"
+)
+
+func init() {
+	seed := time.Now().UnixNano()
+	println(seed)
+	rand.Seed(seed)
+}
+
+func expect(t testing.TB, what bool) {
+	t.Helper()
+	if !what {
+		t.Fatal("expection failure")
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	expect(t, Encode(57.64911, 10.40744, 11) == "u4pruydqqvj")
+	expect(t, Encode(42.6, -5.6, 5) == "ezs42")
+	expect(t, Encode(0, 0, 0) == "s")
+	lat, lon, err := Decode("ezs42")
+	expect(t, err == nil)
+	expect(t, math.Abs(lat-42.605) < 0.001)
+	expect(t, math.Abs(lon - -5.603) < 0.001)
+	rect, err := Rect("ezs42")
+	expect(t, err == nil)
+	expect(t, rect.ContainsPoint(geometry.Point{X: -5.6, Y: 42.6}))
+	expect(t, math.Abs(rect.Max.X-rect.Min.X-360.0/(1<<13)) < 1e-12)
+	expect(t, math.Abs(rect.Max.Y-rect.Min.Y-180.0/(1<<12)) < 1e-12)
+
+	_, err = Rect("")
+	expect(t, err == ErrInvalidHash)
+	_, _, err = Decode("ezs4a")
+	expect(t, err == ErrInvalidHash)
+
+	for i := 0; i < 1000; i++ {
+		lat := rand.Float64()*180 - 90
+		lon := rand.Float64()*360 - 180
+		precision := rand.Intn(12) + 1
+		hash := Encode(lat, lon, precision)
+		expect(t, len(hash) == precision)
+		rect, err := Rect(hash)
+		expect(t, err == nil)
+		expect(t, rect.ContainsPoint(geometry.Point{X: lon, Y: lat}))
+		expect(t, Encode(lat, lon, precision+1)[:precision] == hash)
+	}
+}
+
+func TestNeighbors(t *testing.T) {
+	neighbors, err := Neighbors("gbsuv")
+	expect(t, err == nil)
+	expect(t, neighbors == [8]string{
+		"gbsvj", "gbsvn", "gbsuy", "gbsuw", "gbsut", "gbsus", "gbsuu", "gbsvh",
+	})
+	hash, err := Neighbor("gbsuv", West)
+	expect(t, err == nil && hash == "gbsuu")
+	_, err = Neighbor("gbsuv", NorthWest+1)
+	expect(t, err == ErrInvalidDirection)
+	_, err = Neighbors("gbsu!")
+	expect(t, err == ErrInvalidHash)
+	expect(t, North.String() == "North")
+	expect(t, Direction(100).String() == "Unknown")
+
+	// antimeridian
+	east := Encode(0.1, 179.99, 4)
+	hash, _ = Neighbor(east, East)
+	rect, _ := Rect(hash)
+	expect(t, rect.Min.X == -180)
+	hash, _ = Neighbor(hash, West)
+	expect(t, hash == east)
+
+	// poles
+	hash, _ = Neighbor(Encode(89.99, 10, 3), North)
+	expect(t, hash == "")
+	hash, _ = Neighbor(Encode(-89.99, 10, 3), SouthEast)
+	expect(t, hash == "")
+	hash, _ = Neighbor(Encode(-89.99, 10, 3), North)
+	expect(t, hash != "")
+}
+
+func TestCover(t *testing.T) {
+	poly := geojson.NewPolygon(geometry.NewPoly([]geometry.Point{
+		{X: -112.3, Y: 33.2}, {X: -111.6, Y: 33.2}, {X: -111.6, Y: 33.8},
+		{X: -112.0, Y: 33.5}, {X: -112.3, Y: 33.8}, {X: -112.3, Y: 33.2},
+	}, nil, nil))
+	cells := Cover(poly, 1, 5)
+	expect(t, len(cells) > 0)
+	var interior, boundary int
+	seen := make(map[string]bool)
+	for _, cell := range cells {
+		expect(t, !seen[cell.Hash])
+		seen[cell.Hash] = true
+		rect, err := Rect(cell.Hash)
+		expect(t, err == nil)
+		if cell.Interior {
+			interior++
+			expect(t, poly.Contains(geojson.NewRect(rect)))
+		} else {
+			boundary++
+			expect(t, len(cell.Hash) == 5)
+			expect(t, poly.Intersects(geojson.NewRect(rect)))
+			expect(t, !poly.Contains(geojson.NewRect(rect)))
+		}
+	}
+	expect(t, interior > 0 && boundary > 0)
+	// mixed precision keeps the interior cells large
+	var mixed bool
+	for _, cell := range cells {
+		if cell.Interior && len(cell.Hash) < 5 {
+			mixed = true
+		}
+	}
+	expect(t, mixed)
+
+	// every point in the polygon is covered by exactly one cell
+	for i := 0; i < 1000; i++ {
+		point := geometry.Point{
+			X: -112.3 + rand.Float64()*0.7,
+			Y: 33.2 + rand.Float64()*0.6,
+		}
+		if !poly.Contains(geojson.NewSimplePoint(point)) {
+			continue
+		}
+		var count int
+		hash := Encode(point.Y, point.X, 5)
+		for j := 1; j <= 5; j++ {
+			if seen[hash[:j]] {
+				count++
+			}
+		}
+		expect(t, count == 1)
+	}
+
+	// fixed precision
+	fixed := Cover(poly, 4, 4)
+	for _, cell := range fixed {
+		expect(t, len(cell.Hash) == 4)
+	}
+	expect(t, len(fixed) < len(cells))
+
+	// a point is covered by the single cell that holds it
+	cells = Cover(geojson.NewPoint(geometry.Point{X: 10.40744, Y: 57.64911}),
+		3, 7)
+	expect(t, len(cells) >= 1)
+	var found bool
+	for _, cell := range cells {
+		expect(t, !cell.Interior && len(cell.Hash) == 7)
+		if cell.Hash == "u4pruyd" {
+			found = true
+		}
+	}
+	expect(t, found)
+
+	// empty objects
+	expect(t, len(Cover(geojson.NewMultiPoint(nil), 1, 5)) == 0)
+}
@@ -0,0 +1,185 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import "math"
+
+// Matrix is a 2D affine transformation matrix, where a point is transformed
+// as:
+//
+//	x' = m[0]*x + m[1]*y + m[2]
+//	y' = m[3]*x + m[4]*y + m[5]
+type Matrix [6]float64
+
+// IdentityMatrix is the matrix that does not change a point
+var IdentityMatrix = Matrix{1, 0, 0, 0, 1, 0}
+
+// TranslateMatrix returns a matrix that moves a point by delta
+func TranslateMatrix(deltaX, deltaY float64) Matrix {
+	return Matrix{1, 0, deltaX, 0, 1, deltaY}
+}
+
+// ScaleMatrix returns a matrix that scales a point away from an origin
+func ScaleMatrix(scaleX, scaleY float64, origin Point) Matrix {
+	return aroundOrigin(Matrix{scaleX, 0, 0, 0, scaleY, 0}, origin)
+}
+
+// RotateMatrix returns a matrix that rotates a point counter-clockwise
+// about an origin.
+func RotateMatrix(degrees float64, origin Point) Matrix {
+	sin, cos := math.Sincos(degrees * math.Pi / 180)
+	return aroundOrigin(Matrix{cos, -sin, 0, sin, cos, 0}, origin)
+}
+
+// SkewMatrix returns a matrix that shears a point along the x and y axes
+// about an origin. The angles are in degrees.
+func SkewMatrix(degreesX, degreesY float64, origin Point) Matrix {
+	tanX := math.Tan(degreesX * math.Pi / 180)
+	tanY := math.Tan(degreesY * math.Pi / 180)
+	return aroundOrigin(Matrix{1, tanX, 0, tanY, 1, 0}, origin)
+}
+
+func aroundOrigin(m Matrix, origin Point) Matrix {
+	return TranslateMatrix(origin.X, origin.Y).
+		Multiply(m).
+		Multiply(TranslateMatrix(-origin.X, -origin.Y))
+}
+
+// Multiply returns the matrix that transforms a point by n and then by m
+func (m Matrix) Multiply(n Matrix) Matrix {
+	return Matrix{
+		m[0]*n[0] + m[1]*n[3],
+		m[0]*n[1] + m[1]*n[4],
+		m[0]*n[2] + m[1]*n[5] + m[2],
+		m[3]*n[0] + m[4]*n[3],
+		m[3]*n[1] + m[4]*n[4],
+		m[3]*n[2] + m[4]*n[5] + m[5],
+	}
+}
+
+// Transform returns the transformed point
+func (m Matrix) Transform(point Point) Point {
+	return Point{
+		X: m[0]*point.X + m[1]*point.Y + m[2],
+		Y: m[3]*point.X + m[4]*point.Y + m[5],
+	}
+}
+
+// Affine transforms the point by a matrix
+func (point Point) Affine(m Matrix) Point {
+	return m.Transform(point)
+}
+
+// Scale scales the point away from an origin
+func (point Point) Scale(scaleX, scaleY float64, origin Point) Point {
+	return point.Affine(ScaleMatrix(scaleX, scaleY, origin))
+}
+
+// Rotate rotates the point counter-clockwise about an origin
+func (point Point) Rotate(degrees float64, origin Point) Point {
+	return point.Affine(RotateMatrix(degrees, origin))
+}
+
+// Skew shears the point about an origin
+func (point Point) Skew(degreesX, degreesY float64, origin Point) Point {
+	return point.Affine(SkewMatrix(degreesX, degreesY, origin))
+}
+
+// Affine transforms the rectangle by a matrix. Returns a new polygon,
+// because the result may not be aligned to the axes.
+func (rect Rect) Affine(m Matrix) *Poly {
+	return NewPoly(seriesCopyPoints(rect), nil, nil).Affine(m)
+}
+
+// Scale scales the rectangle away from an origin. Returns a new rectangle.
+func (rect Rect) Scale(scaleX, scaleY float64, origin Point) Rect {
+	m := ScaleMatrix(scaleX, scaleY, origin)
+	a, b := m.Transform(rect.Min), m.Transform(rect.Max)
+	return Rect{
+		Min: Point{X: math.Min(a.X, b.X), Y: math.Min(a.Y, b.Y)},
+		Max: Point{X: math.Max(a.X, b.X), Y: math.Max(a.Y, b.Y)},
+	}
+}
+
+// Rotate rotates the rectangle counter-clockwise about an origin. Returns a
+// new polygon.
+func (rect Rect) Rotate(degrees float64, origin Point) *Poly {
+	return rect.Affine(RotateMatrix(degrees, origin))
+}
+
+// Skew shears the rectangle about an origin. Returns a new polygon.
+func (rect Rect) Skew(degreesX, degreesY float64, origin Point) *Poly {
+	return rect.Affine(SkewMatrix(degreesX, degreesY, origin))
+}
+
+// Affine transforms the line by a matrix. Returns a new line
+func (line *Line) Affine(m Matrix) *Line {
+	if line == nil {
+		return nil
+	}
+	nline := new(Line)
+	nline.baseSeries = *line.baseSeries.Affine(m).(*baseSeries)
+	return nline
+}
+
+// Scale scales the line away from an origin. Returns a new line
+func (line *Line) Scale(scaleX, scaleY float64, origin Point) *Line {
+	return line.Affine(ScaleMatrix(scaleX, scaleY, origin))
+}
+
+// Rotate rotates the line counter-clockwise about an origin. Returns a new
+// line
+func (line *Line) Rotate(degrees float64, origin Point) *Line {
+	return line.Affine(RotateMatrix(degrees, origin))
+}
+
+// Skew shears the line about an origin. Returns a new line
+func (line *Line) Skew(degreesX, degreesY float64, origin Point) *Line {
+	return line.Affine(SkewMatrix(degreesX, degreesY, origin))
+}
+
+// Affine transforms the polygon by a matrix. Returns a new polygon
+func (poly *Poly) Affine(m Matrix) *Poly {
+	if poly == nil {
+		return nil
+	}
+	if poly.Exterior == nil {
+		return new(Poly)
+	}
+	npoly := new(Poly)
+	npoly.Exterior = ringAffine(poly.Exterior, m)
+	if len(poly.Holes) > 0 {
+		npoly.Holes = make([]Ring, len(poly.Holes))
+		for i, hole := range poly.Holes {
+			npoly.Holes[i] = ringAffine(hole, m)
+		}
+	}
+	return npoly
+}
+
+func ringAffine(ring Ring, m Matrix) Ring {
+	if series, ok := ring.(*baseSeries); ok {
+		return Ring(series.Affine(m))
+	}
+	nseries := makeSeries(seriesCopyPoints(ring), false, true,
+		DefaultIndexOptions)
+	return Ring(nseries.Affine(m))
+}
+
+// Scale scales the polygon away from an origin. Returns a new polygon
+func (poly *Poly) Scale(scaleX, scaleY float64, origin Point) *Poly {
+	return poly.Affine(ScaleMatrix(scaleX, scaleY, origin))
+}
+
+// Rotate rotates the polygon counter-clockwise about an origin. Returns a
+// new polygon
+func (poly *Poly) Rotate(degrees float64, origin Point) *Poly {
+	return poly.Affine(RotateMatrix(degrees, origin))
+}
+
+// Skew shears the polygon about an origin. Returns a new polygon
+func (poly *Poly) Skew(degreesX, degreesY float64, origin Point) *Poly {
+	return poly.Affine(SkewMatrix(degreesX, degreesY, origin))
+}
@@ -0,0 +1,93 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"math"
+	"testing"
+)
+
+func near(a, b Point) bool {
+	return math.Abs(a.X-b.X) < 1e-9 && math.Abs(a.Y-b.Y) < 1e-9
+}
+
+func TestMatrix(t *testing.T) {
+	p := P(3, 4)
+	expect(t, IdentityMatrix.Transform(p) == p)
+	expect(t, TranslateMatrix(7, 8).Transform(p) == p.Move(7, 8))
+	expect(t, ScaleMatrix(2, 3, P(1, 1)).Transform(p) == P(5, 10))
+	expect(t, near(RotateMatrix(90, P(1, 1)).Transform(p), P(-2, 3)))
+	expect(t, near(SkewMatrix(45, 0, P(0, 0)).Transform(p), P(7, 4)))
+	expect(t, near(SkewMatrix(0, 45, P(0, 0)).Transform(p), P(3, 7)))
+
+	// rotate, then move
+	m := TranslateMatrix(10, 0).Multiply(RotateMatrix(180, P(0, 0)))
+	expect(t, near(m.Transform(p), P(7, -4)))
+	expect(t, IdentityMatrix.Multiply(m) == m && m.Multiply(IdentityMatrix) == m)
+}
+
+func TestPointAffine(t *testing.T) {
+	p := P(3, 4)
+	expect(t, p.Scale(2, 2, P(0, 0)) == P(6, 8))
+	expect(t, near(p.Rotate(-90, P(0, 0)), P(4, -3)))
+	expect(t, near(p.Skew(45, 0, P(0, 4)), P(3, 4)))
+	expect(t, p.Affine(Matrix{0, 1, 0, 1, 0, 0}) == P(4, 3))
+}
+
+func TestRectAffine(t *testing.T) {
+	rect := R(0, 0, 2, 1)
+	expect(t, rect.Scale(2, -1, P(0, 0)) == R(0, -1, 4, 0))
+	poly := rect.Rotate(90, P(0, 0))
+	expect(t, near(poly.Exterior.PointAt(1), P(0, 2)))
+	expect(t, near(poly.Exterior.PointAt(2), P(-1, 2)))
+	expect(t, poly.ContainsPoint(P(-0.5, 1)))
+	poly = rect.Skew(45, 0, P(0, 0))
+	expect(t, near(poly.Exterior.PointAt(2), P(3, 1)))
+	expect(t, poly.ContainsPoint(P(2.5, 0.75)))
+	expect(t, !poly.ContainsPoint(P(0.25, 0.75)))
+}
+
+func TestLineAffine(t *testing.T) {
+	ln1 := L(P(0, 1), P(2, 3), P(4, 5))
+	ln2 := ln1.Rotate(90, P(0, 0))
+	expect(t, ln1.NumPoints() == ln2.NumPoints())
+	for i := 0; i < ln2.NumPoints(); i++ {
+		expect(t, near(ln2.PointAt(i), ln1.PointAt(i).Rotate(90, P(0, 0))))
+	}
+	expect(t, ln1.Scale(2, 2, P(0, 0)).PointAt(2) == P(8, 10))
+	expect(t, near(ln1.Skew(0, 45, P(0, 0)).PointAt(2), P(4, 9)))
+
+	var line *Line
+	expect(t, line.Affine(IdentityMatrix) == nil)
+	expect(t, (&Line{}).Affine(IdentityMatrix) != nil)
+}
+
+func TestPolyAffine(t *testing.T) {
+	exterior := []Point{P(0, 0), P(4, 0), P(4, 4), P(0, 4), P(0, 0)}
+	hole := []Point{P(1, 1), P(3, 1), P(3, 3), P(1, 3), P(1, 1)}
+	poly := NewPoly(exterior, [][]Point{hole}, &IndexOptions{
+		Kind: RTree, MinPoints: 1,
+	})
+	rotated := poly.Rotate(45, P(2, 2))
+	expect(t, len(rotated.Holes) == 1)
+	expect(t, rotated.Exterior.Index() != nil)
+	expect(t, near(rotated.Exterior.PointAt(0), P(2, 2-math.Sqrt(8))))
+	expect(t, rotated.ContainsPoint(P(2, -0.5)))
+	expect(t, !rotated.ContainsPoint(P(2, 2)))
+	expect(t, !rotated.ContainsPoint(P(0, 0)))
+
+	// mirrored polygons wind the other way
+	mirrored := poly.Scale(-1, 1, P(0, 0))
+	expect(t, mirrored.Exterior.Clockwise() != poly.Exterior.Clockwise())
+	expect(t, mirrored.ContainsPoint(P(-0.5, 0.5)))
+
+	skewed := poly.Skew(45, 0, P(0, 0))
+	expect(t, skewed.ContainsPoint(P(4.5, 0.5)))
+	expect(t, skewed.ContainsPoint(P(7.5, 3.5)))
+
+	var npoly *Poly
+	expect(t, npoly.Affine(IdentityMatrix) == nil)
+	expect(t, (&Poly{}).Affine(IdentityMatrix) != nil)
+}
@@ -128,6 +128,37 @@ func (series *baseSeries) Move(deltaX, deltaY float64) Series {
 	return &nseries
 }
 
+// Affine transforms the series by a matrix. Returns a new series
+func (series *baseSeries) Affine(m Matrix) Series {
+	points := make([]Point, len(series.points))
+	for i := 0; i < len(series.points); i++ {
+		points[i] = m.Transform(series.points[i])
+	}
+	nseries := makeSeries(points, false, series.closed, nil)
+	nseries.indexKind = series.indexKind
+	if series.Index() != nil {
+		nseries.buildIndex()
+	}
+	return &nseries
+}
+
+// Scale scales the series away from an origin. Returns a new series
+func (series *baseSeries) Scale(scaleX, scaleY float64, origin Point) Series {
+	return series.Affine(ScaleMatrix(scaleX, scaleY, origin))
+}
+
+// Rotate rotates the series counter-clockwise about an origin. Returns a new
+// series
+func (series *baseSeries) Rotate(degrees float64, origin Point) Series {
+	return series.Affine(RotateMatrix(degrees, origin))
+}
+
+// Skew shears the series about an origin. Returns a new series
+func (series *baseSeries) Skew(degreesX, degreesY float64, origin Point,
+) Series {
+	return series.Affine(SkewMatrix(degreesX, degreesY, origin))
+}
+
 // Empty returns true if the series does not take up space.
 func (series *baseSeries) Empty() bool {
 	if series == nil {
@@ -0,0 +1,229 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package gpx
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strconv"
+
+	"github.com/tidwall/geojson"
+)
+
+type gpxFile struct {
+	Waypoints []point `xml:"wpt"`
+	Routes    []route `xml:"rte"`
+	Tracks    []track `xml:"trk"`
+}
+
+type point struct {
+	Lat  float64  `xml:"lat,attr"`
+	Lon  float64  `xml:"lon,attr"`
+	Ele  *float64 `xml:"ele"`
+	Time string   `xml:"time"`
+	Name string   `xml:"name"`
+	Desc string   `xml:"desc"`
+	Sym  string   `xml:"sym"`
+	Type string   `xml:"type"`
+}
+
+type route struct {
+	Name   string  `xml:"name"`
+	Desc   string  `xml:"desc"`
+	Points []point `xml:"rtept"`
+}
+
+type track struct {
+	Name     string `xml:"name"`
+	Desc     string `xml:"desc"`
+	Segments []struct {
+		Points []point `xml:"trkpt"`
+	} `xml:"trkseg"`
+}
+
+// Decode returns the waypoints, routes, and tracks of a GPX document as a
+// FeatureCollection, in that order. Waypoints become Points, routes become
+// LineStrings, and tracks become LineStrings, or MultiLineStrings when they
+// have more than one segment. Routes and track segments with fewer than two
+// points are left out, and so are tracks without segments. Elevations become
+// Z values. The "gpxType"
+// property is "wpt", "rte", or "trk", and the name, desc, sym, and type
+// elements become properties with the same names. The time of a waypoint is
+// the "time" property, and the times of the points of a route or track are
+// the "times" property.
+func Decode(data []byte, opts *geojson.ParseOptions,
+) (*geojson.FeatureCollection, error) {
+	var file gpxFile
+	if err := xml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	var features [][]byte
+	for _, wpt := range file.Waypoints {
+		geom := []byte(`{"type":"Point","coordinates":`)
+		geom = appendPosition(geom, wpt, wpt.Ele != nil)
+		geom = append(geom, '}')
+		var p props
+		p.add("gpxType", "wpt")
+		p.add("name", wpt.Name)
+		p.add("desc", wpt.Desc)
+		p.add("sym", wpt.Sym)
+		p.add("type", wpt.Type)
+		p.add("time", wpt.Time)
+		features = append(features, p.appendFeature(nil, geom))
+	}
+	for _, rte := range file.Routes {
+		if len(rte.Points) < 2 {
+			continue
+		}
+		geom := []byte(`{"type":"LineString","coordinates":`)
+		geom = appendPositions(geom, rte.Points, hasEle(rte.Points))
+		geom = append(geom, '}')
+		var p props
+		p.add("gpxType", "rte")
+		p.add("name", rte.Name)
+		p.add("desc", rte.Desc)
+		p.addTimes(rte.Points)
+		features = append(features, p.appendFeature(nil, geom))
+	}
+	for _, trk := range file.Tracks {
+		var segs [][]point
+		var all []point
+		for _, seg := range trk.Segments {
+			if len(seg.Points) < 2 {
+				continue
+			}
+			segs = append(segs, seg.Points)
+			all = append(all, seg.Points...)
+		}
+		if len(segs) == 0 {
+			continue
+		}
+		ele := hasEle(all)
+		var geom []byte
+		if len(segs) == 1 {
+			geom = []byte(`{"type":"LineString","coordinates":`)
+			geom = appendPositions(geom, segs[0], ele)
+		} else {
+			geom = []byte(`{"type":"MultiLineString","coordinates":[`)
+			for i, seg := range segs {
+				if i > 0 {
+					geom = append(geom, ',')
+				}
+				geom = appendPositions(geom, seg, ele)
+			}
+			geom = append(geom, ']')
+		}
+		geom = append(geom, '}')
+		var p props
+		p.add("gpxType", "trk")
+		p.add("name", trk.Name)
+		p.add("desc", trk.Desc)
+		p.addTimes(all)
+		features = append(features, p.appendFeature(nil, geom))
+	}
+	dst := []byte(`{"type":"FeatureCollection","features":[`)
+	for i, feature := range features {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = append(dst, feature...)
+	}
+	dst = append(dst, "]}"...)
+	obj, err := geojson.Parse(string(dst), geojson.WithoutShapeTypes(opts))
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*geojson.FeatureCollection), nil
+}
+
+func hasEle(points []point) bool {
+	for _, pt := range points {
+		if pt.Ele != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// appendPosition appends a point as a position, with an elevation when ele
+// is true. A missing elevation is zero.
+func appendPosition(dst []byte, pt point, ele bool) []byte {
+	dst = append(dst, '[')
+	dst = strconv.AppendFloat(dst, pt.Lon, 'f', -1, 64)
+	dst = append(dst, ',')
+	dst = strconv.AppendFloat(dst, pt.Lat, 'f', -1, 64)
+	if ele {
+		var z float64
+		if pt.Ele != nil {
+			z = *pt.Ele
+		}
+		dst = append(dst, ',')
+		dst = strconv.AppendFloat(dst, z, 'f', -1, 64)
+	}
+	return append(dst, ']')
+}
+
+func appendPositions(dst []byte, points []point, ele bool) []byte {
+	dst = append(dst, '[')
+	for i, pt := range points {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = appendPosition(dst, pt, ele)
+	}
+	return append(dst, ']')
+}
+
+// props are the json properties of a feature
+type props []byte
+
+// add adds a string property, unless it's empty
+func (p *props) add(key, value string) {
+	if value == "" {
+		return
+	}
+	if len(*p) > 0 {
+		*p = append(*p, ',')
+	}
+	k, _ := json.Marshal(key)
+	v, _ := json.Marshal(value)
+	*p = append(append(append(*p, k...), ':'), v...)
+}
+
+// addTimes adds the times of the points, unless none of the points have a
+// time.
+func (p *props) addTimes(points []point) {
+	var has bool
+	for _, pt := range points {
+		has = has || pt.Time != ""
+	}
+	if !has {
+		return
+	}
+	if len(*p) > 0 {
+		*p = append(*p, ',')
+	}
+	*p = append(*p, `"times":[`...)
+	for i, pt := range points {
+		if i > 0 {
+			*p = append(*p, ',')
+		}
+		if pt.Time == "" {
+			*p = append(*p, "null"...)
+		} else {
+			v, _ := json.Marshal(pt.Time)
+			*p = append(*p, v...)
+		}
+	}
+	*p = append(*p, ']')
+}
+
+func (p props) appendFeature(dst, geom []byte) []byte {
+	dst = append(dst, `{"type":"Feature","geometry":`...)
+	dst = append(dst, geom...)
+	dst = append(dst, `,"properties":{`...)
+	dst = append(dst, p...)
+	return append(dst, "}}"...)
+}
@@ -0,0 +1,164 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package gpx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strconv"
+
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/gjson"
+)
+
+// Encode returns a GPX document for the features of a FeatureCollection, or
+// for any other single object. Points and MultiPoints become waypoints,
+// LineStrings become tracks, or routes when the "gpxType" property is "rte",
+// and MultiLineStrings become tracks with a segment for each line. The
+// children of a GeometryCollection are written with the properties of their
+// feature, and other geometries are left out. Z values become elevations,
+// and the "time" and "times" properties become the times of the points.
+func Encode(obj geojson.Object) []byte {
+	var e encoder
+	if fc, ok := obj.(*geojson.FeatureCollection); ok {
+		for _, child := range fc.Children() {
+			e.feature(child)
+		}
+	} else {
+		e.feature(obj)
+	}
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<gpx version="1.1" creator="geojson" ` +
+		`xmlns="http://www.topografix.com/GPX/1/1">`)
+	// waypoints come before routes, which come before tracks
+	buf.Write(e.wpts.Bytes())
+	buf.Write(e.rtes.Bytes())
+	buf.Write(e.trks.Bytes())
+	buf.WriteString("</gpx>\n")
+	return buf.Bytes()
+}
+
+type encoder struct {
+	wpts, rtes, trks bytes.Buffer
+}
+
+func (e *encoder) feature(obj geojson.Object) {
+	var members string
+	if f, ok := obj.(*geojson.Feature); ok {
+		members = f.Members()
+		obj = f.Base()
+	}
+	e.geometry(gjson.Parse(obj.JSON()), gjson.Get(members, "properties"))
+}
+
+func (e *encoder) geometry(g, props gjson.Result) {
+	coords := g.Get("coordinates")
+	times := props.Get("times").Array()
+	switch g.Get("type").String() {
+	case "Point":
+		e.waypoint(coords, props, props.Get("time"))
+	case "MultiPoint":
+		for _, pos := range coords.Array() {
+			e.waypoint(pos, props, props.Get("time"))
+		}
+	case "LineString":
+		if props.Get("gpxType").String() == "rte" {
+			e.rtes.WriteString("<rte>")
+			writeNames(&e.rtes, props)
+			writePoints(&e.rtes, "rtept", coords.Array(), times)
+			e.rtes.WriteString("</rte>")
+		} else {
+			e.trks.WriteString("<trk>")
+			writeNames(&e.trks, props)
+			e.trks.WriteString("<trkseg>")
+			writePoints(&e.trks, "trkpt", coords.Array(), times)
+			e.trks.WriteString("</trkseg></trk>")
+		}
+	case "MultiLineString":
+		e.trks.WriteString("<trk>")
+		writeNames(&e.trks, props)
+		for _, line := range coords.Array() {
+			positions := line.Array()
+			e.trks.WriteString("<trkseg>")
+			writePoints(&e.trks, "trkpt", positions, times)
+			e.trks.WriteString("</trkseg>")
+			// the times of the next segment follow the times of this one
+			if len(times) > len(positions) {
+				times = times[len(positions):]
+			} else {
+				times = nil
+			}
+		}
+		e.trks.WriteString("</trk>")
+	case "GeometryCollection":
+		for _, child := range g.Get("geometries").Array() {
+			e.geometry(child, props)
+		}
+	case "Feature":
+		e.geometry(g.Get("geometry"), props)
+	}
+}
+
+func writeText(buf *bytes.Buffer, elem string, value gjson.Result) {
+	if !value.Exists() || value.Type == gjson.Null {
+		return
+	}
+	buf.WriteString("<" + elem + ">")
+	xml.EscapeText(buf, []byte(value.String()))
+	buf.WriteString("</" + elem + ">")
+}
+
+func writeNames(buf *bytes.Buffer, props gjson.Result) {
+	writeText(buf, "name", props.Get("name"))
+	writeText(buf, "desc", props.Get("desc"))
+}
+
+func (e *encoder) waypoint(pos, props, time gjson.Result) {
+	writePoint(&e.wpts, "wpt", pos, time, func() {
+		writeNames(&e.wpts, props)
+		writeText(&e.wpts, "sym", props.Get("sym"))
+		writeText(&e.wpts, "type", props.Get("type"))
+	})
+}
+
+func writePoints(buf *bytes.Buffer, elem string, positions,
+	times []gjson.Result,
+) {
+	for i, pos := range positions {
+		var time gjson.Result
+		if i < len(times) {
+			time = times[i]
+		}
+		writePoint(buf, elem, pos, time, nil)
+	}
+}
+
+// writePoint writes a point element, with the elevation and time before any
+// other child elements.
+func writePoint(buf *bytes.Buffer, elem string, pos, time gjson.Result,
+	children func(),
+) {
+	xyz := pos.Array()
+	if len(xyz) < 2 {
+		return
+	}
+	var num []byte
+	buf.WriteString("<" + elem + ` lat="`)
+	buf.Write(strconv.AppendFloat(num[:0], xyz[1].Float(), 'f', -1, 64))
+	buf.WriteString(`" lon="`)
+	buf.Write(strconv.AppendFloat(num[:0], xyz[0].Float(), 'f', -1, 64))
+	buf.WriteString(`">`)
+	if len(xyz) > 2 {
+		buf.WriteString("<ele>")
+		buf.Write(strconv.AppendFloat(num[:0], xyz[2].Float(), 'f', -1, 64))
+		buf.WriteString("</ele>")
+	}
+	writeText(buf, "time", time)
+	if children != nil {
+		children()
+	}
+	buf.WriteString("</" + elem + ">")
+}
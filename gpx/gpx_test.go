@@ -0,0 +1,154 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package gpx
+
+import (
+	"testing"
+
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/geojson/geometry"
+	"github.com/tidwall/gjson"
+)
+
+func expect(t testing.TB, what bool) {
+	t.Helper()
+	if !what {
+		t.Fatal("expection failure")
+	}
+}
+
+const testGPX = `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="handheld" xmlns="http://www.topografix.com/GPX/1/1">
+	<wpt lat="33.5" lon="-112.1">
+		<ele>350.5</ele>
+		<time>2018-06-01T10:00:00Z</time>
+		<name>Camp &amp; water</name>
+		<sym>Campground</sym>
+	</wpt>
+	<wpt lat="33.6" lon="-112.2"><name>Peak</name></wpt>
+	<rte>
+		<name>Approach</name>
+		<rtept lat="33.5" lon="-112.1"/>
+		<rtept lat="33.55" lon="-112.15"/>
+		<rtept lat="33.6" lon="-112.2"/>
+	</rte>
+	<trk>
+		<name>Day 1</name>
+		<desc>Morning hike</desc>
+		<trkseg>
+			<trkpt lat="33.5" lon="-112.1"><ele>350</ele><time>2018-06-01T10:00:00Z</time></trkpt>
+			<trkpt lat="33.51" lon="-112.11"><ele>360</ele><time>2018-06-01T10:05:00Z</time></trkpt>
+		</trkseg>
+		<trkseg>
+			<trkpt lat="33.52" lon="-112.12"><ele>370</ele><time>2018-06-01T11:00:00Z</time></trkpt>
+			<trkpt lat="33.53" lon="-112.13"><time>2018-06-01T11:05:00Z</time></trkpt>
+		</trkseg>
+	</trk>
+	<trk><name>Empty</name></trk>
+</gpx>`
+
+func TestDecode(t *testing.T) {
+	fc, err := Decode([]byte(testGPX), nil)
+	expect(t, err == nil)
+	children := fc.Children()
+	expect(t, len(children) == 4)
+
+	camp := children[0].(*geojson.Feature)
+	expect(t, gjson.Get(camp.JSON(), "geometry.coordinates").Raw ==
+		"[-112.1,33.5,350.5]")
+	props := gjson.Get(camp.Members(), "properties")
+	expect(t, props.Get("gpxType").String() == "wpt")
+	expect(t, props.Get("name").String() == "Camp & water")
+	expect(t, props.Get("sym").String() == "Campground")
+	expect(t, props.Get("time").String() == "2018-06-01T10:00:00Z")
+	peak := children[1].(*geojson.Feature)
+	expect(t, gjson.Get(peak.JSON(), "geometry.coordinates").Raw ==
+		"[-112.2,33.6]")
+
+	rte := children[2].(*geojson.Feature)
+	expect(t, gjson.Get(rte.Members(), "properties.gpxType").String() == "rte")
+	expect(t, !gjson.Get(rte.Members(), "properties.times").Exists())
+	line := rte.Base().(*geojson.LineString)
+	expect(t, line.Base().NumPoints() == 3)
+	expect(t, line.Base().PointAt(1) == geometry.Point{X: -112.15, Y: 33.55})
+
+	trk := children[3].(*geojson.Feature)
+	props = gjson.Get(trk.Members(), "properties")
+	expect(t, props.Get("name").String() == "Day 1")
+	expect(t, props.Get("desc").String() == "Morning hike")
+	expect(t, props.Get("times.#").Int() == 4)
+	expect(t, props.Get("times.2").String() == "2018-06-01T11:00:00Z")
+	mls := trk.Base().(*geojson.MultiLineString)
+	expect(t, len(mls.Children()) == 2)
+	// elevations are Z values, and a missing elevation is zero
+	expect(t, gjson.Get(trk.JSON(), "geometry.coordinates.1.1").Raw ==
+		"[-112.13,33.53,0]")
+
+	_, err = Decode([]byte(`<gpx><wpt lat="x"`), nil)
+	expect(t, err != nil)
+
+	// segments and routes with fewer than two points are left out
+	fc, err = Decode([]byte(`<gpx>
+		<wpt lat="1" lon="2"/>
+		<rte><rtept lat="1" lon="2"/></rte>
+		<trk><trkseg/><trkseg><trkpt lat="1" lon="2"/></trkseg></trk>
+		<trk>
+			<trkseg><trkpt lat="1" lon="2"/></trkseg>
+			<trkseg>
+				<trkpt lat="3" lon="4"><time>a</time></trkpt>
+				<trkpt lat="5" lon="6"><time>b</time></trkpt>
+			</trkseg>
+		</trk>
+	</gpx>`), nil)
+	expect(t, err == nil)
+	children = fc.Children()
+	expect(t, len(children) == 2)
+	trk = children[1].(*geojson.Feature)
+	expect(t, gjson.Get(trk.JSON(), "geometry").Raw ==
+		`{"type":"LineString","coordinates":[[4,3],[6,5]]}`)
+	expect(t, gjson.Get(trk.Members(), "properties.times").Raw == `["a","b"]`)
+
+	// a type of Circle is a property, and not the special Circle syntax
+	for _, typ := range []string{"Circle", "Ellipse", "Sector", "Annulus"} {
+		fc, err = Decode([]byte(`<gpx><wpt lat="1" lon="2"><name>x</name>`+
+			`<type>`+typ+`</type></wpt></gpx>`), nil)
+		expect(t, err == nil && len(fc.Children()) == 1)
+		wpt, ok := fc.Children()[0].(*geojson.Feature)
+		expect(t, ok)
+		expect(t, gjson.Get(wpt.Members(), "properties.type").String() == typ)
+		expect(t, gjson.Get(wpt.Members(), "properties.name").String() == "x")
+	}
+}
+
+func TestEncode(t *testing.T) {
+	fc, err := Decode([]byte(testGPX), nil)
+	expect(t, err == nil)
+	fc2, err := Decode(Encode(fc), nil)
+	expect(t, err == nil)
+	expect(t, fc2.JSON() == fc.JSON())
+
+	// plain geometries and collections
+	obj := geojson.NewFeatureCollection([]geojson.Object{
+		geojson.NewLineString(geometry.NewLine([]geometry.Point{
+			{X: 1, Y: 2}, {X: 3, Y: 4},
+		}, nil)),
+		geojson.NewFeature(geojson.NewGeometryCollection([]geojson.Object{
+			geojson.NewMultiPoint([]geometry.Point{{X: 5, Y: 6}, {X: 7, Y: 8}}),
+			geojson.NewPolygon(geometry.NewPoly([]geometry.Point{
+				{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 0},
+			}, nil, nil)),
+		}), `{"properties":{"name":"well"}}`),
+	})
+	fc, err = Decode(Encode(obj), nil)
+	expect(t, err == nil)
+	children := fc.Children()
+	expect(t, len(children) == 3)
+	// waypoints come first
+	expect(t, gjson.Get(children[0].(*geojson.Feature).Members(),
+		"properties.name").String() == "well")
+	expect(t, children[1].Center() == geometry.Point{X: 7, Y: 8})
+	_, ok := children[2].(*geojson.Feature).Base().(*geojson.LineString)
+	expect(t, ok)
+}
@@ -0,0 +1,113 @@
+package geojson
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/tidwall/geojson/geometry"
+	"github.com/tidwall/rbang"
+)
+
+// JoinOptions are the options for Join
+type JoinOptions struct {
+	// Parallel is the number of goroutines that find the matches of the
+	// children of the left collection. The default of 0 finds them on the
+	// calling goroutine.
+	Parallel int
+}
+
+// DefaultJoinOptions are the default options for Join
+var DefaultJoinOptions = &JoinOptions{
+	Parallel: 0,
+}
+
+// Join calls iter for each pair of a child of the left collection and a
+// child of the right collection, where the left child has the relationship
+// of the predicate with the right child, such as being within it. The
+// Target of the predicate is not used, and a nil predicate is
+// PredicateIntersects.
+//
+// The matches of each left child are found through the spatial index of the
+// right collection, and a temporary index is built when it doesn't have one.
+// Returning false from iter stops the join. With parallel goroutines, iter
+// is called from those goroutines, one call at a time, and the pairs are in
+// no particular order.
+func Join(left, right Collection, pred *Predicate, opts *JoinOptions,
+	iter func(left, right Object) bool,
+) {
+	if opts == nil {
+		opts = DefaultJoinOptions
+	}
+	if pred == nil {
+		pred = &Predicate{Op: PredicateIntersects}
+	}
+	search := right.Search
+	if !right.Indexed() {
+		tree := new(rbang.RTree)
+		for _, child := range right.Children() {
+			if child.Empty() {
+				continue
+			}
+			rect := child.Rect()
+			tree.Insert(
+				[2]float64{rect.Min.X, rect.Min.Y},
+				[2]float64{rect.Max.X, rect.Max.Y},
+				child,
+			)
+		}
+		search = func(rect geometry.Rect, iter func(child Object) bool) {
+			tree.Search(
+				[2]float64{rect.Min.X, rect.Min.Y},
+				[2]float64{rect.Max.X, rect.Max.Y},
+				func(_, _ [2]float64, value interface{}) bool {
+					return iter(value.(Object))
+				},
+			)
+		}
+	}
+	var mu sync.Mutex
+	var stopped int32
+	probe := func(child Object) {
+		if child.Empty() {
+			return
+		}
+		search(pred.searchRect(child), func(match Object) bool {
+			if !pred.match(child, match) {
+				return true
+			}
+			mu.Lock()
+			ok := atomic.LoadInt32(&stopped) == 0 && iter(child, match)
+			if !ok {
+				atomic.StoreInt32(&stopped, 1)
+			}
+			mu.Unlock()
+			return ok
+		})
+	}
+	children := left.Children()
+	if opts.Parallel <= 1 {
+		for _, child := range children {
+			if atomic.LoadInt32(&stopped) != 0 {
+				break
+			}
+			probe(child)
+		}
+		return
+	}
+	var wg sync.WaitGroup
+	next := int64(-1)
+	wg.Add(opts.Parallel)
+	for i := 0; i < opts.Parallel; i++ {
+		go func() {
+			defer wg.Done()
+			for atomic.LoadInt32(&stopped) == 0 {
+				j := int(atomic.AddInt64(&next, 1))
+				if j >= len(children) {
+					return
+				}
+				probe(children[j])
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,119 @@
+package geojson
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/tidwall/geojson/geometry"
+)
+
+func TestJoin(t *testing.T) {
+	// listings and the districts that contain them
+	var points, polys []Object
+	for i := 0; i < 500; i++ {
+		points = append(points, NewFeature(PO(rand.Float64()*10,
+			rand.Float64()*10), ""))
+	}
+	for x := 0; x < 10; x++ {
+		for y := 0; y < 10; y++ {
+			x, y := float64(x), float64(y)
+			polys = append(polys, NewPolygon(geometry.NewPoly(
+				[]geometry.Point{P(x, y), P(x+1.5, y), P(x+1.5, y+1.5),
+					P(x, y+1.5), P(x, y)}, nil, nil)))
+		}
+	}
+	listings := NewFeatureCollection(points)
+	districts := NewFeatureCollection(polys)
+	obj, err := Parse(districts.JSON(), &ParseOptions{})
+	expect(t, err == nil)
+	unindexed := obj.(*FeatureCollection)
+	expect(t, listings.Indexed() && districts.Indexed())
+	expect(t, !unindexed.Indexed())
+
+	type pair struct{ a, b int }
+	index := make(map[Object]int)
+	for i, obj := range append(listings.Children(), districts.Children()...) {
+		index[obj] = i
+	}
+	for i, obj := range unindexed.Children() {
+		index[obj] = len(points) + i
+	}
+	sorted := func(pairs []pair) []pair {
+		sort.Slice(pairs, func(i, j int) bool {
+			return pairs[i].a < pairs[j].a ||
+				(pairs[i].a == pairs[j].a && pairs[i].b < pairs[j].b)
+		})
+		return pairs
+	}
+	preds := []*Predicate{
+		{Op: PredicateIntersects},
+		{Op: PredicateWithin},
+		{Op: PredicateContains},
+		{Op: PredicateWithinDistance, Meters: 150000},
+	}
+	for _, pred := range preds {
+		for _, sides := range [][2]*FeatureCollection{
+			{listings, districts}, {listings, unindexed}, {districts, listings},
+		} {
+			left, right := sides[0], sides[1]
+			// the results of a nested loop
+			var expected []pair
+			for _, a := range left.Children() {
+				for _, b := range right.Children() {
+					if pred.match(a, b) {
+						expected = append(expected, pair{index[a], index[b]})
+					}
+				}
+			}
+			// points do not contain polygons
+			none := (pred.Op == PredicateContains && left == listings) ||
+				(pred.Op == PredicateWithin && left == districts)
+			expect(t, (len(expected) == 0) == none)
+			for _, parallel := range []int{0, 4} {
+				var pairs []pair
+				Join(left, right, pred, &JoinOptions{Parallel: parallel},
+					func(a, b Object) bool {
+						pairs = append(pairs, pair{index[a], index[b]})
+						return true
+					})
+				expect(t, len(pairs) == len(expected))
+				pairs, expected = sorted(pairs), sorted(expected)
+				for i := range pairs {
+					expect(t, pairs[i] == expected[i])
+				}
+			}
+		}
+	}
+
+	// stopping early
+	for _, parallel := range []int{0, 8} {
+		var count int
+		Join(listings, districts, preds[0], &JoinOptions{Parallel: parallel},
+			func(a, b Object) bool {
+				count++
+				return count < 10
+			})
+		expect(t, count == 10)
+	}
+	var count int
+	Join(listings, NewFeatureCollection(nil), preds[0], nil,
+		func(a, b Object) bool {
+			count++
+			return true
+		})
+	expect(t, count == 0)
+
+	// a nil predicate is intersects
+	var intersects int
+	Join(listings, districts, preds[0], nil, func(a, b Object) bool {
+		intersects++
+		return true
+	})
+	count = 0
+	Join(listings, districts, nil, nil, func(a, b Object) bool {
+		count++
+		return true
+	})
+	expect(t, count > 0 && count == intersects)
+}
@@ -0,0 +1,298 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package kml
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/geojson"
+)
+
+// ErrInvalidCoordinates is returned when a coordinates element has a tuple
+// that is not "lon,lat" or "lon,lat,alt".
+var ErrInvalidCoordinates = errors.New("invalid coordinates")
+
+type placemark struct {
+	ID           string   `xml:"id,attr"`
+	Name         string   `xml:"name"`
+	Description  string   `xml:"description"`
+	ExtendedData extended `xml:"ExtendedData"`
+	multiGeometry
+}
+
+type multiGeometry struct {
+	Points        []coordinates   `xml:"Point"`
+	LineStrings   []coordinates   `xml:"LineString"`
+	LinearRings   []coordinates   `xml:"LinearRing"`
+	Polygons      []polygon       `xml:"Polygon"`
+	MultiGeometry []multiGeometry `xml:"MultiGeometry"`
+}
+
+type coordinates struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+type polygon struct {
+	Outer coordinates   `xml:"outerBoundaryIs>LinearRing"`
+	Inner []coordinates `xml:"innerBoundaryIs>LinearRing"`
+}
+
+type extended struct {
+	Data []struct {
+		Name  string `xml:"name,attr"`
+		Value string `xml:"value"`
+	} `xml:"Data"`
+	SimpleData []struct {
+		Name  string `xml:"name,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"SchemaData>SimpleData"`
+}
+
+// Decode returns the Placemarks of a KML document as a FeatureCollection.
+// Placemarks are found at any depth, such as in Documents and Folders. The
+// name, description, and ExtendedData of a Placemark become the properties
+// of its Feature, and the id attribute becomes the Feature's id. Altitudes
+// become Z values. Placemarks without a geometry are left out.
+func Decode(data []byte, opts *geojson.ParseOptions,
+) (*geojson.FeatureCollection, error) {
+	dst := []byte(`{"type":"FeatureCollection","features":[`)
+	var count int
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		se, ok := tok.(xml.StartElement)
+		if !ok || se.Name.Local != "Placemark" {
+			continue
+		}
+		var pm placemark
+		if err := dec.DecodeElement(&pm, &se); err != nil {
+			return nil, err
+		}
+		geom, err := pm.multiGeometry.appendJSON(nil, false)
+		if err != nil {
+			return nil, err
+		}
+		if geom == nil {
+			continue
+		}
+		if count > 0 {
+			dst = append(dst, ',')
+		}
+		count++
+		dst = pm.appendFeatureJSON(dst, geom)
+	}
+	dst = append(dst, "]}"...)
+	obj, err := geojson.Parse(string(dst), geojson.WithoutShapeTypes(opts))
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*geojson.FeatureCollection), nil
+}
+
+func appendString(dst []byte, s string) []byte {
+	b, _ := json.Marshal(s)
+	return append(dst, b...)
+}
+
+func (pm *placemark) appendFeatureJSON(dst, geom []byte) []byte {
+	dst = append(dst, `{"type":"Feature","geometry":`...)
+	dst = append(dst, geom...)
+	if pm.ID != "" {
+		dst = append(dst, `,"id":`...)
+		dst = appendString(dst, pm.ID)
+	}
+	dst = append(dst, `,"properties":{`...)
+	var n int
+	prop := func(key, value string) {
+		if n > 0 {
+			dst = append(dst, ',')
+		}
+		n++
+		dst = appendString(dst, key)
+		dst = append(dst, ':')
+		dst = appendString(dst, value)
+	}
+	if pm.Name != "" {
+		prop("name", strings.TrimSpace(pm.Name))
+	}
+	if pm.Description != "" {
+		prop("description", strings.TrimSpace(pm.Description))
+	}
+	for _, data := range pm.ExtendedData.Data {
+		prop(data.Name, data.Value)
+	}
+	for _, data := range pm.ExtendedData.SimpleData {
+		prop(data.Name, data.Value)
+	}
+	return append(dst, "}}"...)
+}
+
+// part is a geometry of a Placemark or MultiGeometry, where json is set
+// for a nested MultiGeometry.
+type part struct {
+	kind   string
+	coords []byte
+	json   []byte
+}
+
+func (p part) appendJSON(dst []byte) []byte {
+	if p.json != nil {
+		return append(dst, p.json...)
+	}
+	dst = append(dst, `{"type":"`...)
+	dst = append(dst, p.kind...)
+	dst = append(dst, `","coordinates":`...)
+	dst = append(dst, p.coords...)
+	return append(dst, '}')
+}
+
+// appendJSON appends the GeoJSON geometry of a Placemark or MultiGeometry,
+// or returns nil when there isn't a geometry. Geometries of one kind become
+// a Multi geometry, and mixed kinds become a GeometryCollection.
+func (mg *multiGeometry) appendJSON(dst []byte, multi bool) ([]byte, error) {
+	var parts []part
+	for _, point := range mg.Points {
+		coords, err := parseCoordinates(point.Coordinates)
+		if err != nil {
+			return nil, err
+		}
+		if len(coords) != 1 {
+			return nil, ErrInvalidCoordinates
+		}
+		parts = append(parts, part{kind: "Point",
+			coords: appendPosition(nil, coords[0])})
+	}
+	for _, line := range mg.LineStrings {
+		coords, err := parseCoordinates(line.Coordinates)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, part{kind: "LineString",
+			coords: appendPositions(nil, coords)})
+	}
+	var polys []polygon
+	for _, ring := range mg.LinearRings {
+		polys = append(polys, polygon{Outer: ring})
+	}
+	for _, poly := range append(polys, mg.Polygons...) {
+		rings := []byte{'['}
+		for i, ring := range append([]coordinates{poly.Outer}, poly.Inner...) {
+			coords, err := parseCoordinates(ring.Coordinates)
+			if err != nil {
+				return nil, err
+			}
+			if i > 0 {
+				rings = append(rings, ',')
+			}
+			rings = appendPositions(rings, coords)
+		}
+		parts = append(parts, part{kind: "Polygon",
+			coords: append(rings, ']')})
+	}
+	for _, child := range mg.MultiGeometry {
+		geom, err := child.appendJSON(nil, true)
+		if err != nil {
+			return nil, err
+		}
+		if geom != nil {
+			parts = append(parts, part{json: geom})
+		}
+	}
+	if len(parts) == 0 {
+		return nil, nil
+	}
+	if len(parts) == 1 && !multi {
+		return parts[0].appendJSON(dst), nil
+	}
+	same := true
+	for _, p := range parts {
+		same = same && p.json == nil && p.kind == parts[0].kind
+	}
+	if same {
+		dst = append(dst, `{"type":"Multi`...)
+		dst = append(dst, parts[0].kind...)
+		dst = append(dst, `","coordinates":[`...)
+		for i, p := range parts {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = append(dst, p.coords...)
+		}
+		return append(dst, "]}"...), nil
+	}
+	dst = append(dst, `{"type":"GeometryCollection","geometries":[`...)
+	for i, p := range parts {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = p.appendJSON(dst)
+	}
+	return append(dst, "]}"...), nil
+}
+
+// parseCoordinates returns the positions of a coordinates element. All
+// positions have an altitude when any of them have one.
+func parseCoordinates(s string) ([][]float64, error) {
+	var coords [][]float64
+	var hasAlt bool
+	for _, tuple := range strings.Fields(s) {
+		parts := strings.Split(tuple, ",")
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, ErrInvalidCoordinates
+		}
+		pos := make([]float64, len(parts))
+		for i, part := range parts {
+			f, err := strconv.ParseFloat(part, 64)
+			if err != nil {
+				return nil, ErrInvalidCoordinates
+			}
+			pos[i] = f
+		}
+		hasAlt = hasAlt || len(pos) == 3
+		coords = append(coords, pos)
+	}
+	if hasAlt {
+		for i, pos := range coords {
+			if len(pos) == 2 {
+				coords[i] = append(pos, 0)
+			}
+		}
+	}
+	return coords, nil
+}
+
+func appendPosition(dst []byte, pos []float64) []byte {
+	dst = append(dst, '[')
+	for i, f := range pos {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = strconv.AppendFloat(dst, f, 'f', -1, 64)
+	}
+	return append(dst, ']')
+}
+
+func appendPositions(dst []byte, coords [][]float64) []byte {
+	dst = append(dst, '[')
+	for i, pos := range coords {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = appendPosition(dst, pos)
+	}
+	return append(dst, ']')
+}
@@ -0,0 +1,171 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package kml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strconv"
+
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/gjson"
+)
+
+// Encode returns a KML document with a Placemark for each feature of a
+// FeatureCollection, or a single Placemark for any other object. The "name"
+// and "description" properties become the name and description of the
+// Placemark, and the other properties become ExtendedData, where values that
+// are not strings are written as JSON. Z values become altitudes.
+func Encode(obj geojson.Object) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<kml xmlns="http://www.opengis.net/kml/2.2"><Document>`)
+	if fc, ok := obj.(*geojson.FeatureCollection); ok {
+		for _, child := range fc.Children() {
+			writePlacemark(&buf, child)
+		}
+	} else {
+		writePlacemark(&buf, obj)
+	}
+	buf.WriteString(`</Document></kml>`)
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+func writeText(buf *bytes.Buffer, s string) {
+	xml.EscapeText(buf, []byte(s))
+}
+
+func writePlacemark(buf *bytes.Buffer, obj geojson.Object) {
+	var members string
+	if f, ok := obj.(*geojson.Feature); ok {
+		members = f.Members()
+		obj = f.Base()
+	}
+	if p, ok := obj.(interface{ Primative() geojson.Object }); ok {
+		obj = p.Primative()
+	}
+	buf.WriteString("<Placemark")
+	if id := gjson.Get(members, "id"); id.Exists() {
+		buf.WriteString(` id="`)
+		writeText(buf, id.String())
+		buf.WriteByte('"')
+	}
+	buf.WriteByte('>')
+	props := gjson.Get(members, "properties")
+	for _, key := range []string{"name", "description"} {
+		if value := props.Get(key); value.Exists() {
+			buf.WriteString("<" + key + ">")
+			writeText(buf, value.String())
+			buf.WriteString("</" + key + ">")
+		}
+	}
+	var hasData bool
+	if props.IsObject() {
+		props.ForEach(func(key, value gjson.Result) bool {
+			if key.String() == "name" || key.String() == "description" {
+				return true
+			}
+			if !hasData {
+				buf.WriteString("<ExtendedData>")
+				hasData = true
+			}
+			buf.WriteString(`<Data name="`)
+			writeText(buf, key.String())
+			buf.WriteString(`"><value>`)
+			if value.Type == gjson.String {
+				writeText(buf, value.String())
+			} else {
+				writeText(buf, value.Raw)
+			}
+			buf.WriteString("</value></Data>")
+			return true
+		})
+	}
+	if hasData {
+		buf.WriteString("</ExtendedData>")
+	}
+	writeGeometry(buf, gjson.Parse(obj.JSON()))
+	buf.WriteString("</Placemark>")
+}
+
+func writeGeometry(buf *bytes.Buffer, g gjson.Result) {
+	coords := g.Get("coordinates")
+	switch g.Get("type").String() {
+	case "Point":
+		writeCoordinates(buf, "Point", []gjson.Result{coords})
+	case "LineString":
+		writeCoordinates(buf, "LineString", coords.Array())
+	case "Polygon":
+		writePolygon(buf, coords)
+	case "MultiPoint":
+		buf.WriteString("<MultiGeometry>")
+		for _, pos := range coords.Array() {
+			writeCoordinates(buf, "Point", []gjson.Result{pos})
+		}
+		buf.WriteString("</MultiGeometry>")
+	case "MultiLineString":
+		buf.WriteString("<MultiGeometry>")
+		for _, line := range coords.Array() {
+			writeCoordinates(buf, "LineString", line.Array())
+		}
+		buf.WriteString("</MultiGeometry>")
+	case "MultiPolygon":
+		buf.WriteString("<MultiGeometry>")
+		for _, poly := range coords.Array() {
+			writePolygon(buf, poly)
+		}
+		buf.WriteString("</MultiGeometry>")
+	case "GeometryCollection":
+		buf.WriteString("<MultiGeometry>")
+		for _, child := range g.Get("geometries").Array() {
+			writeGeometry(buf, child)
+		}
+		buf.WriteString("</MultiGeometry>")
+	case "Feature":
+		writeGeometry(buf, g.Get("geometry"))
+	}
+}
+
+func writePolygon(buf *bytes.Buffer, rings gjson.Result) {
+	buf.WriteString("<Polygon>")
+	for i, ring := range rings.Array() {
+		if i == 0 {
+			buf.WriteString("<outerBoundaryIs>")
+			writeCoordinates(buf, "LinearRing", ring.Array())
+			buf.WriteString("</outerBoundaryIs>")
+		} else {
+			buf.WriteString("<innerBoundaryIs>")
+			writeCoordinates(buf, "LinearRing", ring.Array())
+			buf.WriteString("</innerBoundaryIs>")
+		}
+	}
+	buf.WriteString("</Polygon>")
+}
+
+// writeCoordinates writes an element with the positions as "lon,lat,alt"
+// tuples.
+func writeCoordinates(buf *bytes.Buffer, elem string,
+	positions []gjson.Result,
+) {
+	buf.WriteString("<" + elem + "><coordinates>")
+	var num []byte
+	for i, pos := range positions {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		for j, v := range pos.Array() {
+			if j > 2 {
+				break
+			}
+			if j > 0 {
+				buf.WriteByte(',')
+			}
+			num = strconv.AppendFloat(num[:0], v.Float(), 'f', -1, 64)
+			buf.Write(num)
+		}
+	}
+	buf.WriteString("</coordinates></" + elem + ">")
+}
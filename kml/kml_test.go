@@ -0,0 +1,162 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package kml
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/geojson/geometry"
+	"github.com/tidwall/gjson"
+)
+
+func expect(t testing.TB, what bool) {
+	t.Helper()
+	if !what {
+		t.Fatal("expection failure")
+	}
+}
+
+const testKML = `<?xml version="1.0" encoding="UTF-8"?>
+<kml xmlns="http://www.opengis.net/kml/2.2">
+<Document>
+	<name>Field survey</name>
+	<Folder>
+		<Placemark id="p1">
+			<name>Camp</name>
+			<description><![CDATA[Base <b>camp</b>]]></description>
+			<ExtendedData>
+				<Data name="crew"><value>4</value></Data>
+				<SchemaData schemaUrl="#s"><SimpleData name="zone">A</SimpleData></SchemaData>
+			</ExtendedData>
+			<Point><coordinates>-112.1,33.5,350</coordinates></Point>
+		</Placemark>
+		<Placemark>
+			<name>Trail</name>
+			<LineString><coordinates>
+				-112.1,33.5 -112.2,33.6
+				-112.3,33.5
+			</coordinates></LineString>
+		</Placemark>
+	</Folder>
+	<Placemark>
+		<name>Lake</name>
+		<Polygon>
+			<outerBoundaryIs><LinearRing><coordinates>
+				0,0 10,0 10,10 0,10 0,0
+			</coordinates></LinearRing></outerBoundaryIs>
+			<innerBoundaryIs><LinearRing><coordinates>
+				2,2 4,2 4,4 2,2
+			</coordinates></LinearRing></innerBoundaryIs>
+			<innerBoundaryIs><LinearRing><coordinates>
+				6,6 8,6 8,8 6,6
+			</coordinates></LinearRing></innerBoundaryIs>
+		</Polygon>
+	</Placemark>
+	<Placemark>
+		<name>Wells</name>
+		<MultiGeometry>
+			<Point><coordinates>1,1</coordinates></Point>
+			<Point><coordinates>2,2</coordinates></Point>
+		</MultiGeometry>
+	</Placemark>
+	<Placemark>
+		<name>Site</name>
+		<MultiGeometry>
+			<Point><coordinates>1,1</coordinates></Point>
+			<LineString><coordinates>1,1 2,2</coordinates></LineString>
+			<MultiGeometry>
+				<Point><coordinates>3,3</coordinates></Point>
+			</MultiGeometry>
+		</MultiGeometry>
+	</Placemark>
+	<Placemark><name>Nowhere</name></Placemark>
+</Document>
+</kml>`
+
+func TestDecode(t *testing.T) {
+	fc, err := Decode([]byte(testKML), nil)
+	expect(t, err == nil)
+	children := fc.Children()
+	expect(t, len(children) == 5)
+
+	camp := children[0].(*geojson.Feature)
+	expect(t, gjson.Get(camp.Members(), "id").String() == "p1")
+	props := gjson.Get(camp.Members(), "properties")
+	expect(t, props.Get("name").String() == "Camp")
+	expect(t, props.Get("description").String() == "Base <b>camp</b>")
+	expect(t, props.Get("crew").String() == "4")
+	expect(t, props.Get("zone").String() == "A")
+	expect(t, gjson.Get(camp.JSON(), "geometry.coordinates").Raw ==
+		"[-112.1,33.5,350]")
+
+	trail := children[1].(*geojson.Feature).Base().(*geojson.LineString)
+	expect(t, trail.Base().NumPoints() == 3)
+	expect(t, trail.Base().PointAt(2) == geometry.Point{X: -112.3, Y: 33.5})
+
+	lake := children[2].(*geojson.Feature).Base().(*geojson.Polygon)
+	expect(t, len(lake.Base().Holes) == 2)
+	expect(t, !lake.Contains(geojson.NewPoint(geometry.Point{X: 3, Y: 2.5})))
+	expect(t, lake.Contains(geojson.NewPoint(geometry.Point{X: 5, Y: 5})))
+
+	_, ok := children[3].(*geojson.Feature).Base().(*geojson.MultiPoint)
+	expect(t, ok)
+	gc, ok := children[4].(*geojson.Feature).Base().(*geojson.GeometryCollection)
+	expect(t, ok && len(gc.Children()) == 3)
+	_, ok = gc.Children()[2].(*geojson.MultiPoint)
+	expect(t, ok)
+
+	_, err = Decode([]byte(`<kml><Placemark><Point><coordinates>1</coordinates></Point></Placemark></kml>`), nil)
+	expect(t, err == ErrInvalidCoordinates)
+	_, err = Decode([]byte(`<kml><Placemark>`), nil)
+	expect(t, err != nil)
+	fc, err = Decode([]byte(`<kml></kml>`), nil)
+	expect(t, err == nil && len(fc.Children()) == 0)
+
+	// a type of Circle is a property, and not the special Circle syntax
+	fc, err = Decode([]byte(`<kml><Placemark><ExtendedData>
+		<Data name="type"><value>Circle</value></Data>
+		<Data name="radius"><value>100</value></Data>
+	</ExtendedData><Point><coordinates>1,2</coordinates></Point></Placemark></kml>`), nil)
+	expect(t, err == nil && len(fc.Children()) == 1)
+	circle := fc.Children()[0].(*geojson.Feature)
+	expect(t, gjson.Get(circle.Members(), "properties.type").String() == "Circle")
+	_, ok = circle.Base().(*geojson.Point)
+	expect(t, ok)
+}
+
+func TestEncode(t *testing.T) {
+	fc, err := Decode([]byte(testKML), nil)
+	expect(t, err == nil)
+	data := Encode(fc)
+	fc2, err := Decode(data, nil)
+	expect(t, err == nil)
+	expect(t, fc2.JSON() == fc.JSON())
+
+	// a single object with a shape and properties that are not strings
+	circle := geojson.NewCircle(geometry.Point{X: -112, Y: 33}, 1000, 16)
+	data = Encode(geojson.NewFeature(circle,
+		`{"id":5,"properties":{"name":"a & b","tags":["x","y"],"n":1.5}}`))
+	fc, err = Decode(data, nil)
+	expect(t, err == nil && len(fc.Children()) == 1)
+	f := fc.Children()[0].(*geojson.Feature)
+	expect(t, gjson.Get(f.Members(), "id").String() == "5")
+	expect(t, gjson.Get(f.Members(), "properties.name").String() == "a & b")
+	expect(t, gjson.Get(f.Members(), "properties.tags").String() ==
+		`["x","y"]`)
+	expect(t, gjson.Get(f.Members(), "properties.n").String() == "1.5")
+	poly := f.Base().(*geojson.Polygon)
+	expect(t, poly.NumPoints() == circle.Primative().NumPoints())
+
+	// null properties have no data
+	point := geojson.NewPoint(geometry.Point{X: -112, Y: 33})
+	data = Encode(geojson.NewFeature(point, `{"properties":null}`))
+	expect(t, !bytes.Contains(data, []byte("<ExtendedData>")))
+	fc, err = Decode(data, nil)
+	expect(t, err == nil && len(fc.Children()) == 1)
+	f = fc.Children()[0].(*geojson.Feature)
+	expect(t, len(gjson.Get(f.Members(), "properties").Map()) == 0)
+}
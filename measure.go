@@ -0,0 +1,59 @@
+package geojson
+
+import (
+	"github.com/tidwall/geojson/geo"
+	"github.com/tidwall/geojson/geometry"
+)
+
+// Distance returns the distance in meters between the centers of two
+// objects, measured using the provided model of the earth.
+func Distance(a, b Object, model geo.Model) float64 {
+	ca, cb := a.Center(), b.Center()
+	return model.DistanceTo(ca.Y, ca.X, cb.Y, cb.X)
+}
+
+// Area returns the area in square meters covered by an object, measured
+// using the provided model of the earth. Points and lines have no area.
+func Area(obj Object, model geo.Model) float64 {
+	switch g := obj.(type) {
+	case *Polygon:
+		return polyArea(&g.base, model)
+	case *Rect:
+		return polyArea(&geometry.Poly{Exterior: g.base}, model)
+	case *Circle:
+		return model.CapArea(g.normMeters())
+	case *Feature:
+		return Area(g.base, model)
+	case Collection:
+		var area float64
+		for _, child := range g.Children() {
+			area += Area(child, model)
+		}
+		return area
+	case interface{ Primative() Object }:
+		return Area(g.Primative(), model)
+	}
+	return 0
+}
+
+func polyArea(poly *geometry.Poly, model geo.Model) float64 {
+	area := ringArea(poly.Exterior, model)
+	for _, hole := range poly.Holes {
+		area -= ringArea(hole, model)
+	}
+	if area < 0 {
+		return 0
+	}
+	return area
+}
+
+func ringArea(ring geometry.Ring, model geo.Model) float64 {
+	n := ring.NumPoints()
+	lats := make([]float64, n)
+	lons := make([]float64, n)
+	for i := 0; i < n; i++ {
+		point := ring.PointAt(i)
+		lats[i], lons[i] = point.Y, point.X
+	}
+	return model.RingArea(lats, lons)
+}
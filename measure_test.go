@@ -0,0 +1,51 @@
+package geojson
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tidwall/geojson/geo"
+	"github.com/tidwall/geojson/geometry"
+)
+
+func TestDistance(t *testing.T) {
+	a, b := PO(0, 0), RO(-1, 0, 1, 2)
+	expect(t, Distance(a, b, geo.Spherical) == a.Distance(b))
+	meters, _, _ := geo.InverseWGS84(0, 0, 1, 0)
+	expect(t, Distance(a, b, geo.Ellipsoidal) == meters)
+}
+
+func TestArea(t *testing.T) {
+	near := func(value, expect, epsilon float64) bool {
+		return math.Abs(value-expect) <= epsilon
+	}
+	// a one degree cell at the equator
+	cell := RO(0, 0, 1, 1)
+	sphere := Area(cell, geo.Spherical)
+	wgs84 := Area(cell, geo.Ellipsoidal)
+	expect(t, near(sphere, 12363683990, 1))
+	expect(t, near(wgs84, 12308463894, 1))
+	expect(t, near(Area(PPO([]geometry.Point{{X: 0, Y: 0}, {X: 1, Y: 0},
+		{X: 1, Y: 1}, {X: 0, Y: 1}, {X: 0, Y: 0}}, nil), geo.Ellipsoidal),
+		wgs84, 1e-3))
+
+	// polygon with a hole
+	poly := PPO(
+		[]geometry.Point{{X: 0, Y: 0}, {X: 2, Y: 0}, {X: 2, Y: 2}, {X: 0, Y: 2}, {X: 0, Y: 0}},
+		[][]geometry.Point{{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 1}, {X: 0, Y: 0}}},
+	)
+	full := Area(RO(0, 0, 2, 2), geo.Ellipsoidal)
+	expect(t, near(Area(poly, geo.Ellipsoidal), full-wgs84, 1e-3))
+
+	// collections and features add up their children
+	expect(t, near(Area(NewMultiPolygon([]*geometry.Poly{&poly.base,
+		&poly.base}), geo.Ellipsoidal), 2*(full-wgs84), 1e-3))
+	expect(t, Area(NewFeature(cell, ""), geo.Ellipsoidal) == wgs84)
+
+	// circles use the area of the spherical cap
+	circle := NewCircle(P(0, 0), 1000, 64)
+	expect(t, near(Area(circle, geo.Spherical), math.Pi*1000*1000, 1))
+	expect(t, Area(PO(0, 0), geo.Spherical) == 0)
+	expect(t, Area(LO([]geometry.Point{{X: 0, Y: 0}, {X: 1, Y: 1}}),
+		geo.Spherical) == 0)
+}
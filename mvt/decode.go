@@ -0,0 +1,296 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mvt
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/geojson/geometry"
+	"github.com/tidwall/geojson/tiles"
+)
+
+// Decode returns the layers of a vector tile for the tile at z/x/y. The
+// Features of each layer is a FeatureCollection in lon/lat, and the id and
+// tags of each tile feature become the "id" and "properties" members of a
+// Feature.
+func Decode(data []byte, tile tiles.Tile) ([]Layer, error) {
+	var layers []Layer
+	err := readFields(data, func(field, wire int, v uint64, b []byte) error {
+		if field != 3 || wire != wireBytes {
+			return nil
+		}
+		layer, err := decodeLayer(b, tile)
+		if err != nil {
+			return err
+		}
+		layers = append(layers, layer)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return layers, nil
+}
+
+func decodeLayer(data []byte, tile tiles.Tile) (Layer, error) {
+	var layer Layer
+	var keys []string
+	var values []string
+	var features [][]byte
+	extent := 4096
+	err := readFields(data, func(field, wire int, v uint64, b []byte) error {
+		switch {
+		case field == 1 && wire == wireBytes:
+			layer.Name = string(b)
+		case field == 2 && wire == wireBytes:
+			features = append(features, b)
+		case field == 3 && wire == wireBytes:
+			keys = append(keys, string(b))
+		case field == 4 && wire == wireBytes:
+			value, err := decodeValue(b)
+			if err != nil {
+				return err
+			}
+			values = append(values, value)
+		case field == 5 && wire == wireVarint:
+			extent = int(v)
+		}
+		return nil
+	})
+	if err != nil {
+		return layer, err
+	}
+	if extent <= 0 {
+		return layer, ErrInvalidTile
+	}
+	proj := tileProjection{tile: tile, extent: float64(extent)}
+	var objs []geojson.Object
+	for _, data := range features {
+		obj, err := decodeFeature(data, keys, values, proj)
+		if err != nil {
+			return layer, err
+		}
+		if obj != nil {
+			objs = append(objs, obj)
+		}
+	}
+	layer.Features = geojson.NewFeatureCollection(objs)
+	return layer, nil
+}
+
+// decodeValue returns a Value message as JSON
+func decodeValue(data []byte) (string, error) {
+	value := "null"
+	err := readFields(data, func(field, wire int, v uint64, b []byte) error {
+		switch field {
+		case 1:
+			s, _ := json.Marshal(string(b))
+			value = string(s)
+		case 2:
+			value = jsonFloat(float64(math.Float32frombits(uint32(v))), 32)
+		case 3:
+			value = jsonFloat(math.Float64frombits(v), 64)
+		case 4:
+			value = strconv.FormatInt(int64(v), 10)
+		case 5:
+			value = strconv.FormatUint(v, 10)
+		case 6:
+			value = strconv.FormatInt(int64(v>>1)^-int64(v&1), 10)
+		case 7:
+			value = strconv.FormatBool(v != 0)
+		}
+		return nil
+	})
+	return value, err
+}
+
+func jsonFloat(f float64, bitSize int) string {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "null"
+	}
+	return strconv.FormatFloat(f, 'f', -1, bitSize)
+}
+
+func decodeFeature(data []byte, keys, values []string,
+	proj tileProjection,
+) (geojson.Object, error) {
+	var id uint64
+	var hasID bool
+	var tags, cmds []uint32
+	var geomType uint64
+	err := readFields(data, func(field, wire int, v uint64, b []byte) error {
+		var err error
+		switch {
+		case field == 1 && wire == wireVarint:
+			id, hasID = v, true
+		case field == 2 && wire == wireBytes:
+			tags, err = readPacked(b)
+		case field == 3 && wire == wireVarint:
+			geomType = v
+		case field == 4 && wire == wireBytes:
+			cmds, err = readPacked(b)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	parts, err := decodeGeometry(cmds)
+	if err != nil {
+		return nil, err
+	}
+	var obj geojson.Object
+	switch geomType {
+	case geomPoint:
+		obj = decodePoints(parts, proj)
+	case geomLineString:
+		obj = decodeLines(parts, proj)
+	case geomPolygon:
+		obj = decodePolygons(parts, proj)
+	}
+	if obj == nil {
+		return nil, nil
+	}
+	if len(tags)%2 != 0 {
+		return nil, ErrInvalidTile
+	}
+	var members []byte
+	members = append(members, '{')
+	if hasID {
+		members = append(members, `"id":`...)
+		members = strconv.AppendUint(members, id, 10)
+		members = append(members, ',')
+	}
+	members = append(members, `"properties":{`...)
+	for i := 0; i < len(tags); i += 2 {
+		if int(tags[i]) >= len(keys) || int(tags[i+1]) >= len(values) {
+			return nil, ErrInvalidTile
+		}
+		if i > 0 {
+			members = append(members, ',')
+		}
+		key, _ := json.Marshal(keys[tags[i]])
+		members = append(members, key...)
+		members = append(members, ':')
+		members = append(members, values[tags[i+1]]...)
+	}
+	members = append(members, "}}"...)
+	return geojson.NewFeature(obj, string(members)), nil
+}
+
+// part is a sequence of points from a MoveTo command, in tile coordinates
+type part struct {
+	points []tilePoint
+}
+
+func decodeGeometry(cmds []uint32) ([]part, error) {
+	var parts []part
+	var x, y int32
+	for i := 0; i < len(cmds); {
+		id, count := int(cmds[i]&7), int(cmds[i]>>3)
+		i++
+		switch id {
+		case cmdMoveTo, cmdLineTo:
+			if len(cmds)-i < count*2 || (id == cmdLineTo && len(parts) == 0) {
+				return nil, ErrInvalidTile
+			}
+			for k := 0; k < count; k++ {
+				x += unzigzag(cmds[i])
+				y += unzigzag(cmds[i+1])
+				i += 2
+				if id == cmdMoveTo {
+					parts = append(parts, part{})
+				}
+				p := &parts[len(parts)-1]
+				p.points = append(p.points, tilePoint{x, y})
+			}
+		case cmdClosePath:
+			// rings are always closed
+			if len(parts) == 0 {
+				return nil, ErrInvalidTile
+			}
+		default:
+			return nil, ErrInvalidTile
+		}
+	}
+	return parts, nil
+}
+
+func (proj tileProjection) lonlat(tpoints []tilePoint) []geometry.Point {
+	points := make([]geometry.Point, len(tpoints))
+	for i, p := range tpoints {
+		lat, lon := proj.Inverse(float64(p.x), float64(p.y))
+		points[i] = geometry.Point{X: lon, Y: lat}
+	}
+	return points
+}
+
+func decodePoints(parts []part, proj tileProjection) geojson.Object {
+	var points []geometry.Point
+	for _, part := range parts {
+		points = append(points, proj.lonlat(part.points)...)
+	}
+	switch len(points) {
+	case 0:
+		return nil
+	case 1:
+		return geojson.NewPoint(points[0])
+	}
+	return geojson.NewMultiPoint(points)
+}
+
+func decodeLines(parts []part, proj tileProjection) geojson.Object {
+	var lines []*geometry.Line
+	for _, part := range parts {
+		if len(part.points) >= 2 {
+			lines = append(lines, geometry.NewLine(proj.lonlat(part.points),
+				nil))
+		}
+	}
+	switch len(lines) {
+	case 0:
+		return nil
+	case 1:
+		return geojson.NewLineString(lines[0])
+	}
+	return geojson.NewMultiLineString(lines)
+}
+
+func decodePolygons(parts []part, proj tileProjection) geojson.Object {
+	var polys []*geometry.Poly
+	var exterior []geometry.Point
+	var holes [][]geometry.Point
+	flush := func() {
+		if exterior != nil {
+			polys = append(polys, geometry.NewPoly(exterior, holes, nil))
+		}
+		exterior, holes = nil, nil
+	}
+	for _, part := range parts {
+		area := ringArea(part.points)
+		if len(part.points) < 3 || area == 0 {
+			continue
+		}
+		ring := proj.lonlat(append(part.points, part.points[0]))
+		if area > 0 {
+			// an exterior starts a new polygon
+			flush()
+			exterior = ring
+		} else if exterior != nil {
+			holes = append(holes, ring)
+		}
+	}
+	flush()
+	switch len(polys) {
+	case 0:
+		return nil
+	case 1:
+		return geojson.NewPolygon(polys[0])
+	}
+	return geojson.NewMultiPolygon(polys)
+}
@@ -0,0 +1,347 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mvt
+
+import (
+	"math"
+	"strings"
+
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/geojson/geometry"
+	"github.com/tidwall/geojson/tiles"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/pretty"
+)
+
+// Encode returns a vector tile for the tile at z/x/y that holds the layers.
+// Objects are projected to the tile, clipped to the tile and its buffer, and
+// rounded to the tile's extent. The "id" and "properties" of each feature's
+// members become the id and the tags of the tile feature. Property values
+// that are objects or arrays are stored as JSON strings, and nulls are left
+// out.
+func Encode(tile tiles.Tile, layers []Layer, opts *EncodeOptions) []byte {
+	if opts == nil {
+		opts = DefaultEncodeOptions
+	}
+	extent, buffer := opts.Extent, opts.Buffer
+	if extent <= 0 {
+		extent = 4096
+	}
+	if buffer < 0 {
+		buffer = 0
+	}
+	e := encoder{
+		proj: tileProjection{tile: tile, extent: float64(extent)},
+		bbox: geometry.Rect{
+			Min: geometry.Point{X: float64(-buffer), Y: float64(-buffer)},
+			Max: geometry.Point{X: float64(extent + buffer),
+				Y: float64(extent + buffer)},
+		},
+	}
+	e.clipper = geojson.NewRect(e.bbox)
+	// the clipping area in lon/lat, for skipping objects early
+	maxLat, minLon := e.proj.Inverse(e.bbox.Min.X, e.bbox.Min.Y)
+	minLat, maxLon := e.proj.Inverse(e.bbox.Max.X, e.bbox.Max.Y)
+	e.lonlat = geometry.Rect{
+		Min: geometry.Point{X: minLon, Y: minLat},
+		Max: geometry.Point{X: maxLon, Y: maxLat},
+	}
+	var dst []byte
+	for _, layer := range layers {
+		dst = appendBytesField(dst, 3, e.encodeLayer(layer, extent))
+	}
+	return dst
+}
+
+type encoder struct {
+	proj    tileProjection
+	bbox    geometry.Rect
+	lonlat  geometry.Rect
+	clipper geojson.Object
+}
+
+// layerEncoder holds the key and value tables of a layer
+type layerEncoder struct {
+	keys     []string
+	keyIdx   map[string]uint32
+	values   [][]byte
+	valueIdx map[string]uint32
+	features []byte
+}
+
+func (e *encoder) encodeLayer(layer Layer, extent int) []byte {
+	le := layerEncoder{
+		keyIdx:   make(map[string]uint32),
+		valueIdx: make(map[string]uint32),
+	}
+	if layer.Features != nil {
+		if col, ok := layer.Features.(geojson.Collection); ok {
+			for _, child := range col.Children() {
+				e.encodeFeature(&le, child)
+			}
+		} else {
+			e.encodeFeature(&le, layer.Features)
+		}
+	}
+	var dst []byte
+	dst = appendVarintField(dst, 15, 2)
+	dst = appendBytesField(dst, 1, []byte(layer.Name))
+	dst = append(dst, le.features...)
+	for _, key := range le.keys {
+		dst = appendBytesField(dst, 3, []byte(key))
+	}
+	for _, value := range le.values {
+		dst = appendBytesField(dst, 4, value)
+	}
+	dst = appendVarintField(dst, 5, uint64(extent))
+	return dst
+}
+
+func (e *encoder) encodeFeature(le *layerEncoder, obj geojson.Object) {
+	if obj.Empty() || !obj.Rect().IntersectsRect(e.lonlat) {
+		return
+	}
+	var members string
+	if feature, ok := obj.(*geojson.Feature); ok {
+		members = feature.Members()
+	}
+	obj = geojson.Transform(obj, e.proj, nil)
+	obj = geojson.Clip(obj, e.clipper, nil)
+	var s shapes
+	s.add(obj)
+	var geoms [3][]uint32
+	geoms[geomPoint-1] = s.encodePoints(e.bbox)
+	geoms[geomLineString-1] = s.encodeLines()
+	geoms[geomPolygon-1] = s.encodePolygons()
+	var tags []uint32
+	if len(geoms[0]) > 0 || len(geoms[1]) > 0 || len(geoms[2]) > 0 {
+		tags = le.tags(members)
+	}
+	id := gjson.Get(members, "id")
+	hasID := id.Type == gjson.Number && id.Float() >= 0 &&
+		id.Float() == math.Trunc(id.Float())
+	// a feature with more than one kind of geometry becomes a tile feature
+	// for each kind
+	for i, geom := range geoms {
+		if len(geom) == 0 {
+			continue
+		}
+		var dst []byte
+		if hasID {
+			dst = appendVarintField(dst, 1, id.Uint())
+		}
+		if len(tags) > 0 {
+			dst = appendPackedField(dst, 2, tags)
+		}
+		dst = appendVarintField(dst, 3, uint64(i+1))
+		dst = appendPackedField(dst, 4, geom)
+		le.features = appendBytesField(le.features, 2, dst)
+	}
+}
+
+// tags returns the key and value indexes for the properties in the members
+func (le *layerEncoder) tags(members string) []uint32 {
+	var tags []uint32
+	gjson.Get(members, "properties").ForEach(
+		func(key, val gjson.Result) bool {
+			value := encodeValue(val)
+			if value == nil {
+				return true
+			}
+			k, ok := le.keyIdx[key.String()]
+			if !ok {
+				k = uint32(len(le.keys))
+				le.keys = append(le.keys, key.String())
+				le.keyIdx[key.String()] = k
+			}
+			v, ok := le.valueIdx[string(value)]
+			if !ok {
+				v = uint32(len(le.values))
+				le.values = append(le.values, value)
+				le.valueIdx[string(value)] = v
+			}
+			tags = append(tags, k, v)
+			return true
+		},
+	)
+	return tags
+}
+
+// encodeValue returns a Value message, or nil for nulls
+func encodeValue(val gjson.Result) []byte {
+	switch val.Type {
+	case gjson.String:
+		return appendBytesField(nil, 1, []byte(val.String()))
+	case gjson.True:
+		return appendVarintField(nil, 7, 1)
+	case gjson.False:
+		return appendVarintField(nil, 7, 0)
+	case gjson.Number:
+		f := val.Float()
+		if f == math.Trunc(f) && math.Abs(f) < 1<<63 &&
+			!strings.ContainsAny(val.Raw, ".eE") {
+			if f >= 0 {
+				return appendVarintField(nil, 5, val.Uint())
+			}
+			n := val.Int()
+			return appendVarintField(nil, 6, uint64((n<<1)^(n>>63)))
+		}
+		return appendFixed64Field(nil, 3, math.Float64bits(f))
+	case gjson.JSON:
+		return appendBytesField(nil, 1, pretty.Ugly([]byte(val.Raw)))
+	}
+	return nil
+}
+
+// shapes are the points, lines, and polygon rings of an object, in tile
+// coordinates.
+type shapes struct {
+	points []geometry.Point
+	lines  [][]geometry.Point
+	polys  [][][]geometry.Point
+}
+
+func (s *shapes) add(obj geojson.Object) {
+	switch g := obj.(type) {
+	case *geojson.Point:
+		s.points = append(s.points, g.Base())
+	case *geojson.SimplePoint:
+		s.points = append(s.points, g.Point)
+	case *geojson.LineString:
+		s.lines = append(s.lines, seriesPoints(g.Base()))
+	case *geojson.Polygon:
+		rings := [][]geometry.Point{seriesPoints(g.Base().Exterior)}
+		for _, hole := range g.Base().Holes {
+			rings = append(rings, seriesPoints(hole))
+		}
+		s.polys = append(s.polys, rings)
+	case *geojson.Feature:
+		s.add(g.Base())
+	case geojson.Collection:
+		for _, child := range g.Children() {
+			s.add(child)
+		}
+	}
+}
+
+func seriesPoints(series geometry.Series) []geometry.Point {
+	points := make([]geometry.Point, series.NumPoints())
+	for i := range points {
+		points[i] = series.PointAt(i)
+	}
+	return points
+}
+
+// tilePoint is a point that has been rounded to the tile's extent
+type tilePoint struct{ x, y int32 }
+
+// quantize returns the rounded points, without repeated points
+func quantize(points []geometry.Point) []tilePoint {
+	tpoints := make([]tilePoint, 0, len(points))
+	for _, point := range points {
+		tp := tilePoint{int32(math.Round(point.X)), int32(math.Round(point.Y))}
+		if len(tpoints) > 0 && tpoints[len(tpoints)-1] == tp {
+			continue
+		}
+		tpoints = append(tpoints, tp)
+	}
+	return tpoints
+}
+
+// commands writes the geometry commands, where the cursor is kept from one
+// command to the next.
+type commands struct {
+	cmds []uint32
+	x, y int32
+}
+
+func (c *commands) command(id, count int) {
+	c.cmds = append(c.cmds, uint32(id&7|count<<3))
+}
+
+func (c *commands) point(p tilePoint) {
+	c.cmds = append(c.cmds, zigzag(p.x-c.x), zigzag(p.y-c.y))
+	c.x, c.y = p.x, p.y
+}
+
+func (s *shapes) encodePoints(bbox geometry.Rect) []uint32 {
+	var tpoints []tilePoint
+	for _, point := range s.points {
+		if bbox.ContainsPoint(point) {
+			tpoints = append(tpoints, quantize([]geometry.Point{point})...)
+		}
+	}
+	if len(tpoints) == 0 {
+		return nil
+	}
+	var c commands
+	c.command(cmdMoveTo, len(tpoints))
+	for _, p := range tpoints {
+		c.point(p)
+	}
+	return c.cmds
+}
+
+func (s *shapes) encodeLines() []uint32 {
+	var c commands
+	for _, line := range s.lines {
+		tpoints := quantize(line)
+		if len(tpoints) < 2 {
+			continue
+		}
+		c.command(cmdMoveTo, 1)
+		c.point(tpoints[0])
+		c.command(cmdLineTo, len(tpoints)-1)
+		for _, p := range tpoints[1:] {
+			c.point(p)
+		}
+	}
+	return c.cmds
+}
+
+func (s *shapes) encodePolygons() []uint32 {
+	var c commands
+	for _, rings := range s.polys {
+		for i, ring := range rings {
+			tpoints := quantize(ring)
+			if len(tpoints) > 1 && tpoints[0] == tpoints[len(tpoints)-1] {
+				tpoints = tpoints[:len(tpoints)-1]
+			}
+			area := ringArea(tpoints)
+			if len(tpoints) < 3 || area == 0 {
+				if i == 0 {
+					// the exterior is gone, and so are the holes
+					break
+				}
+				continue
+			}
+			// exteriors have a positive area and holes have a negative
+			// area, in tile coordinates
+			if (i == 0) != (area > 0) {
+				for i, j := 1, len(tpoints)-1; i < j; i, j = i+1, j-1 {
+					tpoints[i], tpoints[j] = tpoints[j], tpoints[i]
+				}
+			}
+			c.command(cmdMoveTo, 1)
+			c.point(tpoints[0])
+			c.command(cmdLineTo, len(tpoints)-1)
+			for _, p := range tpoints[1:] {
+				c.point(p)
+			}
+			c.command(cmdClosePath, 1)
+		}
+	}
+	return c.cmds
+}
+
+// ringArea returns twice the signed area of a ring
+func ringArea(ring []tilePoint) int64 {
+	var area int64
+	for i := range ring {
+		a, b := ring[i], ring[(i+1)%len(ring)]
+		area += int64(a.x)*int64(b.y) - int64(b.x)*int64(a.y)
+	}
+	return area
+}
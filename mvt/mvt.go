@@ -0,0 +1,87 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mvt
+
+import (
+	"errors"
+	"math"
+
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/geojson/geo"
+	"github.com/tidwall/geojson/tiles"
+)
+
+// ErrInvalidTile is returned when the data is not a valid vector tile
+var ErrInvalidTile = errors.New("invalid tile")
+
+// Layer is a named layer of a vector tile
+type Layer struct {
+	Name string
+	// Features are the objects in the layer. A collection, such as a
+	// FeatureCollection, adds one tile feature for each child, and any other
+	// object adds a single tile feature.
+	Features geojson.Object
+}
+
+// EncodeOptions ...
+type EncodeOptions struct {
+	// Extent is the width and height of the tile in tile units. The default
+	// is 4096.
+	Extent int
+	// Buffer is the number of tile units past the edges of the tile that
+	// geometries are kept before being clipped. The default is 64.
+	Buffer int
+}
+
+// DefaultEncodeOptions ...
+var DefaultEncodeOptions = &EncodeOptions{
+	Extent: 4096,
+	Buffer: 64,
+}
+
+// Geometry types
+const (
+	geomPoint      = 1
+	geomLineString = 2
+	geomPolygon    = 3
+)
+
+// Geometry commands
+const (
+	cmdMoveTo    = 1
+	cmdLineTo    = 2
+	cmdClosePath = 7
+)
+
+// tileProjection converts lat/lon coordinates to and from the coordinates
+// of a tile, where x goes right and y goes down from the top left corner of
+// the tile.
+type tileProjection struct {
+	tile   tiles.Tile
+	extent float64
+}
+
+var _ geo.Projection = tileProjection{}
+
+func (p tileProjection) Forward(lat, lon float64) (x, y float64) {
+	n := float64(uint64(1) << uint(p.tile.Z))
+	lat = math.Max(-geo.MaxMercatorLatitude,
+		math.Min(geo.MaxMercatorLatitude, lat))
+	sin := math.Sin(lat * math.Pi / 180)
+	wx := (lon + 180) / 360
+	wy := 0.5 - math.Log((1+sin)/(1-sin))/(4*math.Pi)
+	x = (wx*n - float64(p.tile.X)) * p.extent
+	y = (wy*n - float64(p.tile.Y)) * p.extent
+	return x, y
+}
+
+func (p tileProjection) Inverse(x, y float64) (lat, lon float64) {
+	n := float64(uint64(1) << uint(p.tile.Z))
+	wx := (x/p.extent + float64(p.tile.X)) / n
+	wy := (y/p.extent + float64(p.tile.Y)) / n
+	lon = wx*360 - 180
+	lat = math.Atan(math.Sinh(math.Pi*(1-2*wy))) * 180 / math.Pi
+	return lat, lon
+}
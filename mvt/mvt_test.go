@@ -0,0 +1,217 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mvt
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/geojson/geometry"
+	"github.com/tidwall/geojson/tiles"
+	"github.com/tidwall/gjson"
+)
+
+func expect(t testing.TB, what bool) {
+	t.Helper()
+	if !what {
+		t.Fatal("expection failure")
+	}
+}
+
+func P(x, y float64) geometry.Point {
+	return geometry.Point{X: x, Y: y}
+}
+
+func equalCmds(a, b []uint32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestCommands(t *testing.T) {
+	for _, n := range []int32{0, 1, -1, 2, -2, 1 << 30, -(1 << 30)} {
+		expect(t, unzigzag(zigzag(n)) == n)
+	}
+	expect(t, zigzag(-1) == 1 && zigzag(1) == 2)
+
+	// the examples from the vector tile spec
+	s := shapes{points: []geometry.Point{P(25, 17)}}
+	bbox := geometry.Rect{Max: P(4096, 4096)}
+	expect(t, equalCmds(s.encodePoints(bbox), []uint32{9, 50, 34}))
+	s = shapes{points: []geometry.Point{P(5, 7), P(3, 2)}}
+	expect(t, equalCmds(s.encodePoints(bbox), []uint32{17, 10, 14, 3, 9}))
+	s = shapes{lines: [][]geometry.Point{
+		{P(2, 2), P(2, 10), P(10, 10)},
+		{P(1, 1), P(3, 5)},
+	}}
+	expect(t, equalCmds(s.encodeLines(),
+		[]uint32{9, 4, 4, 18, 0, 16, 16, 0, 9, 17, 17, 10, 4, 8}))
+	s = shapes{polys: [][][]geometry.Point{
+		{{P(3, 6), P(8, 12), P(20, 34), P(3, 6)}},
+	}}
+	expect(t, equalCmds(s.encodePolygons(),
+		[]uint32{9, 6, 12, 18, 10, 12, 24, 44, 15}))
+	// the exterior is turned to a positive area
+	s = shapes{polys: [][][]geometry.Point{
+		{{P(3, 6), P(20, 34), P(8, 12), P(3, 6)}},
+	}}
+	expect(t, equalCmds(s.encodePolygons(),
+		[]uint32{9, 6, 12, 18, 10, 12, 24, 44, 15}))
+	// rings that collapse when rounded are dropped
+	s = shapes{polys: [][][]geometry.Point{
+		{{P(3, 6), P(3.1, 6.1), P(3.2, 5.9), P(3, 6)}},
+	}}
+	expect(t, len(s.encodePolygons()) == 0)
+
+	parts, err := decodeGeometry([]uint32{9, 4, 4, 18, 0, 16, 16, 0, 9, 17, 17,
+		10, 4, 8})
+	expect(t, err == nil && len(parts) == 2)
+	expect(t, parts[0].points[2] == tilePoint{10, 10})
+	expect(t, parts[1].points[1] == tilePoint{3, 5})
+	_, err = decodeGeometry([]uint32{9, 4})
+	expect(t, err == ErrInvalidTile)
+	_, err = decodeGeometry([]uint32{18, 0, 16})
+	expect(t, err == ErrInvalidTile)
+	_, err = decodeGeometry([]uint32{3})
+	expect(t, err == ErrInvalidTile)
+}
+
+func TestEncodeDecode(t *testing.T) {
+	tile := tiles.FromPoint(33.45, -112.07, 12)
+	bounds := tile.Bounds()
+	center := bounds.Center()
+	dx := (bounds.Max.X - bounds.Min.X) / 4
+	dy := (bounds.Max.Y - bounds.Min.Y) / 4
+	poly := geometry.NewPoly(
+		[]geometry.Point{
+			P(center.X-dx, center.Y-dy), P(center.X+dx, center.Y-dy),
+			P(center.X+dx, center.Y+dy), P(center.X-dx, center.Y+dy),
+			P(center.X-dx, center.Y-dy),
+		},
+		[][]geometry.Point{{
+			P(center.X-dx/2, center.Y-dy/2), P(center.X+dx/2, center.Y-dy/2),
+			P(center.X+dx/2, center.Y+dy/2), P(center.X-dx/2, center.Y-dy/2),
+		}}, nil)
+	fc := geojson.NewFeatureCollection([]geojson.Object{
+		geojson.NewFeature(geojson.NewPoint(center),
+			`{"id":7,"properties":{"name":"a","n":12,"neg":-3,"f":1.5,`+
+				`"ok":true,"obj":{"a": [1, 2]},"none":null},"foreign":1}`),
+		geojson.NewFeature(geojson.NewLineString(geometry.NewLine(
+			[]geometry.Point{P(center.X-dx, center.Y), P(center.X+dx, center.Y)},
+			nil)), `{"properties":{"name":"a","n":12}}`),
+		geojson.NewFeature(geojson.NewPolygon(poly),
+			`{"id":"str","properties":{"name":"b"}}`),
+		geojson.NewPoint(P(center.X+50, center.Y)), // outside of the tile
+	})
+	data := Encode(tile, []Layer{{Name: "places", Features: fc}}, nil)
+
+	// the key and value tables are shared by the features
+	var keys, values, features int
+	err := readFields(data, func(field, wire int, v uint64, b []byte) error {
+		return readFields(b, func(field, wire int, v uint64, b []byte) error {
+			switch field {
+			case 2:
+				features++
+			case 3:
+				keys++
+			case 4:
+				values++
+			}
+			return nil
+		})
+	})
+	expect(t, err == nil)
+	expect(t, features == 3 && keys == 6 && values == 7)
+
+	layers, err := Decode(data, tile)
+	expect(t, err == nil && len(layers) == 1)
+	expect(t, layers[0].Name == "places")
+	children := layers[0].Features.(*geojson.FeatureCollection).Children()
+	expect(t, len(children) == 3)
+
+	// one tile unit is the tolerance
+	tol := (bounds.Max.X - bounds.Min.X) / 4096
+	near := func(a, b geometry.Point) bool {
+		return math.Abs(a.X-b.X) < tol && math.Abs(a.Y-b.Y) < tol
+	}
+	f := children[0].(*geojson.Feature)
+	expect(t, near(f.Base().(*geojson.Point).Base(), center))
+	members := f.Members()
+	expect(t, gjson.Get(members, "id").Int() == 7)
+	expect(t, gjson.Get(members, "properties.name").String() == "a")
+	expect(t, gjson.Get(members, "properties.n").Raw == "12")
+	expect(t, gjson.Get(members, "properties.neg").Raw == "-3")
+	expect(t, gjson.Get(members, "properties.f").Raw == "1.5")
+	expect(t, gjson.Get(members, "properties.ok").Raw == "true")
+	expect(t, gjson.Get(members, "properties.obj").String() == `{"a":[1,2]}`)
+	expect(t, !gjson.Get(members, "properties.none").Exists())
+	expect(t, !gjson.Get(members, "foreign").Exists())
+
+	f = children[1].(*geojson.Feature)
+	line := f.Base().(*geojson.LineString).Base()
+	expect(t, near(line.PointAt(0), P(center.X-dx, center.Y)))
+	expect(t, near(line.PointAt(1), P(center.X+dx, center.Y)))
+	expect(t, !gjson.Get(f.Members(), "id").Exists())
+
+	f = children[2].(*geojson.Feature)
+	expect(t, !gjson.Get(f.Members(), "id").Exists())
+	dpoly := f.Base().(*geojson.Polygon)
+	expect(t, len(dpoly.Base().Holes) == 1)
+	expect(t, dpoly.Contains(geojson.NewPoint(P(center.X-dx*0.9, center.Y))))
+	expect(t, !dpoly.Contains(geojson.NewPoint(P(center.X+dx*0.4, center.Y-dy*0.1))))
+	expect(t, math.Abs(dpoly.Rect().Min.X-poly.Rect().Min.X) < tol)
+	expect(t, math.Abs(dpoly.Rect().Max.Y-poly.Rect().Max.Y) < tol)
+}
+
+func TestClip(t *testing.T) {
+	tile := tiles.Tile{X: 1, Y: 1, Z: 2}
+	bounds := tile.Bounds()
+	big := geojson.NewRect(geometry.Rect{
+		Min: P(bounds.Min.X-20, bounds.Min.Y-20),
+		Max: P(bounds.Max.X+20, bounds.Max.Y+20),
+	})
+	line := geojson.NewLineString(geometry.NewLine([]geometry.Point{
+		P(bounds.Min.X-20, bounds.Center().Y),
+		P(bounds.Max.X+20, bounds.Center().Y),
+	}, nil))
+	data := Encode(tile, []Layer{
+		{Name: "a", Features: big},
+		{Name: "b", Features: line},
+	}, &EncodeOptions{Extent: 256, Buffer: 0})
+	layers, err := Decode(data, tile)
+	expect(t, err == nil && len(layers) == 2)
+	tol := 1e-9
+	for _, layer := range layers {
+		children := layer.Features.(*geojson.FeatureCollection).Children()
+		expect(t, len(children) == 1)
+		rect := children[0].Rect()
+		expect(t, math.Abs(rect.Min.X-bounds.Min.X) < tol)
+		expect(t, math.Abs(rect.Max.X-bounds.Max.X) < tol)
+		if layer.Name == "a" {
+			expect(t, math.Abs(rect.Min.Y-bounds.Min.Y) < tol)
+			expect(t, math.Abs(rect.Max.Y-bounds.Max.Y) < tol)
+		}
+	}
+
+	// the buffer keeps more of the objects
+	data = Encode(tile, []Layer{{Name: "a", Features: big}},
+		&EncodeOptions{Extent: 256, Buffer: 16})
+	layers, _ = Decode(data, tile)
+	rect := layers[0].Features.Rect()
+	expect(t, rect.Min.X < bounds.Min.X && rect.Max.X > bounds.Max.X)
+	expect(t, rect.Min.X > bounds.Min.X-20)
+
+	_, err = Decode([]byte{0x1a, 0x10, 0x01}, tile)
+	expect(t, err == ErrInvalidTile)
+	layers, err = Decode(nil, tile)
+	expect(t, err == nil && len(layers) == 0)
+}
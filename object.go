@@ -13,6 +13,7 @@ import (
 
 var (
 	fmtErrTypeIsUnknown         = "type '%s' is unknown"
+	fmtErrFilterInvalid         = "invalid filter: unexpected %s at offset %d"
 	errDataInvalid              = errors.New("invalid data")
 	errTypeInvalid              = errors.New("invalid type")
 	errTypeMissing              = errors.New("missing type")
@@ -25,6 +26,9 @@ var (
 	errGeometriesMissing        = errors.New("missing geometries")
 	errGeometriesInvalid        = errors.New("invalid geometries")
 	errCircleRadiusUnitsInvalid = errors.New("invalid circle radius units")
+	errRadiusUnitsInvalid       = errors.New("invalid radius units")
+	errPolylineInvalid          = errors.New("invalid polyline")
+	errMemberInvalid            = errors.New("invalid member")
 )
 
 // Object is a GeoJSON type
@@ -50,7 +54,8 @@ var _ = []Object{
 	&Point{}, &LineString{}, &Polygon{}, &Feature{},
 	&MultiPoint{}, &MultiLineString{}, &MultiPolygon{},
 	&GeometryCollection{}, &FeatureCollection{},
-	&Rect{}, &Circle{}, &SimplePoint{},
+	&Rect{}, &Circle{}, &ClippedCircle{}, &SimplePoint{},
+	&Ellipse{}, &Sector{}, &Annulus{},
 }
 
 // Collection is a searchable collection type.
@@ -98,16 +103,47 @@ type ParseOptions struct {
 	// DisableCircleType disables the special Circle syntax that is unique to
 	// only Tile38.
 	DisableCircleType bool
+	// DisableEllipseType disables the special Ellipse syntax. The Ellipse,
+	// Sector, and Annulus syntaxes are only used when their sizes are valid,
+	// and other Features with those types are plain Features.
+	DisableEllipseType bool
+	// DisableSectorType disables the special Sector syntax.
+	DisableSectorType bool
+	// DisableAnnulusType disables the special Annulus syntax.
+	DisableAnnulusType bool
+	// DistanceModel is the model of the earth used by the Circle type. The
+	// Ellipse, Sector, and Annulus types are always spherical. The default
+	// is geo.Spherical.
+	DistanceModel geo.Model
 }
 
 // DefaultParseOptions ...
 var DefaultParseOptions = &ParseOptions{
-	IndexChildren:     64,
-	IndexGeometry:     64,
-	IndexGeometryKind: geometry.QuadTree,
-	RequireValid:      false,
-	AllowSimplePoints: false,
-	DisableCircleType: false,
+	IndexChildren:      64,
+	IndexGeometry:      64,
+	IndexGeometryKind:  geometry.QuadTree,
+	RequireValid:       false,
+	AllowSimplePoints:  false,
+	DisableCircleType:  false,
+	DisableEllipseType: false,
+	DisableSectorType:  false,
+	DisableAnnulusType: false,
+	DistanceModel:      geo.Spherical,
+}
+
+// WithoutShapeTypes returns a copy of the options that disables the special
+// Circle, Ellipse, Sector, and Annulus syntaxes. It's for parsing data from
+// other formats, where a "type" property is an attribute and not a shape.
+func WithoutShapeTypes(opts *ParseOptions) *ParseOptions {
+	if opts == nil {
+		opts = DefaultParseOptions
+	}
+	nopts := *opts
+	nopts.DisableCircleType = true
+	nopts.DisableEllipseType = true
+	nopts.DisableSectorType = true
+	nopts.DisableAnnulusType = true
+	return &nopts
 }
 
 // Parse a GeoJSON object
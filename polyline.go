@@ -0,0 +1,228 @@
+package geojson
+
+import (
+	"math"
+
+	"github.com/tidwall/geojson/geometry"
+)
+
+// PolylineOptions ...
+type PolylineOptions struct {
+	// Precision is the number of decimal digits of the coordinates, which is
+	// 5 for Google polylines, or 6 for the polylines of routing engines such
+	// as OSRM and Valhalla. The default is 5.
+	Precision int
+	// Z adds the Z value of each point after its latitude and longitude,
+	// with the same precision. Points without a Z value are encoded with
+	// zero.
+	Z bool
+}
+
+// DefaultPolylineOptions ...
+var DefaultPolylineOptions = &PolylineOptions{
+	Precision: 5,
+}
+
+func (opts *PolylineOptions) factor() float64 {
+	if opts.Precision <= 0 {
+		return 1e5
+	}
+	return math.Pow10(opts.Precision)
+}
+
+func (opts *PolylineOptions) dims() int {
+	if opts.Z {
+		return 3
+	}
+	return 2
+}
+
+// polylinePart is a sequence of points and their extra coordinate values,
+// with dims values per point.
+type polylinePart struct {
+	points []geometry.Point
+	values []float64
+	dims   int
+}
+
+// EncodePolyline returns the Google encoded polylines of an object. A
+// LineString, MultiPoint, or Point is a single polyline and a Polygon has a
+// polyline for each ring. The polylines of a multi-part object, such as a
+// MultiLineString or a FeatureCollection, are returned in order.
+func EncodePolyline(obj Object, opts *PolylineOptions) []string {
+	if opts == nil {
+		opts = DefaultPolylineOptions
+	}
+	factor := opts.factor()
+	parts := appendPolylineParts(nil, obj)
+	polylines := make([]string, 0, len(parts))
+	for _, part := range parts {
+		var dst []byte
+		var prev [3]int64
+		for i, point := range part.points {
+			vals := [3]float64{point.Y, point.X}
+			if opts.Z && part.dims > 0 {
+				vals[2] = part.values[i*part.dims]
+			}
+			for j := 0; j < opts.dims(); j++ {
+				n := int64(math.Round(vals[j] * factor))
+				dst = appendPolylineValue(dst, n-prev[j])
+				prev[j] = n
+			}
+		}
+		polylines = append(polylines, string(dst))
+	}
+	return polylines
+}
+
+func appendPolylineParts(parts []polylinePart, obj Object) []polylinePart {
+	switch g := obj.(type) {
+	case *Point:
+		values, dims := extraValues(g.extra, 0, 1)
+		return append(parts, polylinePart{[]geometry.Point{g.base}, values,
+			dims})
+	case *SimplePoint:
+		return append(parts, polylinePart{points: []geometry.Point{g.Point}})
+	case *LineString:
+		values, dims := extraValues(g.extra, 0, g.base.NumPoints())
+		return append(parts, polylinePart{seriesPoints(&g.base), values,
+			dims})
+	case *Rect:
+		return append(parts, polylinePart{points: seriesPoints(g.base)})
+	case *Polygon:
+		var pidx int
+		rings := append([]geometry.Ring{g.base.Exterior}, g.base.Holes...)
+		for _, ring := range rings {
+			values, dims := extraValues(g.extra, pidx, ring.NumPoints())
+			pidx += ring.NumPoints()
+			parts = append(parts, polylinePart{seriesPoints(ring), values,
+				dims})
+		}
+		return parts
+	case *MultiPoint:
+		// all of the points are one polyline
+		var part polylinePart
+		for _, child := range g.children {
+			for _, p := range appendPolylineParts(nil, child) {
+				part.points = append(part.points, p.points...)
+				for i := range p.points {
+					var z float64
+					if p.dims > 0 {
+						z = p.values[i*p.dims]
+					}
+					part.values = append(part.values, z)
+				}
+			}
+		}
+		part.dims = 1
+		return append(parts, part)
+	case *Feature:
+		return appendPolylineParts(parts, g.base)
+	case Collection:
+		for _, child := range g.Children() {
+			parts = appendPolylineParts(parts, child)
+		}
+		return parts
+	case interface{ Primative() Object }:
+		return appendPolylineParts(parts, g.Primative())
+	}
+	return parts
+}
+
+// appendPolylineValue appends a signed value as chunks of five bits
+func appendPolylineValue(dst []byte, n int64) []byte {
+	v := uint64(n) << 1
+	if n < 0 {
+		v = ^v
+	}
+	for v >= 0x20 {
+		dst = append(dst, byte(0x20|v&0x1f)+63)
+		v >>= 5
+	}
+	return append(dst, byte(v)+63)
+}
+
+// decodePolyline returns the points of a polyline, and the Z values when
+// the options have Z.
+func decodePolyline(polyline string, opts *PolylineOptions,
+) ([]geometry.Point, []float64, error) {
+	factor := opts.factor()
+	dims := opts.dims()
+	var points []geometry.Point
+	var zs []float64
+	var vals [3]int64
+	var j int
+	for i := 0; i < len(polyline); {
+		var v uint64
+		var shift uint
+		for {
+			if i == len(polyline) || shift > 63 {
+				return nil, nil, errPolylineInvalid
+			}
+			b := polyline[i]
+			i++
+			if b < 63 || b > 126 {
+				return nil, nil, errPolylineInvalid
+			}
+			b -= 63
+			v |= uint64(b&0x1f) << shift
+			shift += 5
+			if b < 0x20 {
+				break
+			}
+		}
+		n := int64(v >> 1)
+		if v&1 != 0 {
+			n = ^n
+		}
+		vals[j] += n
+		j++
+		if j == dims {
+			points = append(points, geometry.Point{
+				X: float64(vals[1]) / factor,
+				Y: float64(vals[0]) / factor,
+			})
+			if opts.Z {
+				zs = append(zs, float64(vals[2])/factor)
+			}
+			j = 0
+		}
+	}
+	if j != 0 {
+		return nil, nil, errPolylineInvalid
+	}
+	return points, zs, nil
+}
+
+// DecodePolyline returns the LineString of a Google encoded polyline. The
+// LineString has Z values when the options have Z.
+func DecodePolyline(polyline string, opts *PolylineOptions,
+) (*LineString, error) {
+	if opts == nil {
+		opts = DefaultPolylineOptions
+	}
+	points, zs, err := decodePolyline(polyline, opts)
+	if err != nil {
+		return nil, err
+	}
+	g := NewLineString(geometry.NewLine(points, nil))
+	if opts.Z {
+		g.extra = &extra{dims: 1, values: zs}
+	}
+	return g, nil
+}
+
+// DecodePolylines returns a MultiLineString with a line for each polyline
+func DecodePolylines(polylines []string, opts *PolylineOptions,
+) (*MultiLineString, error) {
+	g := new(MultiLineString)
+	for _, polyline := range polylines {
+		line, err := DecodePolyline(polyline, opts)
+		if err != nil {
+			return nil, err
+		}
+		g.children = append(g.children, line)
+	}
+	g.parseInitRectIndex(DefaultParseOptions)
+	return g, nil
+}
@@ -0,0 +1,87 @@
+package geojson
+
+import (
+	"testing"
+
+	"github.com/tidwall/geojson/geometry"
+)
+
+func TestPolyline(t *testing.T) {
+	// the example from the Google polyline documentation
+	line := LO([]geometry.Point{P(-120.2, 38.5), P(-120.95, 40.7),
+		P(-126.453, 43.252)})
+	polylines := EncodePolyline(line, nil)
+	expect(t, len(polylines) == 1)
+	expect(t, polylines[0] == "_p~iF~ps|U_ulLnnqC_mqNvxq`@")
+	decoded, err := DecodePolyline(polylines[0], nil)
+	expect(t, err == nil)
+	expect(t, decoded.JSON() == line.JSON())
+
+	// precision of six digits
+	opts := &PolylineOptions{Precision: 6}
+	line = LO([]geometry.Point{P(-122.4194155, 37.7749295), P(-122.5, 37.8)})
+	polylines = EncodePolyline(line, opts)
+	decoded, err = DecodePolyline(polylines[0], opts)
+	expect(t, err == nil)
+	expect(t, decoded.JSON() == `{"type":"LineString","coordinates":[[-122.419416,37.77493],[-122.5,37.8]]}`)
+	decoded, err = DecodePolyline(polylines[0], nil)
+	expect(t, err == nil)
+	expect(t, decoded.Base().PointAt(1) == P(-1225, 378))
+
+	// z values
+	opts = &PolylineOptions{Precision: 5, Z: true}
+	line = expectJSON(t, `{"type":"LineString","coordinates":[[1,2,100.5],[3,4,-20]]}`, nil).(*LineString)
+	polylines = EncodePolyline(line, opts)
+	decoded, err = DecodePolyline(polylines[0], opts)
+	expect(t, err == nil)
+	expect(t, decoded.JSON() == line.JSON())
+	decoded, err = DecodePolyline(EncodePolyline(LO([]geometry.Point{P(1, 2)}),
+		opts)[0], opts)
+	expect(t, err == nil)
+	expect(t, decoded.JSON() == `{"type":"LineString","coordinates":[[1,2,0]]}`)
+
+	// invalid polylines
+	_, err = DecodePolyline("_p~iF~ps|U_ulLnnqC_mqNvxq", nil)
+	expect(t, err == errPolylineInvalid)
+	_, err = DecodePolyline("_p~iF", nil)
+	expect(t, err == errPolylineInvalid)
+	_, err = DecodePolyline("_p~iF ps|U", nil)
+	expect(t, err == errPolylineInvalid)
+	_, err = DecodePolyline("_p~iF~ps|U", opts)
+	expect(t, err == errPolylineInvalid)
+	decoded, err = DecodePolyline("", nil)
+	expect(t, err == nil && decoded.Base().NumPoints() == 0)
+}
+
+func TestPolylineParts(t *testing.T) {
+	// a polygon has a polyline for each ring
+	poly := expectJSON(t, `{"type":"Polygon","coordinates":[[[0,0,1],[10,0,2],[10,10,3],[0,0,1]],[[2,2,4],[4,2,5],[4,4,6],[2,2,4]]]}`, nil)
+	opts := &PolylineOptions{Precision: 5, Z: true}
+	polylines := EncodePolyline(poly, opts)
+	expect(t, len(polylines) == 2)
+	lines, err := DecodePolylines(polylines, opts)
+	expect(t, err == nil)
+	expect(t, lines.JSON() == `{"type":"MultiLineString","coordinates":[[[0,0,1],[10,0,2],[10,10,3],[0,0,1]],[[2,2,4],[4,2,5],[4,4,6],[2,2,4]]]}`)
+	expect(t, lines.Rect() == R(0, 0, 10, 10))
+
+	// a multipoint is a single polyline
+	mp := expectJSON(t, `{"type":"MultiPoint","coordinates":[[1,2,3],[4,5,6]]}`, nil)
+	polylines = EncodePolyline(mp, opts)
+	expect(t, len(polylines) == 1)
+	lines, err = DecodePolylines(polylines, opts)
+	expect(t, err == nil)
+	expect(t, lines.JSON() == `{"type":"MultiLineString","coordinates":[[[1,2,3],[4,5,6]]]}`)
+
+	// the parts of collections are in order
+	fc := expectJSON(t, `{"type":"FeatureCollection","features":[
+		{"type":"Feature","geometry":{"type":"MultiLineString","coordinates":[[[0,0],[1,1]],[[2,2],[3,3]]]},"properties":{}},
+		{"type":"Feature","geometry":{"type":"Point","coordinates":[5,5]},"properties":{}}
+	]}`, nil)
+	polylines = EncodePolyline(fc, nil)
+	expect(t, len(polylines) == 3)
+	lines, err = DecodePolylines(polylines, nil)
+	expect(t, err == nil)
+	expect(t, lines.JSON() == `{"type":"MultiLineString","coordinates":[[[0,0],[1,1]],[[2,2],[3,3]],[[5,5]]]}`)
+	_, err = DecodePolylines([]string{"??", "?"}, nil)
+	expect(t, err == errPolylineInvalid)
+}
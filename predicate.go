@@ -0,0 +1,70 @@
+package geojson
+
+import (
+	"github.com/tidwall/geojson/geo"
+	"github.com/tidwall/geojson/geometry"
+)
+
+// PredicateOp is a spatial relationship of an object with a target object
+type PredicateOp byte
+
+const (
+	// PredicateIntersects is when the object intersects the target.
+	PredicateIntersects PredicateOp = iota
+	// PredicateContains is when the object contains the target.
+	PredicateContains
+	// PredicateWithin is when the object is within the target.
+	PredicateWithin
+	// PredicateWithinDistance is when the center of the object is within a
+	// distance in meters of the center of the target, like Distance.
+	PredicateWithinDistance
+)
+
+// Predicate is a spatial predicate, which tests the relationship of objects
+// with a target object.
+type Predicate struct {
+	// Op is the relationship of the objects with the target.
+	Op PredicateOp
+	// Target is the object that objects are tested against. A nil Target
+	// matches all objects. It's not used by Join, where the target is each
+	// child of the right collection.
+	Target Object
+	// Meters is the distance of PredicateWithinDistance.
+	Meters float64
+}
+
+// Match returns true when an object has the relationship with the target,
+// or when there's no target.
+func (p *Predicate) Match(obj Object) bool {
+	if p.Target == nil {
+		return true
+	}
+	return p.match(obj, p.Target)
+}
+
+func (p *Predicate) match(obj, target Object) bool {
+	switch p.Op {
+	case PredicateContains:
+		return obj.Contains(target)
+	case PredicateWithin:
+		return obj.Within(target)
+	case PredicateWithinDistance:
+		return obj.Distance(target) <= p.Meters
+	}
+	return obj.Intersects(target)
+}
+
+// searchRect returns the area where the objects that match a target are
+// found.
+func (p *Predicate) searchRect(target Object) geometry.Rect {
+	if p.Op != PredicateWithinDistance {
+		return target.Rect()
+	}
+	center := target.Center()
+	minLat, minLon, maxLat, maxLon :=
+		geo.RectFromCenter(center.Y, center.X, p.Meters)
+	return geometry.Rect{
+		Min: geometry.Point{X: minLon, Y: minLat},
+		Max: geometry.Point{X: maxLon, Y: maxLat},
+	}
+}
@@ -0,0 +1,267 @@
+package geojson
+
+import (
+	"math"
+
+	"github.com/tidwall/geojson/geometry"
+)
+
+// the kinds of cells of a prepared polygon
+const (
+	cellOutside byte = iota
+	cellInside
+	cellBoundary // a cell that a segment crosses, which needs the rings
+)
+
+// Prepared is an object with cached structures that speed up repeated
+// Contains, Intersects, and Distance tests with points, such as testing a
+// geofence against many points. The results are the same as the object's.
+// All other methods are the object's.
+type Prepared struct {
+	Object
+	center geometry.Point
+	polys  []*preparedPoly
+}
+
+// preparedPoly is a polygon with indexed rings, and a grid of cells over its
+// rect that are inside, outside, or on the boundary of the polygon.
+type preparedPoly struct {
+	poly   *geometry.Poly
+	rect   geometry.Rect
+	n      int     // cells per side
+	cw, ch float64 // cell size
+	cells  []byte
+}
+
+// Prepare returns a prepared object. Polygons and MultiPolygons, which may
+// be in a Feature, are prepared with a grid of the cells that are inside,
+// outside, or on the boundary, and with indexes for all of their rings,
+// including holes. Only points on the boundary cells need the rings. Other
+// objects are returned as is, in a Prepared.
+func Prepare(obj Object) *Prepared {
+	p := &Prepared{Object: obj}
+	base := obj
+	if f, ok := base.(*Feature); ok {
+		base = f.base
+	}
+	switch g := base.(type) {
+	case *Polygon:
+		if g.Empty() {
+			return p
+		}
+		p.polys = appendPreparedPoly(nil, &g.base)
+	case *MultiPolygon:
+		for _, child := range g.children {
+			if poly, ok := child.(*Polygon); ok && !poly.Empty() {
+				p.polys = appendPreparedPoly(p.polys, &poly.base)
+			} else if !child.Empty() {
+				// not a polygon, which is left to the object
+				return &Prepared{Object: obj}
+			}
+		}
+	default:
+		return p
+	}
+	p.center = base.Center()
+	return p
+}
+
+// preparedPoint returns the point of a point object
+func preparedPoint(obj Object) (geometry.Point, bool) {
+	switch g := obj.(type) {
+	case *Point:
+		return g.base, true
+	case *SimplePoint:
+		return g.Point, true
+	case *Feature:
+		return preparedPoint(g.base)
+	}
+	return geometry.Point{}, false
+}
+
+// containsPoint returns the same as the polygons' ContainsPoint, which is
+// used for both Contains and Intersects of points.
+func (p *Prepared) containsPoint(point geometry.Point) bool {
+	for _, poly := range p.polys {
+		if poly.containsPoint(point) {
+			return true
+		}
+	}
+	return false
+}
+
+// Contains returns true when the object contains another object.
+func (p *Prepared) Contains(obj Object) bool {
+	if point, ok := preparedPoint(obj); ok && p.polys != nil {
+		return p.containsPoint(point)
+	}
+	return p.Object.Contains(obj)
+}
+
+// Intersects returns true when the object intersects another object.
+func (p *Prepared) Intersects(obj Object) bool {
+	if point, ok := preparedPoint(obj); ok && p.polys != nil {
+		return p.containsPoint(point)
+	}
+	return p.Object.Intersects(obj)
+}
+
+// Distance returns the distance in meters to another object.
+func (p *Prepared) Distance(obj Object) float64 {
+	if point, ok := preparedPoint(obj); ok && p.polys != nil {
+		return geoDistancePoints(point, p.center)
+	}
+	return p.Object.Distance(obj)
+}
+
+func appendPreparedPoly(polys []*preparedPoly, poly *geometry.Poly,
+) []*preparedPoly {
+	// rebuild the rings with indexes
+	opts := &geometry.IndexOptions{Kind: geometry.QuadTree, MinPoints: 1}
+	var holes [][]geometry.Point
+	for _, hole := range poly.Holes {
+		holes = append(holes, seriesPoints(hole))
+	}
+	pp := &preparedPoly{
+		poly: geometry.NewPoly(seriesPoints(poly.Exterior), holes, opts),
+		rect: poly.Rect(),
+	}
+	nsegs := pp.poly.Exterior.NumSegments()
+	for _, hole := range pp.poly.Holes {
+		nsegs += hole.NumSegments()
+	}
+	pp.n = int(4 * math.Sqrt(float64(nsegs)))
+	if pp.n < 32 {
+		pp.n = 32
+	} else if pp.n > 256 {
+		pp.n = 256
+	}
+	pp.cw = (pp.rect.Max.X - pp.rect.Min.X) / float64(pp.n)
+	pp.ch = (pp.rect.Max.Y - pp.rect.Min.Y) / float64(pp.n)
+	if !(pp.cw > 0 && pp.ch > 0) {
+		// without an area, all points use the rings
+		pp.n = 0
+		return append(polys, pp)
+	}
+	pp.cells = make([]byte, pp.n*pp.n)
+	pp.markBoundary(pp.poly.Exterior)
+	for _, hole := range pp.poly.Holes {
+		pp.markBoundary(hole)
+	}
+	// Neighboring cells that are not on the boundary are on the same side of
+	// it, so only the first of these cells in a row is tested.
+	for y := 0; y < pp.n; y++ {
+		kind := cellBoundary
+		for x := 0; x < pp.n; x++ {
+			i := y*pp.n + x
+			if pp.cells[i] == cellBoundary {
+				kind = cellBoundary
+				continue
+			}
+			if kind == cellBoundary {
+				kind = cellOutside
+				if pp.poly.ContainsPoint(pp.cellRect(x, y, 0).Center()) {
+					kind = cellInside
+				}
+			}
+			pp.cells[i] = kind
+		}
+	}
+	return append(polys, pp)
+}
+
+// cellRect returns the rect of a cell, expanded by a fraction of its size
+func (pp *preparedPoly) cellRect(x, y int, margin float64) geometry.Rect {
+	mx, my := pp.cw*margin, pp.ch*margin
+	return geometry.Rect{
+		Min: geometry.Point{
+			X: pp.rect.Min.X + float64(x)*pp.cw - mx,
+			Y: pp.rect.Min.Y + float64(y)*pp.ch - my,
+		},
+		Max: geometry.Point{
+			X: pp.rect.Min.X + float64(x+1)*pp.cw + mx,
+			Y: pp.rect.Min.Y + float64(y+1)*pp.ch + my,
+		},
+	}
+}
+
+// cell returns the column and row of the cell of a point, which are clamped
+// to the grid.
+func (pp *preparedPoly) cell(point geometry.Point) (x, y int) {
+	x = int((point.X - pp.rect.Min.X) / pp.cw)
+	y = int((point.Y - pp.rect.Min.Y) / pp.ch)
+	if x < 0 {
+		x = 0
+	} else if x >= pp.n {
+		x = pp.n - 1
+	}
+	if y < 0 {
+		y = 0
+	} else if y >= pp.n {
+		y = pp.n - 1
+	}
+	return x, y
+}
+
+// markBoundary marks the cells that the segments of a ring touch. The cells
+// are expanded a little, so that a point that is rounded into a neighboring
+// cell is still on the boundary.
+func (pp *preparedPoly) markBoundary(ring geometry.Ring) {
+	const margin = 1e-6
+	nsegs := ring.NumSegments()
+	for i := 0; i < nsegs; i++ {
+		seg := ring.SegmentAt(i)
+		rect := seg.Rect()
+		x1, y1 := pp.cell(geometry.Point{
+			X: rect.Min.X - pp.cw*margin, Y: rect.Min.Y - pp.ch*margin})
+		x2, y2 := pp.cell(geometry.Point{
+			X: rect.Max.X + pp.cw*margin, Y: rect.Max.Y + pp.ch*margin})
+		for y := y1; y <= y2; y++ {
+			for x := x1; x <= x2; x++ {
+				if pp.cells[y*pp.n+x] != cellBoundary &&
+					segmentIntersectsRect(seg, pp.cellRect(x, y, margin)) {
+					pp.cells[y*pp.n+x] = cellBoundary
+				}
+			}
+		}
+	}
+}
+
+func segmentIntersectsRect(seg geometry.Segment, rect geometry.Rect) bool {
+	if rect.ContainsPoint(seg.A) || rect.ContainsPoint(seg.B) {
+		return true
+	}
+	for i := 0; i < 4; i++ {
+		if seg.IntersectsSegment(rect.SegmentAt(i)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (pp *preparedPoly) containsPoint(point geometry.Point) bool {
+	if point.X < pp.rect.Min.X || point.X > pp.rect.Max.X ||
+		point.Y < pp.rect.Min.Y || point.Y > pp.rect.Max.Y {
+		return false
+	}
+	if pp.n > 0 {
+		x := int((point.X - pp.rect.Min.X) / pp.cw)
+		y := int((point.Y - pp.rect.Min.Y) / pp.ch)
+		// a point on the max edges is in the last cell
+		if x == pp.n {
+			x--
+		}
+		if y == pp.n {
+			y--
+		}
+		if x >= 0 && x < pp.n && y >= 0 && y < pp.n {
+			switch pp.cells[y*pp.n+x] {
+			case cellInside:
+				return true
+			case cellOutside:
+				return false
+			}
+		}
+	}
+	return pp.poly.ContainsPoint(point)
+}
@@ -0,0 +1,148 @@
+package geojson
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/tidwall/geojson/geometry"
+)
+
+// star returns a concave ring around a center
+func star(cx, cy, r float64, n int) []geometry.Point {
+	var points []geometry.Point
+	for i := 0; i < n*2; i++ {
+		d := r
+		if i%2 == 1 {
+			d = r / 2
+		}
+		a := float64(i) * math.Pi / float64(n)
+		points = append(points, P(cx+math.Cos(a)*d, cy+math.Sin(a)*d))
+	}
+	return append(points, points[0])
+}
+
+func testPrepared(t *testing.T, obj Object) {
+	t.Helper()
+	prep := Prepare(obj)
+	expect(t, prep.JSON() == obj.JSON())
+	// random points, and points on and near the vertices and segments
+	rect := obj.Rect()
+	var points []geometry.Point
+	for i := 0; i < 10000; i++ {
+		points = append(points, P(
+			rect.Min.X+(rand.Float64()*1.2-0.1)*(rect.Max.X-rect.Min.X),
+			rect.Min.Y+(rand.Float64()*1.2-0.1)*(rect.Max.Y-rect.Min.Y)))
+	}
+	obj.ForEach(func(geom Object) bool {
+		var polys []*geometry.Poly
+		switch g := geom.(type) {
+		case *Feature:
+			return true
+		case *Polygon:
+			polys = append(polys, &g.base)
+		case *MultiPolygon:
+			for _, child := range g.children {
+				polys = append(polys, &child.(*Polygon).base)
+			}
+		}
+		for _, poly := range polys {
+			for _, ring := range append([]geometry.Ring{poly.Exterior},
+				poly.Holes...) {
+				for i := 0; i < ring.NumSegments(); i++ {
+					seg := ring.SegmentAt(i)
+					mid := P((seg.A.X+seg.B.X)/2, (seg.A.Y+seg.B.Y)/2)
+					points = append(points, seg.A, mid,
+						P(mid.X+1e-12, mid.Y), P(mid.X, mid.Y-1e-12))
+				}
+			}
+		}
+		return true
+	})
+	points = append(points, rect.Min, rect.Max, rect.Center(),
+		P(math.NaN(), 1))
+	var inside int
+	for _, point := range points {
+		for _, other := range []Object{PO(point.X, point.Y),
+			NewSimplePoint(point), NewFeature(PO(point.X, point.Y), "")} {
+			contains := obj.Contains(other)
+			expect(t, prep.Contains(other) == contains)
+			expect(t, prep.Intersects(other) == obj.Intersects(other))
+			dist, pdist := obj.Distance(other), prep.Distance(other)
+			expect(t, pdist == dist || (math.IsNaN(dist) && math.IsNaN(pdist)))
+			if contains {
+				inside++
+			}
+		}
+	}
+	expect(t, inside > 0)
+	// other objects use the object
+	line := LO([]geometry.Point{rect.Min, rect.Center()})
+	expect(t, prep.Intersects(line) == obj.Intersects(line))
+	expect(t, prep.Contains(line) == obj.Contains(line))
+	expect(t, prep.Distance(line) == obj.Distance(line))
+}
+
+func TestPrepared(t *testing.T) {
+	// a concave polygon with holes, one of which shares a vertex
+	poly := NewPolygon(geometry.NewPoly(star(0, 0, 10, 40),
+		[][]geometry.Point{star(2, 2, 2, 5), star(-3, -3, 1.5, 3),
+			{P(5, 0), P(4, 1), P(4, -1), P(5, 0)}}, nil))
+	testPrepared(t, poly)
+	expect(t, len(Prepare(poly).polys) == 1)
+	expect(t, len(Prepare(poly).polys[0].cells) > 0)
+
+	// multipolygons and features
+	mp := expectJSON(t, `{"type":"MultiPolygon","coordinates":[
+		[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[2,2],[8,2],[8,8],[2,8],[2,2]]],
+		[[[4,4],[6,4],[5,6],[4,4]]],
+		[[[20,0],[30,5],[20,10],[25,5],[20,0]]]]}`, nil)
+	testPrepared(t, mp)
+	expect(t, len(Prepare(mp).polys) == 3)
+	testPrepared(t, NewFeature(mp, `{"id":1}`))
+	testPrepared(t, expectJSON(t, `{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,0]]]}`, nil))
+
+	// polygons without an area, and other objects
+	flat := expectJSON(t, `{"type":"Polygon","coordinates":[[[0,0],[10,0],[5,0],[0,0]]]}`, nil)
+	expect(t, Prepare(flat).polys[0].n == 0)
+	expect(t, Prepare(flat).Contains(PO(5, 0)) == flat.Contains(PO(5, 0)))
+	line := LO([]geometry.Point{P(0, 0), P(10, 10)})
+	prep := Prepare(line)
+	expect(t, prep.polys == nil)
+	expect(t, prep.Contains(PO(5, 5)) == line.Contains(PO(5, 5)))
+	expect(t, prep.Intersects(PO(5, 6)) == line.Intersects(PO(5, 6)))
+	expect(t, prep.Distance(PO(5, 6)) == line.Distance(PO(5, 6)))
+	expect(t, Prepare(NewPolygon(&geometry.Poly{})).polys == nil)
+}
+
+func BenchmarkPrepared(b *testing.B) {
+	// a geofence with a thousand vertices and a hole
+	circle := func(cx, cy, r float64, n int) []geometry.Point {
+		var points []geometry.Point
+		for i := 0; i < n; i++ {
+			a := float64(i) * 2 * math.Pi / float64(n)
+			points = append(points, P(cx+math.Cos(a)*r, cy+math.Sin(a)*r))
+		}
+		return append(points, points[0])
+	}
+	poly := NewPolygon(geometry.NewPoly(circle(0, 0, 10, 1000),
+		[][]geometry.Point{circle(2, 2, 2, 100)}, nil))
+	points := make([]Object, 10000)
+	for i := range points {
+		points[i] = NewSimplePoint(P(rand.Float64()*24-12,
+			rand.Float64()*24-12))
+	}
+	for _, prepared := range []bool{false, true} {
+		var obj Object = poly
+		name := "Polygon"
+		if prepared {
+			obj = Prepare(poly)
+			name = "Prepared"
+		}
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				obj.Contains(points[i%len(points)])
+			}
+		})
+	}
+}
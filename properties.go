@@ -0,0 +1,177 @@
+package geojson
+
+import (
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// propertiesIndexSize is the number of properties at which a map is used to
+// find a property, instead of a scan of the keys.
+const propertiesIndexSize = 16
+
+// Properties are the parsed "properties" member of a Feature. The values
+// are parsed once, and a value is found without parsing the members again.
+// A nil Properties has no values.
+type Properties struct {
+	raw    gjson.Result
+	keys   []string
+	values []gjson.Result
+	index  map[string]int
+}
+
+var emptyProperties = &Properties{}
+
+func newProperties(raw gjson.Result) *Properties {
+	p := &Properties{raw: raw}
+	if !raw.IsObject() {
+		return p
+	}
+	raw.ForEach(func(key, value gjson.Result) bool {
+		k := key.String()
+		if p.find(k) == -1 {
+			p.keys = append(p.keys, k)
+			p.values = append(p.values, value)
+			if p.index != nil || len(p.keys) == propertiesIndexSize {
+				if p.index == nil {
+					p.index = make(map[string]int, len(p.keys))
+					for i, k := range p.keys {
+						p.index[k] = i
+					}
+				}
+				p.index[k] = len(p.keys) - 1
+			}
+		}
+		return true
+	})
+	return p
+}
+
+// find returns the index of a key, or -1 when missing
+func (p *Properties) find(key string) int {
+	if p.index != nil {
+		if i, ok := p.index[key]; ok {
+			return i
+		}
+		return -1
+	}
+	for i, k := range p.keys {
+		if k == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// Len returns the number of properties.
+func (p *Properties) Len() int {
+	if p == nil {
+		return 0
+	}
+	return len(p.keys)
+}
+
+// Raw returns the raw JSON of the properties, or an empty string when the
+// Feature does not have properties.
+func (p *Properties) Raw() string {
+	if p == nil {
+		return ""
+	}
+	return p.raw.Raw
+}
+
+// ForEach iterates over the properties in the order of the JSON.
+func (p *Properties) ForEach(iter func(key string, value gjson.Result) bool) {
+	if p == nil {
+		return
+	}
+	for i, key := range p.keys {
+		if !iter(key, p.values[i]) {
+			return
+		}
+	}
+}
+
+// Get returns the value at a path, which uses the GJSON path syntax, such
+// as "name" or "address.city".
+func (p *Properties) Get(path string) gjson.Result {
+	if p == nil {
+		return gjson.Result{}
+	}
+	key, rest := path, ""
+	if i := strings.IndexAny(path, `.\*?|#@`); i != -1 {
+		if path[i] != '.' {
+			// the first key has special characters
+			return p.raw.Get(path)
+		}
+		key, rest = path[:i], path[i+1:]
+	}
+	i := p.find(key)
+	if i == -1 {
+		return gjson.Result{}
+	}
+	if rest == "" {
+		return p.values[i]
+	}
+	return p.values[i].Get(rest)
+}
+
+// String returns the string at a path, and false when the value is missing
+// or is not a string.
+func (p *Properties) String(path string) (string, bool) {
+	value := p.Get(path)
+	if value.Type != gjson.String {
+		return "", false
+	}
+	return value.Str, true
+}
+
+// Float returns the number at a path, and false when the value is missing
+// or is not a number.
+func (p *Properties) Float(path string) (float64, bool) {
+	value := p.Get(path)
+	if value.Type != gjson.Number {
+		return 0, false
+	}
+	return value.Num, true
+}
+
+// Int returns the integer at a path, and false when the value is missing or
+// is not a number without a fraction.
+func (p *Properties) Int(path string) (int64, bool) {
+	value := p.Get(path)
+	if value.Type != gjson.Number || value.Num != float64(value.Int()) {
+		return 0, false
+	}
+	return value.Int(), true
+}
+
+// Bool returns the boolean at a path, and false when the value is missing or
+// is not a boolean.
+func (p *Properties) Bool(path string) (bool, bool) {
+	value := p.Get(path)
+	if value.Type != gjson.True && value.Type != gjson.False {
+		return false, false
+	}
+	return value.Type == gjson.True, true
+}
+
+// Object returns the object at a path as Properties, and false when the
+// value is missing or is not an object.
+func (p *Properties) Object(path string) (*Properties, bool) {
+	value := p.Get(path)
+	if !value.IsObject() {
+		return nil, false
+	}
+	return newProperties(value), true
+}
+
+// Array returns the values of the array at a path, and false when the value
+// is missing or is not an array.
+func (p *Properties) Array(path string) ([]gjson.Result, bool) {
+	value := p.Get(path)
+	if !value.IsArray() {
+		return nil, false
+	}
+	return value.Array(), true
+}
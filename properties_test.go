@@ -0,0 +1,112 @@
+package geojson
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestPropertiesGetters(t *testing.T) {
+	f := expectJSON(t, `{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"id":"a1","properties":{"name":"Phoenix","pop":1608139,"area":1340.6,"capital":true,"tags":["a","b"],"address":{"city":"Phoenix","zip":"85001"},"name":"dup","a.b":1}}`, nil).(*Feature)
+	expect(t, f.ID() == "a1")
+	props := f.Properties()
+	expect(t, props.Len() == 7)
+	s, ok := props.String("name")
+	expect(t, ok && s == "Phoenix")
+	_, ok = props.String("pop")
+	expect(t, !ok)
+	n, ok := props.Int("pop")
+	expect(t, ok && n == 1608139)
+	_, ok = props.Int("area")
+	expect(t, !ok)
+	x, ok := props.Float("area")
+	expect(t, ok && x == 1340.6)
+	_, ok = props.Float("missing")
+	expect(t, !ok)
+	b, ok := props.Bool("capital")
+	expect(t, ok && b)
+	_, ok = props.Bool("name")
+	expect(t, !ok)
+	arr, ok := props.Array("tags")
+	expect(t, ok && len(arr) == 2 && arr[1].String() == "b")
+	_, ok = props.Array("address")
+	expect(t, !ok)
+	addr, ok := props.Object("address")
+	expect(t, ok && addr.Len() == 2)
+	s, ok = addr.String("zip")
+	expect(t, ok && s == "85001")
+	_, ok = props.Object("tags")
+	expect(t, !ok)
+
+	// paths
+	s, ok = props.String("address.city")
+	expect(t, ok && s == "Phoenix")
+	s, ok = props.String("tags.0")
+	expect(t, ok && s == "a")
+	expect(t, props.Get("tags.#").Int() == 2)
+	expect(t, props.Get(`a\.b`).Int() == 1)
+	expect(t, !props.Get("name.first").Exists())
+
+	var keys []string
+	props.ForEach(func(key string, value gjson.Result) bool {
+		keys = append(keys, key)
+		return key != "tags"
+	})
+	expect(t, len(keys) == 5 && keys[4] == "tags")
+	expect(t, gjson.Get(props.Raw(), "pop").Int() == 1608139)
+
+	// numeric ids, and features without an id or properties
+	f = expectJSON(t, `{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"id":15,"properties":{}}`, nil).(*Feature)
+	expect(t, f.ID() == int64(15))
+	for _, id := range []struct {
+		raw   string
+		value interface{}
+	}{
+		{"12345678901234567", int64(12345678901234567)},
+		{"-4", int64(-4)},
+		{"18446744073709551615", uint64(18446744073709551615)},
+		{"1.5", float64(1.5)},
+		{"2e3", float64(2000)},
+	} {
+		f := NewFeature(PO(1, 2), `{"id":`+id.raw+`}`)
+		expect(t, f.ID() == id.value)
+	}
+	expect(t, f.Properties().Len() == 0 && f.Properties().Raw() == "{}")
+	_, ok = f.Properties().String("name")
+	expect(t, !ok)
+	expect(t, NewFeature(PO(1, 2), "").Properties().Raw() == "")
+	f = NewFeature(PO(1, 2), `{"id":[1],"properties":true}`)
+	expect(t, f.ID() == nil)
+	expect(t, f.Properties().Len() == 0 && f.Properties().Raw() == "true")
+	f = NewFeature(PO(1, 2), `{"properties":{"type":"Circle"}}`)
+	s, ok = f.Properties().String("type")
+	expect(t, ok && s == "Circle")
+	var nilProps *Properties
+	expect(t, nilProps.Len() == 0 && !nilProps.Get("a").Exists())
+}
+
+func TestPropertiesIndex(t *testing.T) {
+	json := `{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":{`
+	for i := 0; i < 100; i++ {
+		if i > 0 {
+			json += ","
+		}
+		json += `"p` + strconv.Itoa(i) + `":` + strconv.Itoa(i)
+	}
+	json += `,"p5":-1}}`
+	props := expectJSON(t, json, nil).(*Feature).Properties()
+	expect(t, props.Len() == 100)
+	for i := 0; i < 100; i++ {
+		n, ok := props.Int("p" + strconv.Itoa(i))
+		expect(t, ok && n == int64(i))
+	}
+	_, ok := props.Int("p100")
+	expect(t, !ok)
+
+	// the cache is kept when the geometry is rebuilt
+	f := Densify(expectJSON(t, `{"type":"Feature","id":"x","geometry":{"type":"LineString","coordinates":[[0,0],[2,0]]},"properties":{"a":1}}`, nil), 1, DensifyDegrees, nil).(*Feature)
+	expect(t, f.ID() == "x")
+	n, ok := f.Properties().Int("a")
+	expect(t, ok && n == 1)
+}
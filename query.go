@@ -0,0 +1,28 @@
+package geojson
+
+// Query returns the children of a collection that match a spatial predicate
+// and a filter. The spatial index of the collection is searched first, when
+// it has one, and the filter is only tested on children that match the
+// predicate. A nil predicate or filter, or a predicate without a target,
+// matches all children.
+func Query(collection Collection, pred *Predicate, filter *Filter) []Object {
+	if pred != nil && pred.Target == nil {
+		pred = nil
+	}
+	var objs []Object
+	iter := func(child Object) bool {
+		if (pred == nil || pred.Match(child)) &&
+			(filter == nil || filter.Match(child)) {
+			objs = append(objs, child)
+		}
+		return true
+	}
+	if pred == nil {
+		for _, child := range collection.Children() {
+			iter(child)
+		}
+	} else {
+		collection.Search(pred.searchRect(pred.Target), iter)
+	}
+	return objs
+}
@@ -0,0 +1,86 @@
+package geojson
+
+import (
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/tidwall/geojson/geometry"
+)
+
+func TestQuery(t *testing.T) {
+	// a grid of features with a value, indexed and not
+	var features []Object
+	for x := 0; x < 20; x++ {
+		for y := 0; y < 20; y++ {
+			features = append(features, NewFeature(PO(float64(x), float64(y)),
+				`{"properties":{"n":`+strconv.Itoa(x*20+y)+`}}`))
+		}
+	}
+	features = append(features, NewFeature(NewPolygon(geometry.NewPoly(
+		[]geometry.Point{P(0, 0), P(30, 0), P(30, 30), P(0, 30), P(0, 0)},
+		nil, nil)), `{"properties":{"n":"big"}}`))
+	indexed := NewFeatureCollection(features)
+	expect(t, indexed.Indexed())
+	obj, err := Parse(indexed.JSON(), &ParseOptions{})
+	expect(t, err == nil)
+	unindexed := obj.(*FeatureCollection)
+	expect(t, !unindexed.Indexed())
+
+	values := func(objs []Object) []string {
+		var vals []string
+		for _, obj := range objs {
+			vals = append(vals, obj.(*Feature).Properties().Get("n").String())
+		}
+		sort.Strings(vals)
+		return vals
+	}
+	numbered, err := CompileFilter(`n between 0 and 399 and not n in (0, 2, 4)`)
+	expect(t, err == nil)
+	area := RO(2.5, 2.5, 4.5, 4.5)
+	for _, fc := range []Collection{indexed, unindexed} {
+		objs := Query(fc, &Predicate{Target: area}, nil)
+		expect(t, len(values(objs)) == 5)
+		objs = Query(fc, &Predicate{Op: PredicateWithin, Target: area}, nil)
+		expect(t, len(objs) == 4)
+		objs = Query(fc, &Predicate{Op: PredicateWithin, Target: area}, numbered)
+		expect(t, len(objs) == 4)
+		objs = Query(fc, &Predicate{Op: PredicateContains, Target: area}, nil)
+		expect(t, len(objs) == 1 && values(objs)[0] == "big")
+		objs = Query(fc, nil, numbered)
+		expect(t, len(objs) == 397)
+		expect(t, len(Query(fc, nil, nil)) == 401)
+		// a predicate without a target matches all children
+		expect(t, len(Query(fc, &Predicate{}, nil)) == 401)
+		expect(t, len(Query(fc, &Predicate{}, numbered)) == 397)
+		expect(t, (&Predicate{Op: PredicateContains}).Match(PO(1, 2)))
+
+		// within a distance of a center
+		pred := &Predicate{Op: PredicateWithinDistance, Target: PO(10, 10),
+			Meters: 120000}
+		objs = Query(fc, pred, nil)
+		for _, obj := range fc.Children() {
+			found := false
+			for _, match := range objs {
+				found = found || match == obj
+			}
+			expect(t, found == (obj.Distance(PO(10, 10)) <= 120000))
+		}
+		expect(t, len(objs) == 5)
+	}
+}
+
+func TestQueryShapes(t *testing.T) {
+	obj, err := Parse(`{"type":"FeatureCollection","features":[
+		{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":{"name":"a"}},
+		{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":{"type":"Circle","radius":100,"name":"a"}},
+		{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":{"type":"Circle","radius":100,"name":"b"}}
+	]}`, nil)
+	expect(t, err == nil)
+	filter, err := CompileFilter(`name = 'a'`)
+	expect(t, err == nil)
+	objs := Query(obj.(Collection), nil, filter)
+	expect(t, len(objs) == 2)
+	_, ok := objs[1].(*Circle)
+	expect(t, ok)
+}
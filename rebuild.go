@@ -0,0 +1,195 @@
+package geojson
+
+import (
+	"strconv"
+
+	"github.com/tidwall/geojson/geometry"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// seriesRebuilder returns new points for the points of a line or ring. The
+// values are the extra coordinate values, such as Z and M, with dims values
+// per point. The returned values must have the same layout as the points.
+type seriesRebuilder func(points []geometry.Point, values []float64, dims int,
+	closed bool) ([]geometry.Point, []float64)
+
+// rebuildObject returns a copy of the object where the points of every
+// point, line, and ring have been replaced by the rebuilder. Feature members
+// and foreign members are kept, except for a "bbox", which is the rect of
+// the new object. Shapes, such as circles, are rebuilt from
+// their polygon approximations when primatives is true, otherwise they are
+// returned as is.
+func rebuildObject(obj Object, rebuild seriesRebuilder, primatives bool,
+	opts *geometry.IndexOptions,
+) Object {
+	switch g := obj.(type) {
+	case *Point:
+		values, dims := extraValues(g.extra, 0, 1)
+		points, values := rebuild([]geometry.Point{g.base}, values, dims, false)
+		n := &Point{base: points[0], extra: rebuildExtra(g.extra, values)}
+		n.extra = rebuildBBox(n.extra, n)
+		return n
+	case *SimplePoint:
+		points, _ := rebuild([]geometry.Point{g.Point}, nil, 0, false)
+		return NewSimplePoint(points[0])
+	case *Rect:
+		points, _ := rebuild(seriesPoints(g.base), nil, 0, true)
+		return NewPolygon(geometry.NewPoly(points, nil, opts))
+	case *LineString:
+		values, dims := extraValues(g.extra, 0, g.base.NumPoints())
+		points, values := rebuild(seriesPoints(&g.base), values, dims, false)
+		n := &LineString{
+			base:  *geometry.NewLine(points, opts),
+			extra: rebuildExtra(g.extra, values),
+		}
+		n.extra = rebuildBBox(n.extra, n)
+		return n
+	case *Polygon:
+		return rebuildPolygon(g, rebuild, opts)
+	case *Feature:
+		n := &Feature{
+			base:  rebuildObject(g.base, rebuild, primatives, opts),
+			extra: g.extra,
+			id:    g.id,
+			props: g.props,
+		}
+		n.extra = rebuildBBox(n.extra, n)
+		return n
+	case *MultiPoint:
+		n := new(MultiPoint)
+		n.collection = rebuildCollection(&g.collection, rebuild, primatives,
+			opts)
+		return n
+	case *MultiLineString:
+		n := new(MultiLineString)
+		n.collection = rebuildCollection(&g.collection, rebuild, primatives,
+			opts)
+		return n
+	case *MultiPolygon:
+		n := new(MultiPolygon)
+		n.collection = rebuildCollection(&g.collection, rebuild, primatives,
+			opts)
+		return n
+	case *GeometryCollection:
+		n := new(GeometryCollection)
+		n.collection = rebuildCollection(&g.collection, rebuild, primatives,
+			opts)
+		return n
+	case *FeatureCollection:
+		n := new(FeatureCollection)
+		n.collection = rebuildCollection(&g.collection, rebuild, primatives,
+			opts)
+		return n
+	case interface{ Primative() Object }:
+		if primatives {
+			return rebuildObject(g.Primative(), rebuild, primatives, opts)
+		}
+	}
+	return obj
+}
+
+func rebuildPolygon(g *Polygon, rebuild seriesRebuilder,
+	opts *geometry.IndexOptions,
+) Object {
+	rings := append([]geometry.Ring{g.base.Exterior}, g.base.Holes...)
+	var pidx int
+	var allValues []float64
+	newRings := make([][]geometry.Point, len(rings))
+	for i, ring := range rings {
+		values, dims := extraValues(g.extra, pidx, ring.NumPoints())
+		pidx += ring.NumPoints()
+		newRings[i], values = rebuild(seriesPoints(ring), values, dims, true)
+		allValues = append(allValues, values...)
+	}
+	n := &Polygon{
+		base:  *geometry.NewPoly(newRings[0], newRings[1:], opts),
+		extra: rebuildExtra(g.extra, allValues),
+	}
+	n.extra = rebuildBBox(n.extra, n)
+	return n
+}
+
+func rebuildCollection(g *collection, rebuild seriesRebuilder,
+	primatives bool, opts *geometry.IndexOptions,
+) collection {
+	var n collection
+	n.extra = g.extra
+	n.children = make([]Object, len(g.children))
+	for i, child := range g.children {
+		n.children[i] = rebuildObject(child, rebuild, primatives, opts)
+	}
+	// the children are indexed when the original children are indexed
+	var indexChildren int
+	if g.tree != nil {
+		indexChildren = 1
+	}
+	n.parseInitRectIndex(&ParseOptions{IndexChildren: indexChildren})
+	n.extra = rebuildBBox(n.extra, &n)
+	return n
+}
+
+// extraValues returns the extra coordinate values for n points starting at
+// point index pidx.
+func extraValues(ex *extra, pidx, n int) (values []float64, dims int) {
+	if ex == nil || ex.dims == 0 {
+		return nil, 0
+	}
+	dims = int(ex.dims)
+	return ex.values[pidx*dims : (pidx+n)*dims], dims
+}
+
+// rebuildExtra returns a copy of the extra with new coordinate values.
+func rebuildExtra(ex *extra, values []float64) *extra {
+	if ex == nil {
+		return nil
+	}
+	nex := *ex
+	if ex.dims != 0 {
+		nex.values = values
+	}
+	return &nex
+}
+
+// rebuildBBox returns a copy of the extra where the "bbox" member is the rect
+// of the rebuilt object. A bbox with more than two dimensions, or of an empty
+// object, is removed.
+func rebuildBBox(ex *extra, obj interface {
+	Empty() bool
+	Rect() geometry.Rect
+}) *extra {
+	if ex == nil || ex.members == "" {
+		return ex
+	}
+	bbox := gjson.Get(ex.members, "bbox")
+	if !bbox.Exists() {
+		return ex
+	}
+	nex := *ex
+	if len(bbox.Array()) == 4 && !obj.Empty() {
+		rect := obj.Rect()
+		var raw []byte
+		raw = append(raw, '[')
+		raw = strconv.AppendFloat(raw, rect.Min.X, 'f', -1, 64)
+		raw = append(raw, ',')
+		raw = strconv.AppendFloat(raw, rect.Min.Y, 'f', -1, 64)
+		raw = append(raw, ',')
+		raw = strconv.AppendFloat(raw, rect.Max.X, 'f', -1, 64)
+		raw = append(raw, ',')
+		raw = strconv.AppendFloat(raw, rect.Max.Y, 'f', -1, 64)
+		raw = append(raw, ']')
+		nex.members, _ = sjson.SetRaw(ex.members, "bbox", string(raw))
+	} else {
+		nex.members, _ = sjson.Delete(ex.members, "bbox")
+		var more bool
+		gjson.Parse(nex.members).ForEach(func(_, _ gjson.Result) bool {
+			more = true
+			return false
+		})
+		if !more {
+			// without other members
+			nex.members = ""
+		}
+	}
+	return &nex
+}
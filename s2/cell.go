@@ -0,0 +1,206 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package s2
+
+import (
+	"math"
+
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/geojson/geometry"
+)
+
+// Vertices returns the four corners of the cell in counter-clockwise order,
+// as lon/lat points.
+func (id CellID) Vertices() [4]geometry.Point {
+	var vertices [4]geometry.Point
+	face, uMin, uMax, vMin, vMax := id.uvBounds()
+	us := [4]float64{uMin, uMax, uMax, uMin}
+	vs := [4]float64{vMin, vMin, vMax, vMax}
+	for i := 0; i < 4; i++ {
+		lat, lon := xyzToLatLng(faceUVToXYZ(face, us[i], vs[i]))
+		vertices[i] = geometry.Point{X: lon, Y: lat}
+	}
+	return vertices
+}
+
+// boundary returns points along the edges of the cell in counter-clockwise
+// order, with steps points per edge. The first point is not repeated at
+// the end.
+func (id CellID) boundary(steps int) []geometry.Point {
+	face, uMin, uMax, vMin, vMax := id.uvBounds()
+	us := [5]float64{uMin, uMax, uMax, uMin, uMin}
+	vs := [5]float64{vMin, vMin, vMax, vMax, vMin}
+	points := make([]geometry.Point, 0, steps*4)
+	for i := 0; i < 4; i++ {
+		for k := 0; k < steps; k++ {
+			t := float64(k) / float64(steps)
+			u := us[i] + (us[i+1]-us[i])*t
+			v := vs[i] + (vs[i+1]-vs[i])*t
+			lat, lon := xyzToLatLng(faceUVToXYZ(face, u, v))
+			points = append(points, geometry.Point{X: lon, Y: lat})
+		}
+	}
+	return points
+}
+
+// edgeSteps returns the number of points per edge that are needed for the
+// straight lon/lat edges of a polygon to follow the geodesic edges of a cell.
+func edgeSteps(level int) int {
+	if level >= 10 {
+		return 1
+	}
+	return 8
+}
+
+// pole returns 1 when the cell holds the north pole, -1 when it holds the
+// south pole, or 0 when it holds neither.
+func (id CellID) pole() int {
+	face, uMin, uMax, vMin, vMax := id.uvBounds()
+	if (face != 2 && face != 5) || uMin > 0 || uMax < 0 || vMin > 0 ||
+		vMax < 0 {
+		return 0
+	}
+	if face == 2 {
+		return 1
+	}
+	return -1
+}
+
+// Polygon returns the cell as a polygon. Edges of large cells are
+// subdivided to follow the cell's geodesic edges. The longitudes of a cell
+// that crosses the antimeridian continue past 180 or -180 so that the polygon
+// stays in one piece, and a pole inside of the cell becomes an edge along
+// the top or bottom of the map.
+func (id CellID) Polygon() *geojson.Polygon {
+	raw := id.boundary(edgeSteps(id.Level()))
+	points := make([]geometry.Point, 0, len(raw)+4)
+	for i, point := range raw {
+		if math.Abs(point.Y) == 90 {
+			// the longitude of a pole is taken from the edges on each
+			// side of it
+			prev := raw[(i+len(raw)-1)%len(raw)]
+			next := raw[(i+1)%len(raw)]
+			points = append(points,
+				geometry.Point{X: prev.X, Y: point.Y},
+				geometry.Point{X: next.X, Y: point.Y})
+			continue
+		}
+		points = append(points, point)
+	}
+	for i := 1; i < len(points); i++ {
+		points[i].X = unwrap(points[i].X, points[i-1].X)
+	}
+	first, last := points[0], points[len(points)-1]
+	if pole := id.pole(); pole != 0 {
+		if closing := unwrap(first.X, last.X); closing != first.X {
+			points = aroundPole(points, closing-first.X, float64(pole)*90)
+			return geojson.NewPolygon(geometry.NewPoly(points, nil, nil))
+		}
+	}
+	points = append(points, first)
+	return geojson.NewPolygon(geometry.NewPoly(points, nil, nil))
+}
+
+// aroundPole returns the ring for the points of a cell that goes around a
+// pole, where the longitudes of the points turn by shift degrees. The ring
+// runs from -180 to 180 and is closed along the pole's latitude.
+func aroundPole(points []geometry.Point, shift, lat float64,
+) []geometry.Point {
+	// three turns of the points, in increasing longitude
+	var chain []geometry.Point
+	for turn := 0; turn < 3; turn++ {
+		for _, point := range points {
+			point.X += shift * float64(turn)
+			chain = append(chain, point)
+		}
+	}
+	if shift < 0 {
+		for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+			chain[i], chain[j] = chain[j], chain[i]
+		}
+	}
+	// start the chain before -180
+	offset := 360 * math.Floor((chain[0].X+540)/360)
+	for i := range chain {
+		chain[i].X -= offset
+	}
+	interpolate := func(a, b geometry.Point, lon float64) geometry.Point {
+		t := (lon - a.X) / (b.X - a.X)
+		return geometry.Point{X: lon, Y: a.Y + (b.Y-a.Y)*t}
+	}
+	var ring []geometry.Point
+	for i := 1; i < len(chain); i++ {
+		a, b := chain[i-1], chain[i]
+		if a.X < -180 && b.X >= -180 {
+			ring = append(ring, interpolate(a, b, -180))
+		}
+		if len(ring) == 0 {
+			continue
+		}
+		if b.X >= 180 {
+			ring = append(ring, interpolate(a, b, 180))
+			break
+		}
+		if b.X > -180 {
+			ring = append(ring, b)
+		}
+	}
+	return append(ring, geometry.Point{X: 180, Y: lat},
+		geometry.Point{X: -180, Y: lat}, ring[0])
+}
+
+// unwrap returns the longitude moved by whole turns to be within 180 degrees
+// of another longitude.
+func unwrap(lon, near float64) float64 {
+	return lon + 360*math.Round((near-lon)/360)
+}
+
+// Rect returns a lon/lat rectangle that holds the entire cell. Cells that
+// cross the antimeridian or hold a pole span all longitudes.
+func (id CellID) Rect() geometry.Rect {
+	points := id.boundary(16)
+	rect := geometry.Rect{Min: points[0], Max: points[0]}
+	for _, point := range points[1:] {
+		rect.Min.X = math.Min(rect.Min.X, point.X)
+		rect.Min.Y = math.Min(rect.Min.Y, point.Y)
+		rect.Max.X = math.Max(rect.Max.X, point.X)
+		rect.Max.Y = math.Max(rect.Max.Y, point.Y)
+	}
+	// pad for the curve of the edges between the points
+	padX := (rect.Max.X-rect.Min.X)*0.01 + 1e-12
+	padY := (rect.Max.Y-rect.Min.Y)*0.01 + 1e-12
+	rect.Min.X -= padX
+	rect.Min.Y = math.Max(-90, rect.Min.Y-padY)
+	rect.Max.X += padX
+	rect.Max.Y = math.Min(90, rect.Max.Y+padY)
+	switch pole := id.pole(); {
+	case pole > 0:
+		rect.Max.Y = 90
+	case pole < 0:
+		rect.Min.Y = -90
+	}
+	if id.pole() != 0 || rect.Max.X-rect.Min.X > 180 {
+		rect.Min.X, rect.Max.X = -180, 180
+	}
+	rect.Min.X = math.Max(-180, rect.Min.X)
+	rect.Max.X = math.Min(180, rect.Max.X)
+	return rect
+}
+
+// crosses returns true when the cell holds a pole or crosses the
+// antimeridian, which are the cells that can't be tested as lon/lat polygons.
+func (id CellID) crosses() bool {
+	if id.pole() != 0 {
+		return true
+	}
+	points := id.boundary(1)
+	for i := range points {
+		next := points[(i+1)%len(points)]
+		if math.Abs(next.X-points[i].X) > 180 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,319 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package s2
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// MaxLevel is the level of the smallest cells, which are called leaf cells
+const MaxLevel = 30
+
+const (
+	numFaces = 6
+	posBits  = 2*MaxLevel + 1
+	maxSize  = 1 << MaxLevel
+
+	swapMask   = 1
+	invertMask = 2
+)
+
+// ErrInvalidToken is returned when a token is not the hex form of a valid
+// cell id.
+var ErrInvalidToken = errors.New("invalid token")
+
+// CellID is the 64-bit id of an S2 cell. The top three bits hold the cube
+// face, the following bits hold the position of the cell along the face's
+// Hilbert curve, and the lowest set bit marks the level of the cell.
+type CellID uint64
+
+// posToIJ maps an orientation and a Hilbert curve position to the i and j
+// bits of a child cell, and ijToPos is the inverse.
+var (
+	posToIJ = [4][4]int{
+		{0, 1, 3, 2}, // canonical order
+		{0, 2, 3, 1}, // axes swapped
+		{3, 2, 0, 1}, // bits inverted
+		{3, 1, 0, 2}, // swapped and inverted
+	}
+	ijToPos = [4][4]int{
+		{0, 1, 3, 2},
+		{0, 3, 1, 2},
+		{2, 3, 1, 0},
+		{2, 1, 3, 0},
+	}
+	posToOrientation = [4]int{swapMask, 0, 0, invertMask | swapMask}
+)
+
+// CellIDFromFace returns the level 0 cell for a cube face, from 0 to 5
+func CellIDFromFace(face int) CellID {
+	return CellID(uint64(face)<<posBits + lsbForLevel(0))
+}
+
+// CellIDFromLatLng returns the leaf cell that holds a point
+func CellIDFromLatLng(lat, lon float64) CellID {
+	x, y, z := latLngToXYZ(lat, lon)
+	face, u, v := xyzToFaceUV(x, y, z)
+	i := stToIJ(uvToST(u))
+	j := stToIJ(uvToST(v))
+	return cellIDFromFaceIJ(face, i, j)
+}
+
+// CellIDFromToken returns the cell for a token that was made by Token
+func CellIDFromToken(token string) (CellID, error) {
+	if len(token) == 0 || len(token) > 16 {
+		return 0, ErrInvalidToken
+	}
+	n, err := strconv.ParseUint(token, 16, 64)
+	if err != nil {
+		return 0, ErrInvalidToken
+	}
+	id := CellID(n << uint(4*(16-len(token))))
+	if !id.IsValid() {
+		return 0, ErrInvalidToken
+	}
+	return id, nil
+}
+
+func cellIDFromFaceIJ(face, i, j int) CellID {
+	n := uint64(face) << (posBits - 1)
+	orientation := face & swapMask
+	for k := MaxLevel - 1; k >= 0; k-- {
+		ij := ((i>>uint(k))&1)<<1 | (j>>uint(k))&1
+		pos := ijToPos[orientation][ij]
+		n |= uint64(pos) << uint(2*k)
+		orientation ^= posToOrientation[pos]
+	}
+	return CellID(n*2 + 1)
+}
+
+func lsbForLevel(level int) uint64 {
+	return 1 << uint(2*(MaxLevel-level))
+}
+
+func (id CellID) lsb() uint64 {
+	return uint64(id) & -uint64(id)
+}
+
+// IsValid returns true when the id is a cell on one of the six faces
+func (id CellID) IsValid() bool {
+	return id.Face() < numFaces && id.lsb()&0x1555555555555555 != 0
+}
+
+// Face returns the cube face of the cell, from 0 to 5
+func (id CellID) Face() int {
+	return int(uint64(id) >> posBits)
+}
+
+// Level returns the level of the cell, from 0 for a face to MaxLevel for a
+// leaf cell.
+func (id CellID) Level() int {
+	return MaxLevel - bits.TrailingZeros64(uint64(id))>>1
+}
+
+// IsLeaf returns true for cells at MaxLevel
+func (id CellID) IsLeaf() bool {
+	return uint64(id)&1 != 0
+}
+
+// Parent returns the cell at a level that holds this cell. The level must
+// not be greater than the level of the cell.
+func (id CellID) Parent(level int) CellID {
+	lsb := lsbForLevel(level)
+	return CellID(uint64(id)&-lsb | lsb)
+}
+
+// ImmediateParent returns the cell at the level above that holds this cell
+func (id CellID) ImmediateParent() CellID {
+	lsb := id.lsb() << 2
+	return CellID(uint64(id)&-lsb | lsb)
+}
+
+// Children returns the four cells at the next level that make up the cell,
+// in Hilbert curve order. Leaf cells do not have children.
+func (id CellID) Children() [4]CellID {
+	lsb := id.lsb()
+	first := uint64(id) - lsb + lsb>>2
+	return [4]CellID{
+		CellID(first),
+		CellID(first + lsb>>1),
+		CellID(first + lsb),
+		CellID(first + lsb + lsb>>1),
+	}
+}
+
+// RangeMin returns the first leaf cell in the cell
+func (id CellID) RangeMin() CellID {
+	return CellID(uint64(id) - (id.lsb() - 1))
+}
+
+// RangeMax returns the last leaf cell in the cell
+func (id CellID) RangeMax() CellID {
+	return CellID(uint64(id) + (id.lsb() - 1))
+}
+
+// Contains returns true when the other cell is inside of this cell
+func (id CellID) Contains(other CellID) bool {
+	return other >= id.RangeMin() && other <= id.RangeMax()
+}
+
+// Intersects returns true when the cells share any leaf cells
+func (id CellID) Intersects(other CellID) bool {
+	return other.RangeMin() <= id.RangeMax() &&
+		other.RangeMax() >= id.RangeMin()
+}
+
+// Token returns the cell id as a compact hex string, with trailing zeros
+// removed.
+func (id CellID) Token() string {
+	if id == 0 {
+		return "X"
+	}
+	s := strconv.FormatUint(uint64(id), 16)
+	s = strings.Repeat("0", 16-len(s)) + s
+	return strings.TrimRight(s, "0")
+}
+
+// String returns the face and the child positions of the cell, such as
+// "3/0213".
+func (id CellID) String() string {
+	if !id.IsValid() {
+		return "Invalid: " + strconv.FormatUint(uint64(id), 16)
+	}
+	var b strings.Builder
+	b.WriteString(strconv.Itoa(id.Face()))
+	b.WriteByte('/')
+	for level := 1; level <= id.Level(); level++ {
+		pos := uint64(id) >> uint(posBits-2*level) & 3
+		b.WriteByte(byte('0' + pos))
+	}
+	return b.String()
+}
+
+// LatLng returns the center of the cell
+func (id CellID) LatLng() (lat, lon float64) {
+	face, i, j := id.faceIJ()
+	half := float64(int(1)<<uint(MaxLevel-id.Level())) / 2
+	u := stToUV((float64(i) + half) / maxSize)
+	v := stToUV((float64(j) + half) / maxSize)
+	return xyzToLatLng(faceUVToXYZ(face, u, v))
+}
+
+// faceIJ returns the face and the lowest i and j leaf coordinates of the
+// cell.
+func (id CellID) faceIJ() (face, i, j int) {
+	face = id.Face()
+	orientation := face & swapMask
+	level := id.Level()
+	for k := MaxLevel - 1; k >= MaxLevel-level; k-- {
+		pos := int(uint64(id)>>uint(2*k+1)) & 3
+		ij := posToIJ[orientation][pos]
+		i |= (ij >> 1) << uint(k)
+		j |= (ij & 1) << uint(k)
+		orientation ^= posToOrientation[pos]
+	}
+	return face, i, j
+}
+
+// uvBounds returns the range of the cell's u and v coordinates on its face
+func (id CellID) uvBounds() (face int, uMin, uMax, vMin, vMax float64) {
+	face, i, j := id.faceIJ()
+	size := 1 << uint(MaxLevel-id.Level())
+	uMin = stToUV(float64(i) / maxSize)
+	uMax = stToUV(float64(i+size) / maxSize)
+	vMin = stToUV(float64(j) / maxSize)
+	vMax = stToUV(float64(j+size) / maxSize)
+	return face, uMin, uMax, vMin, vMax
+}
+
+// The cube faces are projected with the quadratic transform, which keeps
+// cells at the same level close to the same size.
+
+func stToUV(s float64) float64 {
+	if s >= 0.5 {
+		return (1 / 3.0) * (4*s*s - 1)
+	}
+	return (1 / 3.0) * (1 - 4*(1-s)*(1-s))
+}
+
+func uvToST(u float64) float64 {
+	if u >= 0 {
+		return 0.5 * math.Sqrt(1+3*u)
+	}
+	return 1 - 0.5*math.Sqrt(1-3*u)
+}
+
+func stToIJ(s float64) int {
+	return int(math.Max(0, math.Min(maxSize-1, math.Floor(maxSize*s))))
+}
+
+func latLngToXYZ(lat, lon float64) (x, y, z float64) {
+	sinLat, cosLat := math.Sincos(lat * math.Pi / 180)
+	sinLon, cosLon := math.Sincos(lon * math.Pi / 180)
+	return cosLat * cosLon, cosLat * sinLon, sinLat
+}
+
+func xyzToLatLng(x, y, z float64) (lat, lon float64) {
+	lat = math.Atan2(z, math.Sqrt(x*x+y*y)) * 180 / math.Pi
+	lon = math.Atan2(y, x) * 180 / math.Pi
+	return lat, lon
+}
+
+func faceUVToXYZ(face int, u, v float64) (x, y, z float64) {
+	switch face {
+	case 0:
+		return 1, u, v
+	case 1:
+		return -u, 1, v
+	case 2:
+		return -u, -v, 1
+	case 3:
+		return -1, -v, -u
+	case 4:
+		return v, -1, -u
+	default:
+		return v, u, -1
+	}
+}
+
+func xyzToFaceUV(x, y, z float64) (face int, u, v float64) {
+	ax, ay, az := math.Abs(x), math.Abs(y), math.Abs(z)
+	switch {
+	case ax >= ay && ax >= az:
+		face = 0
+		if x < 0 {
+			face = 3
+		}
+	case ay >= az:
+		face = 1
+		if y < 0 {
+			face = 4
+		}
+	default:
+		face = 2
+		if z < 0 {
+			face = 5
+		}
+	}
+	switch face {
+	case 0:
+		u, v = y/x, z/x
+	case 1:
+		u, v = -x/y, z/y
+	case 2:
+		u, v = -x/z, -y/z
+	case 3:
+		u, v = z/x, y/x
+	case 4:
+		u, v = z/y, -x/y
+	default:
+		u, v = -y/z, -x/z
+	}
+	return face, u, v
+}
@@ -0,0 +1,119 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package s2
+
+import (
+	"sort"
+
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/geojson/geometry"
+)
+
+// CellUnion is a sorted set of cells that do not overlap
+type CellUnion []CellID
+
+// Contains returns true when a cell is inside of one of the cells in the
+// union.
+func (cu CellUnion) Contains(id CellID) bool {
+	i := sort.Search(len(cu), func(i int) bool {
+		return cu[i].RangeMax() >= id
+	})
+	return i < len(cu) && cu[i].Contains(id)
+}
+
+// ContainsLatLng returns true when a point is inside of the union
+func (cu CellUnion) ContainsLatLng(lat, lon float64) bool {
+	return cu.Contains(CellIDFromLatLng(lat, lon))
+}
+
+// RegionCoverer makes coverings of objects
+type RegionCoverer struct {
+	// MinLevel is the level of the largest cells in a covering
+	MinLevel int
+	// MaxLevel is the level of the smallest cells in a covering. Zero means
+	// MaxLevel.
+	MaxLevel int
+	// MaxCells is the number of cells that a covering should try to stay
+	// under. A covering may have more cells when the object needs more cells
+	// at MinLevel. Zero means 8.
+	MaxCells int
+}
+
+// Covering returns a union of cells that covers an object. Large cells are
+// divided into smaller cells, first by level, until the cells that touch the
+// object are at MaxLevel or dividing them would go over MaxCells. Cells that
+// the object contains are not divided past MinLevel.
+//
+// Cells are tested with the object's Intersects and Contains, where a cell
+// that holds a pole or crosses the antimeridian is never contained.
+func (rc RegionCoverer) Covering(obj geojson.Object) CellUnion {
+	minLevel, maxLevel, maxCells := rc.MinLevel, rc.MaxLevel, rc.MaxCells
+	if maxLevel <= 0 || maxLevel > MaxLevel {
+		maxLevel = MaxLevel
+	}
+	if minLevel < 0 {
+		minLevel = 0
+	}
+	if minLevel > maxLevel {
+		minLevel = maxLevel
+	}
+	if maxCells <= 0 {
+		maxCells = 8
+	}
+	if obj.Empty() {
+		return nil
+	}
+	c := coverer{obj: obj, rect: obj.Rect()}
+	var queue []CellID
+	for face := 0; face < numFaces; face++ {
+		if id := CellIDFromFace(face); c.intersects(id) {
+			queue = append(queue, id)
+		}
+	}
+	// The queue is in level order, because children are always one level
+	// below the cell they came from.
+	var result CellUnion
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		level := id.Level()
+		if level >= maxLevel || (level >= minLevel && c.contains(id)) {
+			result = append(result, id)
+			continue
+		}
+		var children []CellID
+		for _, child := range id.Children() {
+			if c.intersects(child) {
+				children = append(children, child)
+			}
+		}
+		if level < minLevel || len(children) <= 1 ||
+			len(result)+len(queue)+len(children) <= maxCells {
+			queue = append(queue, children...)
+		} else {
+			result = append(result, id)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
+
+type coverer struct {
+	obj  geojson.Object
+	rect geometry.Rect
+}
+
+// intersects returns true when the object may intersect the cell. The test
+// is on a rectangle that holds the entire cell, so a covering never misses
+// any part of the object.
+func (c *coverer) intersects(id CellID) bool {
+	rect := id.Rect()
+	return c.rect.IntersectsRect(rect) && c.obj.Intersects(geojson.NewRect(rect))
+}
+
+// contains returns true when the object contains the cell
+func (c *coverer) contains(id CellID) bool {
+	return !id.crosses() && c.obj.Contains(id.Polygon())
+}
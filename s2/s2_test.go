@@ -0,0 +1,190 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package s2
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/geojson/geometry"
+)
+
+func init() {
+	seed := time.Now().UnixNano()
+	println(seed)
+	rand.Seed(seed)
+}
+
+func expect(t testing.TB, what bool) {
+	t.Helper()
+	if !what {
+		t.Fatal("expection failure")
+	}
+}
+
+func TestCellID(t *testing.T) {
+	tests := []struct {
+		id       CellID
+		lat, lon float64
+	}{
+		{0x47a1cbd595522b39, 49.703498679, 11.770681595},
+		{0x46525318b63be0f9, 55.685376759, 12.588490937},
+		{0x52b30b71698e729d, 45.486546517, -93.449700022},
+	}
+	for _, test := range tests {
+		expect(t, CellIDFromLatLng(test.lat, test.lon) == test.id)
+		lat, lon := test.id.LatLng()
+		expect(t, math.Abs(lat-test.lat) < 1e-6)
+		expect(t, math.Abs(lon-test.lon) < 1e-6)
+	}
+	for face := 0; face < numFaces; face++ {
+		id := CellIDFromFace(face)
+		expect(t, id.IsValid() && id.Face() == face && id.Level() == 0)
+		expect(t, id.Token() == string("13579b"[face]))
+	}
+	expect(t, !CellID(0).IsValid())
+	expect(t, CellID(0).Token() == "X")
+
+	id := CellIDFromLatLng(33.5, -112.1)
+	expect(t, id.IsLeaf() && id.Level() == MaxLevel)
+	expect(t, id.Parent(MaxLevel) == id)
+	for level := MaxLevel - 1; level >= 0; level-- {
+		parent := id.Parent(level)
+		expect(t, parent.Level() == level)
+		expect(t, parent.Contains(id) && !id.Contains(parent))
+		expect(t, parent.Intersects(id) && id.Intersects(parent))
+		expect(t, id.Parent(level+1).ImmediateParent() == parent)
+		var found int
+		for i, child := range parent.Children() {
+			expect(t, child.Level() == level+1)
+			expect(t, child.ImmediateParent() == parent)
+			expect(t, child.String() == parent.String()+string(byte('0'+i)))
+			if child.Contains(id) {
+				found++
+			}
+		}
+		expect(t, found == 1)
+		token, err := CellIDFromToken(parent.Token())
+		expect(t, err == nil && token == parent)
+	}
+	expect(t, id.Parent(0).RangeMin() == CellIDFromFace(id.Face()).RangeMin())
+	_, err := CellIDFromToken("zz")
+	expect(t, err == ErrInvalidToken)
+	_, err = CellIDFromToken("")
+	expect(t, err == ErrInvalidToken)
+	_, err = CellIDFromToken("8")
+	expect(t, err == ErrInvalidToken)
+	expect(t, CellIDFromFace(4).String() == "4/")
+}
+
+func TestCellGeometry(t *testing.T) {
+	for i := 0; i < 1000; i++ {
+		lat := rand.Float64()*180 - 90
+		lon := rand.Float64()*360 - 180
+		point := geometry.Point{X: lon, Y: lat}
+		leaf := CellIDFromLatLng(lat, lon)
+		id := leaf.Parent(rand.Intn(MaxLevel + 1))
+		expect(t, id.Rect().ContainsPoint(point))
+		clat, clon := id.LatLng()
+		expect(t, CellIDFromLatLng(clat, clon).Parent(id.Level()) == id)
+		if !id.crosses() {
+			poly := id.Polygon()
+			expect(t, poly.Valid())
+			if id.Level() > 2 {
+				expect(t, poly.Contains(geojson.NewSimplePoint(
+					geometry.Point{X: clon, Y: clat})))
+			}
+		}
+	}
+	// a face around the north pole
+	poly := CellIDFromFace(2).Polygon()
+	expect(t, poly.Contains(geojson.NewSimplePoint(geometry.Point{X: 0, Y: 89})))
+	expect(t, poly.Contains(geojson.NewSimplePoint(geometry.Point{X: 170, Y: 60})))
+	expect(t, !poly.Contains(geojson.NewSimplePoint(geometry.Point{X: 0, Y: 10})))
+	expect(t, CellIDFromFace(2).Rect().Max.Y == 90)
+	poly = CellIDFromFace(5).Polygon()
+	expect(t, poly.Contains(geojson.NewSimplePoint(geometry.Point{X: -179, Y: -89})))
+	expect(t, !poly.Contains(geojson.NewSimplePoint(geometry.Point{X: 0, Y: 89})))
+	// a face across the antimeridian
+	rect := CellIDFromFace(3).Rect()
+	expect(t, rect.Min.X == -180 && rect.Max.X == 180)
+	poly = CellIDFromFace(3).Polygon()
+	expect(t, poly.Contains(geojson.NewSimplePoint(geometry.Point{X: 180, Y: 0})))
+	for _, v := range CellIDFromFace(0).Vertices() {
+		expect(t, math.Abs(math.Abs(v.X)-45) < 1e-9)
+		expect(t, math.Abs(math.Abs(v.Y)-35.264389682754654) < 1e-9)
+	}
+}
+
+func TestCovering(t *testing.T) {
+	poly := geojson.NewPolygon(geometry.NewPoly([]geometry.Point{
+		{X: -112.3, Y: 33.2}, {X: -111.6, Y: 33.2}, {X: -111.6, Y: 33.8},
+		{X: -112.0, Y: 33.5}, {X: -112.3, Y: 33.8}, {X: -112.3, Y: 33.2},
+	}, nil, nil))
+	check := func(rc RegionCoverer, obj geojson.Object) CellUnion {
+		cells := rc.Covering(obj)
+		expect(t, len(cells) > 0)
+		for i, id := range cells {
+			expect(t, id.IsValid())
+			expect(t, id.Level() >= rc.MinLevel)
+			if rc.MaxLevel > 0 {
+				expect(t, id.Level() <= rc.MaxLevel)
+			}
+			if i > 0 {
+				expect(t, !cells[i-1].Intersects(id) && cells[i-1] < id)
+			}
+			expect(t, obj.Intersects(geojson.NewRect(id.Rect())))
+		}
+		// every point in the object is in the covering
+		rect := obj.Rect()
+		for i := 0; i < 1000; i++ {
+			point := geometry.Point{
+				X: rect.Min.X + rand.Float64()*(rect.Max.X-rect.Min.X),
+				Y: rect.Min.Y + rand.Float64()*(rect.Max.Y-rect.Min.Y),
+			}
+			if obj.Contains(geojson.NewSimplePoint(point)) {
+				expect(t, cells.ContainsLatLng(point.Y, point.X))
+			}
+		}
+		return cells
+	}
+	cells := check(RegionCoverer{MaxLevel: 20, MaxCells: 8}, poly)
+	expect(t, len(cells) <= 8)
+	many := check(RegionCoverer{MaxLevel: 12, MaxCells: 200}, poly)
+	expect(t, len(many) > len(cells) && len(many) <= 200)
+	var interior int
+	for _, id := range many {
+		if poly.Contains(id.Polygon()) {
+			interior++
+		}
+	}
+	expect(t, interior > 0)
+	// the minimum level is kept even past the max cells
+	fixed := check(RegionCoverer{MinLevel: 9, MaxLevel: 9, MaxCells: 1}, poly)
+	for _, id := range fixed {
+		expect(t, id.Level() == 9)
+	}
+	expect(t, len(fixed) > 1)
+
+	// points and objects across the antimeridian and around the poles
+	cells = check(RegionCoverer{MinLevel: 30}, geojson.NewPoint(
+		geometry.Point{X: 11.770681595, Y: 49.703498679}))
+	expect(t, len(cells) >= 1)
+	expect(t, cells.Contains(0x47a1cbd595522b39))
+	check(RegionCoverer{MaxLevel: 10, MaxCells: 20}, geojson.NewRect(
+		geometry.Rect{
+			Min: geometry.Point{X: 170, Y: -10},
+			Max: geometry.Point{X: 180, Y: 10},
+		}))
+	check(RegionCoverer{MaxLevel: 10, MaxCells: 20}, geojson.NewRect(
+		geometry.Rect{
+			Min: geometry.Point{X: -180, Y: 80},
+			Max: geometry.Point{X: 180, Y: 90},
+		}))
+	expect(t, len(RegionCoverer{}.Covering(geojson.NewMultiPoint(nil))) == 0)
+}
@@ -0,0 +1,386 @@
+package geojson
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/tidwall/geojson/geo"
+	"github.com/tidwall/geojson/geometry"
+	"github.com/tidwall/gjson"
+)
+
+// Sector is a wedge of a circle on the surface of the earth. It covers the
+// points within a radius from the center that are clockwise from the start
+// bearing to the end bearing, in degrees from north.
+//
+// Its GeoJSON representation is a special Feature syntax such as:
+//
+//	{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},
+//	 "properties":{"type":"Sector","radius":1000,"radius_units":"m",
+//	 "bearing_start":45,"bearing_end":135}}
+type Sector struct {
+	object Object
+	center geometry.Point
+	meters float64
+	radius float64  // radius in the original units
+	units  geo.Unit // units of the original radius
+	start  float64
+	end    float64
+	steps  int
+	id     gjson.Result // id of the Feature, for filters
+	props  *Properties  // properties of the Feature, for filters
+}
+
+// NewSector returns a sector object
+func NewSector(center geometry.Point, meters, bearingStart, bearingEnd float64,
+	steps int,
+) *Sector {
+	return NewSectorUnits(center, meters, geo.Meters, bearingStart, bearingEnd,
+		steps)
+}
+
+// NewSectorUnits returns a sector object with a radius in the provided
+// units. The units are kept when the sector is converted to JSON.
+func NewSectorUnits(center geometry.Point, radius float64, units geo.Unit,
+	bearingStart, bearingEnd float64, steps int,
+) *Sector {
+	if steps < 3 {
+		steps = 3
+	}
+	g := new(Sector)
+	g.center = center
+	g.radius = radius
+	g.units = units
+	g.meters = units.ToMeters(radius)
+	g.start = bearingStart
+	g.end = bearingEnd
+	g.steps = steps
+	g.object = g.makeObject()
+	return g
+}
+
+// AppendJSON ...
+func (g *Sector) AppendJSON(dst []byte) []byte {
+	radius, units := g.radius, g.units.String()
+	if units == "" {
+		// unknown units are written as meters
+		radius, units = g.meters, geo.Meters.String()
+	}
+	var props []byte
+	props = append(props, `"radius":`...)
+	props = strconv.AppendFloat(props, radius, 'f', -1, 64)
+	props = append(props, `,"radius_units":"`...)
+	props = append(props, units...)
+	props = append(props, `","bearing_start":`...)
+	props = strconv.AppendFloat(props, g.start, 'f', -1, 64)
+	props = append(props, `,"bearing_end":`...)
+	props = strconv.AppendFloat(props, g.end, 'f', -1, 64)
+	return appendJSONShape(dst, g.center, "Sector", props)
+}
+
+// JSON ...
+func (g *Sector) JSON() string {
+	return string(g.AppendJSON(nil))
+}
+
+// MarshalJSON ...
+func (g *Sector) MarshalJSON() ([]byte, error) {
+	return g.AppendJSON(nil), nil
+}
+
+// String ...
+func (g *Sector) String() string {
+	return string(g.AppendJSON(nil))
+}
+
+// Center returns the sector's center point
+func (g *Sector) Center() geometry.Point {
+	return g.center
+}
+
+// Meters returns the sector's radius
+func (g *Sector) Meters() float64 {
+	return g.meters
+}
+
+// Radius returns the sector's radius in its original units
+func (g *Sector) Radius() float64 {
+	return g.radius
+}
+
+// Units returns the units of the sector's original radius
+func (g *Sector) Units() geo.Unit {
+	return g.units
+}
+
+// BearingStart returns the bearing where the sector starts
+func (g *Sector) BearingStart() float64 {
+	return g.start
+}
+
+// BearingEnd returns the bearing where the sector ends
+func (g *Sector) BearingEnd() float64 {
+	return g.end
+}
+
+// Within returns true if sector is contained inside object
+func (g *Sector) Within(obj Object) bool {
+	return obj.Contains(g)
+}
+
+// Contains returns true if the sector contains other object
+func (g *Sector) Contains(obj Object) bool {
+	return shapeContains(g, obj, g.object)
+}
+
+// Intersects returns true the sector intersects other object
+func (g *Sector) Intersects(obj Object) bool {
+	return shapeIntersects(g, obj, g.object)
+}
+
+// Empty ...
+func (g *Sector) Empty() bool {
+	return false
+}
+
+// Valid ...
+func (g *Sector) Valid() bool {
+	return g.object.Valid()
+}
+
+// ForEach ...
+func (g *Sector) ForEach(iter func(geom Object) bool) bool {
+	return iter(g)
+}
+
+// NumPoints ...
+func (g *Sector) NumPoints() int {
+	return 1
+}
+
+// Distance ...
+func (g *Sector) Distance(other Object) float64 {
+	return g.object.Distance(other)
+}
+
+// Rect ...
+func (g *Sector) Rect() geometry.Rect {
+	return g.object.Rect()
+}
+
+// Spatial ...
+func (g *Sector) Spatial() Spatial {
+	return g
+}
+
+// Primative returns a primative GeoJSON object. Either a Polygon or Point.
+func (g *Sector) Primative() Object {
+	return g.object
+}
+
+// WithinRect ...
+func (g *Sector) WithinRect(rect geometry.Rect) bool {
+	return g.object.Spatial().WithinRect(rect)
+}
+
+// WithinPoint ...
+func (g *Sector) WithinPoint(point geometry.Point) bool {
+	return g.object.Spatial().WithinPoint(point)
+}
+
+// WithinLine ...
+func (g *Sector) WithinLine(line *geometry.Line) bool {
+	return g.object.Spatial().WithinLine(line)
+}
+
+// WithinPoly ...
+func (g *Sector) WithinPoly(poly *geometry.Poly) bool {
+	return g.object.Spatial().WithinPoly(poly)
+}
+
+// IntersectsPoint ...
+func (g *Sector) IntersectsPoint(point geometry.Point) bool {
+	return g.containsPoint(point)
+}
+
+// IntersectsRect ...
+func (g *Sector) IntersectsRect(rect geometry.Rect) bool {
+	return shapeIntersectsPoly(g, &geometry.Poly{Exterior: rect})
+}
+
+// IntersectsLine ...
+func (g *Sector) IntersectsLine(line *geometry.Line) bool {
+	return shapeIntersectsSeries(g, line)
+}
+
+// IntersectsPoly ...
+func (g *Sector) IntersectsPoly(poly *geometry.Poly) bool {
+	return shapeIntersectsPoly(g, poly)
+}
+
+// DistancePoint ...
+func (g *Sector) DistancePoint(point geometry.Point) float64 {
+	return g.object.Spatial().DistancePoint(point)
+}
+
+// DistanceRect ...
+func (g *Sector) DistanceRect(rect geometry.Rect) float64 {
+	return g.object.Spatial().DistanceRect(rect)
+}
+
+// DistanceLine ...
+func (g *Sector) DistanceLine(line *geometry.Line) float64 {
+	return g.object.Spatial().DistanceLine(line)
+}
+
+// DistancePoly ...
+func (g *Sector) DistancePoly(poly *geometry.Poly) float64 {
+	return g.object.Spatial().DistancePoly(poly)
+}
+
+// full returns true when the sector sweeps the entire circle
+func (g *Sector) full() bool {
+	return bearingSweep(g.start, g.end) == 360
+}
+
+func (g *Sector) distanceTo(point geometry.Point) float64 {
+	return geo.DistanceTo(g.center.Y, g.center.X, point.Y, point.X)
+}
+
+func (g *Sector) bearingTo(point geometry.Point) float64 {
+	return geo.BearingTo(g.center.Y, g.center.X, point.Y, point.X)
+}
+
+// crossesRadial returns true if the segment crosses the sector's radial edge
+// at the provided bearing. Seen from the center, the bearing of a point that
+// moves along a segment only turns one way, sweeping less than 180 degrees.
+func (g *Sector) crossesRadial(seg geometry.Segment, bearing float64) bool {
+	if g.distanceTo(seg.A) == 0 || g.distanceTo(seg.B) == 0 {
+		// segment is on a radial line
+		return false
+	}
+	bearingA := g.bearingTo(seg.A)
+	delta := bearingDelta(bearingA, g.bearingTo(seg.B))
+	offset := bearingDelta(bearingA, bearing)
+	if offset == 0 || delta == 0 || (offset > 0) != (delta > 0) ||
+		math.Abs(offset) >= math.Abs(delta) {
+		return false
+	}
+	// bisect the segment to find where it crosses the bearing
+	pointAt := func(t float64) geometry.Point {
+		return geometry.Point{
+			X: seg.A.X + (seg.B.X-seg.A.X)*t,
+			Y: seg.A.Y + (seg.B.Y-seg.A.Y)*t,
+		}
+	}
+	lo, hi := 0.0, 1.0
+	for i := 0; i < 64; i++ {
+		mid := (lo + hi) / 2
+		if math.Abs(bearingDelta(bearingA, g.bearingTo(pointAt(mid)))) <
+			math.Abs(offset) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return g.distanceTo(pointAt((lo+hi)/2)) <= g.meters
+}
+
+func (g *Sector) bounds() geometry.Rect {
+	minLat, minLon, maxLat, maxLon :=
+		geo.RectFromCenter(g.center.Y, g.center.X, g.meters)
+	return geometry.Rect{
+		Min: geometry.Point{X: minLon, Y: minLat},
+		Max: geometry.Point{X: maxLon, Y: maxLat},
+	}
+}
+
+func (g *Sector) interiorPoint() geometry.Point {
+	bearing := g.start + bearingSweep(g.start, g.end)/2
+	return destinationPoint(g.center, g.meters/2, bearing)
+}
+
+func (g *Sector) containsPoint(point geometry.Point) bool {
+	meters := g.distanceTo(point)
+	if meters == 0 {
+		return true
+	}
+	if meters > g.meters {
+		return false
+	}
+	return bearingInRange(g.bearingTo(point), g.start, g.end)
+}
+
+func (g *Sector) containsSegment(seg geometry.Segment) bool {
+	if !g.containsPoint(seg.A) || !g.containsPoint(seg.B) {
+		return false
+	}
+	if g.full() {
+		return true
+	}
+	// both ends are inside, so the segment can only leave the sector by
+	// crossing one of the radial edges.
+	return !g.crossesRadial(seg, g.start) && !g.crossesRadial(seg, g.end)
+}
+
+func (g *Sector) intersectsSegment(seg geometry.Segment) bool {
+	if g.containsPoint(seg.A) || g.containsPoint(seg.B) {
+		return true
+	}
+	closest, meters := segmentMin(seg, g.distanceTo)
+	if meters > g.meters {
+		// the segment does not enter the circle
+		return false
+	}
+	if meters < 1e-6 || g.containsPoint(closest) {
+		// passes through the center, or the closest point is inside
+		return true
+	}
+	if g.full() {
+		return false
+	}
+	return g.crossesRadial(seg, g.start) || g.crossesRadial(seg, g.end)
+}
+
+func (g *Sector) makeObject() Object {
+	if g.meters <= 0 {
+		return NewPoint(g.center)
+	}
+	radius := func(float64) float64 { return g.meters }
+	var points []geometry.Point
+	sweep := bearingSweep(g.start, g.end)
+	if sweep == 360 {
+		points = shapeRing(g.center, g.steps, g.start, sweep, radius)
+		points[len(points)-1] = points[0]
+	} else {
+		steps := int(math.Ceil(float64(g.steps) * sweep / 360))
+		if steps < 1 {
+			steps = 1
+		}
+		points = append(points, g.center)
+		points = append(points,
+			shapeRing(g.center, steps, g.start, sweep, radius)...)
+		points = append(points, g.center)
+	}
+	return NewPolygon(
+		geometry.NewPoly(points, nil, &geometry.IndexOptions{
+			Kind: geometry.None,
+		}),
+	)
+}
+
+// parseJSONSector returns the Sector of a Feature, or nil when the radius is
+// missing or not positive, which leaves it a plain Feature.
+func parseJSONSector(center geometry.Point, members string) (Object, error) {
+	radius, ok := parseShapeSize(members, "radius")
+	if !ok || !(radius > 0) {
+		return nil, nil
+	}
+	units, ok := parseRadiusUnits(members)
+	if !ok {
+		return nil, errRadiusUnitsInvalid
+	}
+	start := gjson.Get(members, "properties.bearing_start").Float()
+	end := gjson.Get(members, "properties.bearing_end").Float()
+	return NewSectorUnits(center, radius, units, start, end, 64), nil
+}
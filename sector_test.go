@@ -0,0 +1,112 @@
+package geojson
+
+import (
+	"testing"
+
+	"github.com/tidwall/geojson/geo"
+	"github.com/tidwall/geojson/geometry"
+)
+
+func TestSectorNew(t *testing.T) {
+	data := `{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},"properties":{"type":"Sector","radius":1,"radius_units":"km","bearing_start":45,"bearing_end":135}}`
+	g := expectJSON(t, data, data)
+	expect(t, g.(*Sector).Units() == geo.Kilometers)
+	expect(t, g.(*Sector).Radius() == 1)
+	expect(t, g.(*Sector).Meters() == 1000)
+	g, err := Parse(`{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},"properties":{"type":"Sector","radius":1000}}`,
+		&ParseOptions{DisableSectorType: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := g.(*Feature); !ok {
+		t.Fatalf("expected Feature, got %T", g)
+	}
+	// invalid sizes are plain Features
+	for _, json := range []string{
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},"properties":{"type":"Sector","radius":0}}`,
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},"properties":{"type":"Sector","radius":-1000}}`,
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},"properties":{"type":"Sector","bearing_start":45,"bearing_end":135}}`,
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},"properties":{"type":"Sector","radius":"1000"}}`,
+	} {
+		g, err := Parse(json, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := g.(*Feature); !ok {
+			t.Fatalf("expected Feature, got %T", g)
+		}
+	}
+	sector := NewSector(P(-112, 33), 1000, 350, 10, 64)
+	expect(t, sector.Meters() == 1000)
+	expect(t, sector.BearingStart() == 350)
+	expect(t, sector.BearingEnd() == 10)
+	expectJSON(t, sector.JSON(), `{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},"properties":{"type":"Sector","radius":1000,"radius_units":"m","bearing_start":350,"bearing_end":10}}`)
+	// an invalid unit has no meters, which are written instead
+	sector = NewSectorUnits(P(-112, 33), 1, geo.Unit(100), 350, 10, 64)
+	expectJSON(t, sector.JSON(), `{"type":"Feature","geometry":{"type":"Point","coordinates":[-112,33]},"properties":{"type":"Sector","radius":0,"radius_units":"m","bearing_start":350,"bearing_end":10}}`)
+}
+
+func TestSectorPredicates(t *testing.T) {
+	center := P(-122.4412, 37.7335)
+	// a quarter wedge facing north-east
+	g := NewSector(center, 1000, 0, 90, 64)
+	p := func(meters, bearing float64) *Point {
+		pt := destinationPoint(center, meters, bearing)
+		return PO(pt.X, pt.Y)
+	}
+	expect(t, g.Contains(PO(center.X, center.Y)))
+	expect(t, g.Contains(p(990, 45)))
+	expect(t, g.Contains(p(990, 1)))
+	expect(t, g.Contains(p(990, 89)))
+	expect(t, !g.Contains(p(1010, 45)))
+	expect(t, !g.Contains(p(500, 91)))
+	expect(t, !g.Contains(p(500, 359)))
+	expect(t, !g.Intersects(p(500, 180)))
+
+	// a line that crosses the radial edge at bearing 0, with both ends
+	// outside of the sector.
+	line := LO([]geometry.Point{p(500, 315).Base(), p(500, 30).Base()})
+	expect(t, g.Intersects(line))
+	expect(t, !g.Contains(line))
+	// a line that passes through the center
+	line = LO([]geometry.Point{p(500, 180).Base(), center, p(500, 270).Base()})
+	expect(t, g.Intersects(line))
+	// a line that passes behind the center
+	line = LO([]geometry.Point{p(500, 160).Base(), p(500, 290).Base()})
+	expect(t, !g.Intersects(line))
+	// a line that grazes the arc
+	line = LO([]geometry.Point{p(1050, 20).Base(), p(1050, 70).Base()})
+	expect(t, g.Intersects(line))
+	line = LO([]geometry.Point{p(1500, 20).Base(), p(1500, 70).Base()})
+	expect(t, !g.Intersects(line))
+	// lines inside of the sector
+	line = LO([]geometry.Point{p(900, 10).Base(), p(900, 80).Base()})
+	expect(t, g.Contains(line))
+	expect(t, g.Intersects(line))
+
+	// a reflex sector that is missing its north-east quarter
+	g = NewSector(center, 1000, 90, 0, 64)
+	expect(t, !g.Contains(p(500, 45)))
+	expect(t, g.Contains(p(500, 180)))
+	line = LO([]geometry.Point{p(500, 100).Base(), p(500, 260).Base()})
+	expect(t, g.Contains(line))
+	// the chord between these ends cuts through the missing quarter
+	line = LO([]geometry.Point{p(500, 100).Base(), p(500, 350).Base()})
+	expect(t, !g.Contains(line))
+	expect(t, g.Intersects(line))
+	line = LO([]geometry.Point{p(500, 80).Base(), p(500, 350).Base()})
+	expect(t, !g.Contains(line))
+	line = LO([]geometry.Point{p(500, 95).Base(), p(500, 355).Base()})
+	expect(t, !g.Contains(line))
+
+	// polygons
+	rect := RO(center.X-0.1, center.Y-0.1, center.X+0.1, center.Y+0.1)
+	expect(t, g.Within(rect))
+	expect(t, rect.Intersects(g))
+	expect(t, g.Intersects(rect))
+	ne := p(500, 45).Base()
+	rect = RO(ne.X, ne.Y, ne.X+0.001, ne.Y+0.001)
+	expect(t, !g.Intersects(rect))
+	expect(t, !rect.Intersects(g))
+	expect(t, NewSector(center, 1000, 0, 90, 64).Intersects(rect))
+}
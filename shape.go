@@ -0,0 +1,247 @@
+package geojson
+
+import (
+	"math"
+
+	"github.com/tidwall/geojson/geo"
+	"github.com/tidwall/geojson/geometry"
+	"github.com/tidwall/gjson"
+)
+
+// shape is a search area that is defined by distances and bearings from a
+// center point, such as an Ellipse or a Sector.
+type shape interface {
+	// bounds returns a rectangle that contains the entire shape
+	bounds() geometry.Rect
+	// interiorPoint returns any point that is inside of the shape
+	interiorPoint() geometry.Point
+	containsPoint(point geometry.Point) bool
+	containsSegment(seg geometry.Segment) bool
+	intersectsSegment(seg geometry.Segment) bool
+}
+
+// shapeContainsSeries returns true if the shape contains every segment of the
+// series.
+func shapeContainsSeries(s shape, series geometry.Series) bool {
+	n := series.NumPoints()
+	if n == 0 {
+		return false
+	}
+	if n == 1 {
+		return s.containsPoint(series.PointAt(0))
+	}
+	nsegs := series.NumSegments()
+	for i := 0; i < nsegs; i++ {
+		if !s.containsSegment(series.SegmentAt(i)) {
+			return false
+		}
+	}
+	return true
+}
+
+// shapeIntersectsSeries returns true if any point or segment of the series
+// is inside of the shape.
+func shapeIntersectsSeries(s shape, series geometry.Series) bool {
+	n := series.NumPoints()
+	if n == 0 {
+		return false
+	}
+	if s.containsPoint(series.PointAt(0)) {
+		return true
+	}
+	var intersects bool
+	series.Search(s.bounds(), func(seg geometry.Segment, _ int) bool {
+		if s.intersectsSegment(seg) {
+			intersects = true
+			return false
+		}
+		return true
+	})
+	return intersects
+}
+
+// shapeIntersectsPoly returns true if the shape intersects the polygon.
+// When none of the polygon's rings pass through the shape, then the shape is
+// either entirely inside or entirely outside of the polygon.
+func shapeIntersectsPoly(s shape, poly *geometry.Poly) bool {
+	if poly.Empty() {
+		return false
+	}
+	if shapeIntersectsSeries(s, poly.Exterior) {
+		return true
+	}
+	for _, hole := range poly.Holes {
+		if shapeIntersectsSeries(s, hole) {
+			return true
+		}
+	}
+	return poly.ContainsPoint(s.interiorPoint())
+}
+
+// shapeContains returns true if the shape contains the other object.
+func shapeContains(s shape, obj Object, approx Object) bool {
+	switch other := obj.(type) {
+	case *Point:
+		return s.containsPoint(other.Center())
+	case *SimplePoint:
+		return s.containsPoint(other.Center())
+	case *LineString:
+		return shapeContainsSeries(s, &other.base)
+	case *Polygon:
+		if other.base.Empty() {
+			return false
+		}
+		return shapeContainsSeries(s, other.base.Exterior)
+	case *Rect:
+		return shapeContainsSeries(s, other.base)
+	case *Feature:
+		return shapeContains(s, other.base, approx)
+	case Collection:
+		for _, p := range other.Children() {
+			if !shapeContains(s, p, approx) {
+				return false
+			}
+		}
+		return true
+	default:
+		// No simple cases, so using polygon approximation.
+		return approx.Contains(other)
+	}
+}
+
+// shapeIntersects returns true if the shape intersects the other object.
+func shapeIntersects(s shape, obj Object, approx Object) bool {
+	switch other := obj.(type) {
+	case *Point:
+		return s.containsPoint(other.Center())
+	case *SimplePoint:
+		return s.containsPoint(other.Center())
+	case *LineString:
+		return shapeIntersectsSeries(s, &other.base)
+	case *Polygon:
+		return shapeIntersectsPoly(s, &other.base)
+	case *Rect:
+		return shapeIntersectsPoly(s, &geometry.Poly{Exterior: other.base})
+	case *Feature:
+		return shapeIntersects(s, other.base, approx)
+	case Collection:
+		for _, p := range other.Children() {
+			if shapeIntersects(s, p, approx) {
+				return true
+			}
+		}
+		return false
+	default:
+		// No simple cases, so using polygon approximation.
+		return approx.Intersects(obj)
+	}
+}
+
+// segmentMin returns the point along the segment where f is the smallest,
+// and its value. The f function must have a single minimum along the segment.
+func segmentMin(seg geometry.Segment, f func(point geometry.Point) float64) (
+	point geometry.Point, value float64,
+) {
+	t, value := geo.SegmentMin(func(t float64) float64 {
+		return f(segmentPointAt(seg, t))
+	})
+	return segmentPointAt(seg, t), value
+}
+
+// segmentPointAt returns the point at t, from 0 to 1, along a segment
+func segmentPointAt(seg geometry.Segment, t float64) geometry.Point {
+	if t == 1 {
+		return seg.B
+	}
+	return geometry.Point{
+		X: seg.A.X + (seg.B.X-seg.A.X)*t,
+		Y: seg.A.Y + (seg.B.Y-seg.A.Y)*t,
+	}
+}
+
+// destinationPoint returns the point at a distance and bearing from center.
+func destinationPoint(center geometry.Point, meters, bearing float64,
+) geometry.Point {
+	lat, lon := geo.DestinationPoint(center.Y, center.X, meters, bearing)
+	return geometry.Point{X: lon, Y: lat}
+}
+
+// bearingDelta returns the signed difference from bearing a to bearing b, in
+// the range (-180, 180].
+func bearingDelta(a, b float64) float64 {
+	d := math.Mod(b-a, 360)
+	if d <= -180 {
+		d += 360
+	} else if d > 180 {
+		d -= 360
+	}
+	return d
+}
+
+// bearingSweep returns the clockwise sweep from bearing start to bearing end,
+// in the range (0, 360]. Matching bearings sweep the full circle.
+func bearingSweep(start, end float64) float64 {
+	sweep := math.Mod(end-start, 360)
+	if sweep <= 0 {
+		sweep += 360
+	}
+	return sweep
+}
+
+// bearingInRange returns true if the bearing is within the clockwise sweep
+// from start to end.
+func bearingInRange(bearing, start, end float64) bool {
+	offset := math.Mod(bearing-start, 360)
+	if offset < 0 {
+		offset += 360
+	}
+	return offset <= bearingSweep(start, end)
+}
+
+// shapeRing returns a closed ring of points around center where the distance
+// for each bearing is provided by the meters function.
+func shapeRing(center geometry.Point, steps int, start, sweep float64,
+	meters func(bearing float64) float64,
+) []geometry.Point {
+	points := make([]geometry.Point, 0, steps+1)
+	for i := 0; i <= steps; i++ {
+		bearing := start + sweep*float64(i)/float64(steps)
+		points = append(points,
+			destinationPoint(center, meters(bearing), bearing))
+	}
+	return points
+}
+
+// appendJSONShape appends the special Feature syntax of a shape that is
+// defined from a center point. The props must be a list of comma separated
+// members that are added to the properties.
+func appendJSONShape(dst []byte, center geometry.Point, kind string,
+	props []byte,
+) []byte {
+	dst = append(dst, `{"type":"Feature","geometry":`...)
+	dst = append(dst, `{"type":"Point","coordinates":`...)
+	dst = appendJSONPoint(dst, center, nil, 0)
+	dst = append(dst, `},"properties":{"type":"`...)
+	dst = append(dst, kind...)
+	dst = append(dst, `",`...)
+	dst = append(dst, props...)
+	dst = append(dst, `}}`...)
+	return dst
+}
+
+// parseShapeSize returns a size in the properties of a shape, and false
+// when it's missing or is not a number.
+func parseShapeSize(members, name string) (float64, bool) {
+	value := gjson.Get(members, "properties."+name)
+	return value.Num, value.Type == gjson.Number
+}
+
+// parseRadiusUnits returns the units of the "radius_units" property of a
+// special shape. Meters are used when there are no units.
+func parseRadiusUnits(members string) (geo.Unit, bool) {
+	abbr := gjson.Get(members, "properties.radius_units").String()
+	if abbr == "" {
+		return geo.Meters, true
+	}
+	return geo.ParseUnit(abbr)
+}
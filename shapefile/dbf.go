@@ -0,0 +1,150 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package shapefile
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// dbfReader reads the records of a .dbf file in order
+type dbfReader struct {
+	r      *bufio.Reader
+	fields []Field
+	count  int
+	record []byte
+}
+
+func newDBFReader(r *bufio.Reader) (*dbfReader, error) {
+	var h [32]byte
+	if err := readFull(r, h[:], ErrInvalidDBF); err != nil {
+		return nil, err
+	}
+	d := &dbfReader{
+		r:      r,
+		count:  int(binary.LittleEndian.Uint32(h[4:])),
+		record: make([]byte, binary.LittleEndian.Uint16(h[10:])),
+	}
+	size := int(binary.LittleEndian.Uint16(h[8:]))
+	// field descriptors end with a carriage return
+	pos := len(h)
+	recordSize := 1
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			return nil, shortErr(err, ErrInvalidDBF)
+		}
+		pos++
+		if c == '\r' {
+			break
+		}
+		var fd [32]byte
+		fd[0] = c
+		if err := readFull(r, fd[1:], ErrInvalidDBF); err != nil {
+			return nil, err
+		}
+		pos += len(fd) - 1
+		f := Field{
+			Name:     strings.TrimRight(string(fd[:11]), "\x00 "),
+			Type:     fd[11],
+			Length:   int(fd[16]),
+			Decimals: int(fd[17]),
+		}
+		d.fields = append(d.fields, f)
+		recordSize += f.Length
+	}
+	if recordSize > len(d.record) {
+		return nil, ErrInvalidDBF
+	}
+	if size > pos {
+		if _, err := r.Discard(size - pos); err != nil {
+			return nil, shortErr(err, ErrInvalidDBF)
+		}
+	}
+	return d, nil
+}
+
+// appendRecord appends the properties of the next record, and returns
+// whether the record is deleted.
+func (d *dbfReader) appendRecord(dst []byte) ([]byte, bool, error) {
+	if d.count == 0 {
+		return nil, false, ErrInvalidDBF
+	}
+	if err := readFull(d.r, d.record, ErrInvalidDBF); err != nil {
+		return nil, false, err
+	}
+	d.count--
+	pos := 1
+	for i, f := range d.fields {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = appendString(dst, f.Name)
+		dst = append(dst, ':')
+		dst = appendValue(dst, f, d.record[pos:pos+f.Length])
+		pos += f.Length
+	}
+	return dst, d.record[0] == '*', nil
+}
+
+func appendString(dst []byte, s string) []byte {
+	b, _ := json.Marshal(s)
+	return append(dst, b...)
+}
+
+// decodeText returns text as UTF-8, where text that is not UTF-8 is
+// Latin-1.
+func decodeText(b []byte) string {
+	if utf8.Valid(b) {
+		return string(b)
+	}
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+// appendValue appends the JSON of a field value. Numbers, logicals, and
+// dates that are blank are null.
+func appendValue(dst []byte, f Field, raw []byte) []byte {
+	s := strings.Trim(string(raw), "\x00 ")
+	switch f.Type {
+	case 'N', 'F':
+		if f.Decimals == 0 {
+			if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return strconv.AppendInt(dst, n, 10)
+			}
+		}
+		x, err := strconv.ParseFloat(s, 64)
+		if err != nil || math.IsNaN(x) || math.IsInf(x, 0) {
+			return append(dst, "null"...)
+		}
+		return strconv.AppendFloat(dst, x, 'f', -1, 64)
+	case 'L':
+		switch s {
+		case "T", "t", "Y", "y":
+			return append(dst, "true"...)
+		case "F", "f", "N", "n":
+			return append(dst, "false"...)
+		}
+		return append(dst, "null"...)
+	case 'D':
+		if s == "" {
+			return append(dst, "null"...)
+		}
+		if _, err := strconv.ParseUint(s, 10, 64); err == nil && len(s) == 8 {
+			s = s[:4] + "-" + s[4:6] + "-" + s[6:]
+		}
+		return appendString(dst, s)
+	}
+	return appendString(dst, decodeText([]byte(strings.TrimRight(
+		string(raw), "\x00 "))))
+}
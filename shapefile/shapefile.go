@@ -0,0 +1,165 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+// Package shapefile reads ESRI Shapefiles as GeoJSON Features.
+package shapefile
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/geojson/geometry"
+)
+
+var (
+	// ErrInvalidFile is returned when a .shp or .shx file is not valid
+	ErrInvalidFile = errors.New("invalid shapefile")
+	// ErrInvalidDBF is returned when a .dbf file is not valid, or when it
+	// does not have a record for every shape.
+	ErrInvalidDBF = errors.New("invalid dbf file")
+	// ErrUnsupportedShape is returned for MultiPatch shapes
+	ErrUnsupportedShape = errors.New("unsupported shape type")
+)
+
+// ShapeType is the type of the shapes in a shapefile
+type ShapeType int
+
+// Shape types
+const (
+	Null        ShapeType = 0
+	Point       ShapeType = 1
+	PolyLine    ShapeType = 3
+	Polygon     ShapeType = 5
+	MultiPoint  ShapeType = 8
+	PointZ      ShapeType = 11
+	PolyLineZ   ShapeType = 13
+	PolygonZ    ShapeType = 15
+	MultiPointZ ShapeType = 18
+	PointM      ShapeType = 21
+	PolyLineM   ShapeType = 23
+	PolygonM    ShapeType = 25
+	MultiPointM ShapeType = 28
+	MultiPatch  ShapeType = 31
+)
+
+// Files are the parts of a shapefile, where only the .shp file is required.
+// The .shx file is used for the offsets of the records.
+type Files struct {
+	SHP io.Reader
+	SHX io.Reader
+	DBF io.Reader
+	PRJ io.Reader
+}
+
+// Field is an attribute of the .dbf file
+type Field struct {
+	Name     string
+	Type     byte
+	Length   int
+	Decimals int
+}
+
+// Shapefile is the content of a shapefile
+type Shapefile struct {
+	// Type is the shape type in the .shp header
+	Type ShapeType
+	// Bounds is the bounding box in the .shp header
+	Bounds geometry.Rect
+	// Fields are the attributes of the features
+	Fields []Field
+	// Projection is the WKT of the .prj file
+	Projection string
+	// Features has a Feature for every shape that is not null or deleted
+	Features *geojson.FeatureCollection
+}
+
+// Read returns the features of a shapefile. Shapes become Point, MultiPoint,
+// LineString, MultiLineString, Polygon, and MultiPolygon geometries, where
+// the Z values of Z shapes are the third value of positions and M values are
+// left out. The rings of polygons become exteriors or holes by the number of
+// rings that contain them. The attributes of the .dbf file become the
+// properties.
+func Read(files Files, opts *geojson.ParseOptions) (*Shapefile, error) {
+	shp := bufio.NewReader(files.SHP)
+	header, err := readHeader(shp)
+	if err != nil {
+		return nil, err
+	}
+	sf := &Shapefile{Type: header.shapeType, Bounds: header.bounds}
+	var offsets []int64
+	if files.SHX != nil {
+		if offsets, err = readIndex(bufio.NewReader(files.SHX)); err != nil {
+			return nil, err
+		}
+	}
+	var dbf *dbfReader
+	if files.DBF != nil {
+		if dbf, err = newDBFReader(bufio.NewReader(files.DBF)); err != nil {
+			return nil, err
+		}
+		sf.Fields = dbf.fields
+	}
+	if files.PRJ != nil {
+		prj, err := ioutil.ReadAll(files.PRJ)
+		if err != nil {
+			return nil, err
+		}
+		sf.Projection = strings.TrimSpace(string(prj))
+	}
+
+	rd := &recordReader{r: shp, pos: headerSize, end: header.length}
+	dst := []byte(`{"type":"FeatureCollection","features":[`)
+	var count int
+	for i := 0; ; i++ {
+		if offsets != nil {
+			if i == len(offsets) {
+				break
+			}
+			if err := rd.seek(offsets[i]); err != nil {
+				return nil, err
+			}
+		}
+		content, err := rd.next()
+		if err == io.EOF && offsets == nil {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		geom, err := appendShape(nil, content)
+		if err != nil {
+			return nil, err
+		}
+		var props []byte
+		deleted := false
+		if dbf != nil {
+			if props, deleted, err = dbf.appendRecord(nil); err != nil {
+				return nil, err
+			}
+		}
+		if geom == nil || deleted {
+			continue
+		}
+		if count > 0 {
+			dst = append(dst, ',')
+		}
+		count++
+		dst = append(dst, `{"type":"Feature","geometry":`...)
+		dst = append(dst, geom...)
+		dst = append(dst, `,"properties":{`...)
+		dst = append(dst, props...)
+		dst = append(dst, "}}"...)
+	}
+	dst = append(dst, "]}"...)
+	obj, err := geojson.Parse(string(dst), geojson.WithoutShapeTypes(opts))
+	if err != nil {
+		return nil, err
+	}
+	sf.Features = obj.(*geojson.FeatureCollection)
+	return sf, nil
+}
@@ -0,0 +1,339 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package shapefile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/geojson/geometry"
+)
+
+func expect(t testing.TB, what bool) {
+	t.Helper()
+	if !what {
+		t.Fatal("expection failure")
+	}
+}
+
+func P(x, y float64) geometry.Point {
+	return geometry.Point{X: x, Y: y}
+}
+
+func square(x, y, size float64) []geometry.Point {
+	return []geometry.Point{
+		P(x, y), P(x, y+size), P(x+size, y+size), P(x+size, y), P(x, y),
+	}
+}
+
+func putFloats(dst []byte, vals ...float64) []byte {
+	for _, v := range vals {
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], math.Float64bits(v))
+		dst = append(dst, b[:]...)
+	}
+	return dst
+}
+
+func putInts(dst []byte, vals ...int) []byte {
+	for _, v := range vals {
+		var b [4]byte
+		binary.LittleEndian.PutUint32(b[:], uint32(v))
+		dst = append(dst, b[:]...)
+	}
+	return dst
+}
+
+// pointRecord returns the content of a Point, PointZ, or PointM record
+func pointRecord(typ ShapeType, vals ...float64) []byte {
+	return putFloats(putInts(nil, int(typ)), vals...)
+}
+
+// partsRecord returns the content of a PolyLine or Polygon record, with
+// z and m values of the parts when they are not nil.
+func partsRecord(typ ShapeType, parts [][]geometry.Point, z, m []float64,
+) []byte {
+	content := putInts(nil, int(typ))
+	content = putFloats(content, 0, 0, 0, 0)
+	var n int
+	for _, part := range parts {
+		n += len(part)
+	}
+	content = putInts(content, len(parts), n)
+	n = 0
+	for _, part := range parts {
+		content = putInts(content, n)
+		n += len(part)
+	}
+	for _, part := range parts {
+		for _, p := range part {
+			content = putFloats(content, p.X, p.Y)
+		}
+	}
+	for _, vals := range [][]float64{z, m} {
+		if vals != nil {
+			content = putFloats(content, 0, 0)
+			content = putFloats(content, vals...)
+		}
+	}
+	return content
+}
+
+// multiPointRecord returns the content of a MultiPoint record
+func multiPointRecord(typ ShapeType, points []geometry.Point, m []float64,
+) []byte {
+	content := putInts(nil, int(typ))
+	content = putFloats(content, 0, 0, 0, 0)
+	content = putInts(content, len(points))
+	for _, p := range points {
+		content = putFloats(content, p.X, p.Y)
+	}
+	if m != nil {
+		content = putFloats(content, 0, 0)
+		content = putFloats(content, m...)
+	}
+	return content
+}
+
+func fileHeader(typ ShapeType, length int) []byte {
+	h := make([]byte, headerSize)
+	binary.BigEndian.PutUint32(h[0:], 9994)
+	binary.BigEndian.PutUint32(h[24:], uint32(length/2))
+	binary.LittleEndian.PutUint32(h[28:], 1000)
+	binary.LittleEndian.PutUint32(h[32:], uint32(typ))
+	copy(h[36:], putFloats(nil, -10, -20, 30, 40))
+	return h
+}
+
+// buildSHP returns .shp and .shx files of record contents, with gap bytes
+// between the records.
+func buildSHP(typ ShapeType, records [][]byte, gap int) (shp, shx []byte) {
+	var body, index []byte
+	for i, content := range records {
+		var b [8]byte
+		binary.BigEndian.PutUint32(b[:], uint32((headerSize+len(body))/2))
+		binary.BigEndian.PutUint32(b[4:], uint32(len(content)/2))
+		index = append(index, b[:]...)
+		binary.BigEndian.PutUint32(b[:], uint32(i+1))
+		body = append(body, b[:]...)
+		body = append(body, content...)
+		body = append(body, make([]byte, gap)...)
+	}
+	shp = append(fileHeader(typ, headerSize+len(body)), body...)
+	shx = append(fileHeader(typ, headerSize+len(index)), index...)
+	return shp, shx
+}
+
+// buildDBF returns a .dbf file of records, where deleted records start with
+// a '*'.
+func buildDBF(fields []Field, records [][]string) []byte {
+	size := 1
+	for _, f := range fields {
+		size += f.Length
+	}
+	h := make([]byte, 32)
+	h[0] = 3
+	binary.LittleEndian.PutUint32(h[4:], uint32(len(records)))
+	binary.LittleEndian.PutUint16(h[8:], uint16(32+32*len(fields)+1))
+	binary.LittleEndian.PutUint16(h[10:], uint16(size))
+	for _, f := range fields {
+		fd := make([]byte, 32)
+		copy(fd, f.Name)
+		fd[11] = f.Type
+		fd[16] = byte(f.Length)
+		fd[17] = byte(f.Decimals)
+		h = append(h, fd...)
+	}
+	h = append(h, '\r')
+	for _, record := range records {
+		if len(record) > 0 && record[0] == "*" {
+			h = append(h, '*')
+			record = record[1:]
+		} else {
+			h = append(h, ' ')
+		}
+		for i, f := range fields {
+			value := []byte(record[i])
+			for len(value) < f.Length {
+				value = append(value, ' ')
+			}
+			h = append(h, value...)
+		}
+	}
+	return append(h, 0x1A)
+}
+
+func TestPoints(t *testing.T) {
+	shp, shx := buildSHP(Point, [][]byte{
+		pointRecord(Point, 1, 2),
+		pointRecord(Point, 3, 4),
+		pointRecord(Null),
+		pointRecord(Point, 5, 6),
+	}, 0)
+	fields := []Field{
+		{Name: "NAME", Type: 'C', Length: 10},
+		{Name: "COUNT", Type: 'N', Length: 5},
+		{Name: "AREA", Type: 'N', Length: 8, Decimals: 2},
+		{Name: "RATIO", Type: 'F', Length: 8, Decimals: 3},
+		{Name: "OPEN", Type: 'L', Length: 1},
+		{Name: "BUILT", Type: 'D', Length: 8},
+	}
+	dbf := buildDBF(fields, [][]string{
+		{"Main St", "12", "1.50", "0.125", "T", "19990131"},
+		{"*", "Gone", "1", "1", "1", "F", ""},
+		{"Null", "", "", "", "?", ""},
+		{"Caf\xe9", "-3", "  10.00", "1e3", "n", ""},
+	})
+	prj := `GEOGCS["GCS_WGS_1984",DATUM["D_WGS_1984",SPHEROID["WGS_1984",6378137,298.257223563]],PRIMEM["Greenwich",0],UNIT["Degree",0.017453292519943295]]`
+	for _, withSHX := range []bool{false, true} {
+		files := Files{
+			SHP: bytes.NewReader(shp),
+			DBF: bytes.NewReader(dbf),
+			PRJ: bytes.NewReader([]byte(prj + "\n")),
+		}
+		if withSHX {
+			files.SHX = bytes.NewReader(shx)
+		}
+		sf, err := Read(files, nil)
+		expect(t, err == nil)
+		expect(t, sf.Type == Point)
+		expect(t, sf.Bounds == geometry.Rect{Min: P(-10, -20), Max: P(30, 40)})
+		expect(t, len(sf.Fields) == 6 && sf.Fields[2] == fields[2])
+		expect(t, sf.Projection == prj)
+		// the deleted record and the null shape are left out
+		children := sf.Features.Children()
+		expect(t, len(children) == 2)
+		expect(t, children[0].JSON() == `{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":{"NAME":"Main St","COUNT":12,"AREA":1.5,"RATIO":0.125,"OPEN":true,"BUILT":"1999-01-31"}}`)
+		expect(t, children[1].JSON() == `{"type":"Feature","geometry":{"type":"Point","coordinates":[5,6]},"properties":{"NAME":"Café","COUNT":-3,"AREA":10,"RATIO":1000,"OPEN":false,"BUILT":null}}`)
+	}
+
+	// without a dbf
+	sf, err := Read(Files{SHP: bytes.NewReader(shp)}, nil)
+	expect(t, err == nil && len(sf.Features.Children()) == 3)
+	expect(t, sf.Features.Children()[2].JSON() == `{"type":"Feature","geometry":{"type":"Point","coordinates":[5,6]},"properties":{}}`)
+
+	// a "type" attribute is not a special shape
+	shp, _ = buildSHP(Point, [][]byte{pointRecord(Point, 1, 2)}, 0)
+	dbf = buildDBF([]Field{
+		{Name: "type", Type: 'C', Length: 10},
+		{Name: "radius", Type: 'N', Length: 5},
+	}, [][]string{{"Circle", "100"}})
+	sf, err = Read(Files{SHP: bytes.NewReader(shp),
+		DBF: bytes.NewReader(dbf)}, nil)
+	expect(t, err == nil && len(sf.Features.Children()) == 1)
+	_, ok := sf.Features.Children()[0].(*geojson.Feature)
+	expect(t, ok)
+}
+
+func TestShapes(t *testing.T) {
+	// an exterior with a hole, an island in the hole, and another exterior,
+	// in any order and orientation
+	rings := [][]geometry.Point{
+		square(2, 2, 6), square(0, 0, 10), square(20, 0, 5), square(4, 4, 2),
+	}
+	var z []float64
+	for i, ring := range rings {
+		for range ring {
+			z = append(z, float64(i))
+		}
+	}
+	shp, shx := buildSHP(PolygonZ, [][]byte{
+		partsRecord(PolygonZ, rings, z, z),
+		partsRecord(PolygonZ, rings[1:2], z[5:10], nil),
+	}, 4)
+	sf, err := Read(Files{SHP: bytes.NewReader(shp), SHX: bytes.NewReader(shx)},
+		nil)
+	expect(t, err == nil)
+	children := sf.Features.Children()
+	expect(t, len(children) == 2)
+	mp := children[0].(*geojson.Feature).Base().(*geojson.MultiPolygon)
+	polys := mp.Children()
+	expect(t, len(polys) == 3)
+	outer := polys[0].(*geojson.Polygon).Base()
+	expect(t, outer.Exterior.Rect() == geometry.Rect{Min: P(0, 0), Max: P(10, 10)})
+	expect(t, len(outer.Holes) == 1)
+	expect(t, outer.Holes[0].Rect() == geometry.Rect{Min: P(2, 2), Max: P(8, 8)})
+	expect(t, !mp.Contains(geojson.NewPoint(P(3, 3))))
+	expect(t, mp.Contains(geojson.NewPoint(P(5, 5))))
+	expect(t, mp.Contains(geojson.NewPoint(P(1, 1))))
+	expect(t, mp.Contains(geojson.NewPoint(P(22, 2))))
+	// z values stay with their rings, and m values are left out
+	expect(t, children[1].JSON() == `{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[0,0,1],[0,10,1],[10,10,1],[10,0,1],[0,0,1]]]},"properties":{}}`)
+
+	// measured lines and points
+	shp, _ = buildSHP(PolyLineM, [][]byte{
+		partsRecord(PolyLineM, [][]geometry.Point{{P(0, 0), P(1, 1)}},
+			nil, []float64{5, 6}),
+		partsRecord(PolyLineM, [][]geometry.Point{{P(0, 0), P(1, 1)},
+			{P(2, 2), P(3, 3)}}, nil, nil),
+		multiPointRecord(MultiPointM, []geometry.Point{P(1, 2), P(3, 4)},
+			[]float64{7, 8}),
+		pointRecord(PointZ, 1, 2, 3, 4),
+	}, 0)
+	sf, err = Read(Files{SHP: bytes.NewReader(shp)}, nil)
+	expect(t, err == nil)
+	var geoms []string
+	for _, child := range sf.Features.Children() {
+		geoms = append(geoms, child.(*geojson.Feature).Base().JSON())
+	}
+	expect(t, len(geoms) == 4)
+	expect(t, geoms[0] == `{"type":"LineString","coordinates":[[0,0],[1,1]]}`)
+	expect(t, geoms[1] == `{"type":"MultiLineString","coordinates":[[[0,0],[1,1]],[[2,2],[3,3]]]}`)
+	expect(t, geoms[2] == `{"type":"MultiPoint","coordinates":[[1,2],[3,4]]}`)
+	expect(t, geoms[3] == `{"type":"Point","coordinates":[1,2,3]}`)
+}
+
+func TestInvalid(t *testing.T) {
+	shp, shx := buildSHP(Point, [][]byte{
+		pointRecord(Point, 1, 2),
+		pointRecord(Point, 3, 4),
+	}, 0)
+	read := func(shp, shx, dbf []byte) error {
+		files := Files{SHP: bytes.NewReader(shp)}
+		if shx != nil {
+			files.SHX = bytes.NewReader(shx)
+		}
+		if dbf != nil {
+			files.DBF = bytes.NewReader(dbf)
+		}
+		_, err := Read(files, nil)
+		return err
+	}
+	expect(t, read(shp, shx, nil) == nil)
+	expect(t, read(shp[:50], nil, nil) == ErrInvalidFile)
+	expect(t, read(shp[:len(shp)-1], nil, nil) == ErrInvalidFile)
+	expect(t, read(shp, shx[:len(shx)-1], nil) == ErrInvalidFile)
+	bad := append([]byte{}, shp...)
+	bad[3] = 0
+	expect(t, read(bad, nil, nil) == ErrInvalidFile)
+
+	// a dbf without a record for every shape
+	fields := []Field{{Name: "A", Type: 'C', Length: 2}}
+	expect(t, read(shp, nil, buildDBF(fields, [][]string{{"a"}})) ==
+		ErrInvalidDBF)
+	expect(t, read(shp, nil, buildDBF(fields, [][]string{{"a"}, {"b"}})) ==
+		nil)
+	expect(t, read(shp, nil, []byte{3, 0, 0}) == ErrInvalidDBF)
+
+	// multipatches are not supported
+	shp, _ = buildSHP(MultiPatch, [][]byte{putInts(nil, int(MultiPatch))}, 0)
+	expect(t, read(shp, nil, nil) == ErrUnsupportedShape)
+
+	// every truncated or corrupted file is an error, and never a panic
+	shp, _ = buildSHP(Polygon, [][]byte{
+		partsRecord(Polygon, [][]geometry.Point{square(0, 0, 1)}, nil, nil),
+	}, 0)
+	for i := range shp {
+		read(shp[:i], nil, nil)
+		for _, b := range []byte{0x00, 0x7f, 0xff} {
+			corrupt := append([]byte{}, shp...)
+			corrupt[i] = b
+			read(corrupt, nil, nil)
+		}
+	}
+}
@@ -0,0 +1,382 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package shapefile
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/tidwall/geojson/geometry"
+)
+
+// headerSize is the size of the header of .shp and .shx files
+const headerSize = 100
+
+type header struct {
+	length    int64
+	shapeType ShapeType
+	bounds    geometry.Rect
+}
+
+// readFull reads exactly len(b) bytes, where a short read is invalid
+func readFull(r io.Reader, b []byte, invalid error) error {
+	_, err := io.ReadFull(r, b)
+	return shortErr(err, invalid)
+}
+
+// shortErr returns invalid for the end of a file, or err otherwise
+func shortErr(err, invalid error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return invalid
+	}
+	return err
+}
+
+func readFloat(b []byte) float64 {
+	return math.Float64frombits(binary.LittleEndian.Uint64(b))
+}
+
+func readHeader(r io.Reader) (header, error) {
+	var b [headerSize]byte
+	if err := readFull(r, b[:], ErrInvalidFile); err != nil {
+		return header{}, err
+	}
+	if binary.BigEndian.Uint32(b[0:]) != 9994 ||
+		binary.LittleEndian.Uint32(b[28:]) != 1000 {
+		return header{}, ErrInvalidFile
+	}
+	h := header{
+		length:    int64(binary.BigEndian.Uint32(b[24:])) * 2,
+		shapeType: ShapeType(int32(binary.LittleEndian.Uint32(b[32:]))),
+	}
+	if h.length < headerSize {
+		return header{}, ErrInvalidFile
+	}
+	h.bounds.Min = geometry.Point{X: readFloat(b[36:]), Y: readFloat(b[44:])}
+	h.bounds.Max = geometry.Point{X: readFloat(b[52:]), Y: readFloat(b[60:])}
+	return h, nil
+}
+
+// readIndex returns the record offsets of a .shx file
+func readIndex(r io.Reader) ([]int64, error) {
+	h, err := readHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	var offsets []int64
+	var b [8]byte
+	for i := int64(0); i < (h.length-headerSize)/8; i++ {
+		if err := readFull(r, b[:], ErrInvalidFile); err != nil {
+			return nil, err
+		}
+		offsets = append(offsets, int64(binary.BigEndian.Uint32(b[:]))*2)
+	}
+	return offsets, nil
+}
+
+// recordReader reads the records of a .shp file in order
+type recordReader struct {
+	r   *bufio.Reader
+	pos int64
+	end int64
+}
+
+// seek skips to a record offset, which cannot be before the last record
+func (rd *recordReader) seek(off int64) error {
+	if off < rd.pos {
+		return ErrInvalidFile
+	}
+	for off > rd.pos {
+		n := off - rd.pos
+		if n > math.MaxInt32 {
+			n = math.MaxInt32
+		}
+		discarded, err := rd.r.Discard(int(n))
+		rd.pos += int64(discarded)
+		if err != nil {
+			return shortErr(err, ErrInvalidFile)
+		}
+	}
+	return nil
+}
+
+// next returns the content of the next record, or io.EOF at the end of the
+// file.
+func (rd *recordReader) next() ([]byte, error) {
+	if rd.pos >= rd.end {
+		return nil, io.EOF
+	}
+	var b [8]byte
+	if err := readFull(rd.r, b[:], ErrInvalidFile); err != nil {
+		return nil, err
+	}
+	size := int64(binary.BigEndian.Uint32(b[4:])) * 2
+	if size < 4 || rd.pos+8+size > rd.end {
+		return nil, ErrInvalidFile
+	}
+	content := make([]byte, size)
+	if err := readFull(rd.r, content, ErrInvalidFile); err != nil {
+		return nil, err
+	}
+	rd.pos += 8 + size
+	return content, nil
+}
+
+// shape is the points of a record and the points where its parts start
+type shape struct {
+	parts  []int
+	points []geometry.Point
+	zs     []float64
+}
+
+func readShape(typ ShapeType, data []byte) (shape, error) {
+	var s shape
+	hasZ := typ == PointZ || typ == MultiPointZ || typ == PolyLineZ ||
+		typ == PolygonZ
+	var numPoints, pos int
+	switch typ {
+	case Point, PointZ, PointM:
+		numPoints = 1
+	case MultiPoint, MultiPointZ, MultiPointM:
+		if len(data) < 36 {
+			return s, ErrInvalidFile
+		}
+		numPoints = int(int32(binary.LittleEndian.Uint32(data[32:])))
+		pos = 36
+	default:
+		if len(data) < 40 {
+			return s, ErrInvalidFile
+		}
+		numParts := int(int32(binary.LittleEndian.Uint32(data[32:])))
+		numPoints = int(int32(binary.LittleEndian.Uint32(data[36:])))
+		if numParts < 0 || numParts > (len(data)-40)/4 {
+			return s, ErrInvalidFile
+		}
+		pos = 40 + numParts*4
+		for i := 0; i < numParts; i++ {
+			part := int(int32(binary.LittleEndian.Uint32(data[40+i*4:])))
+			if part < 0 || part > numPoints ||
+				(i > 0 && part < s.parts[i-1]) {
+				return s, ErrInvalidFile
+			}
+			s.parts = append(s.parts, part)
+		}
+	}
+	if numPoints < 0 || numPoints > (len(data)-pos)/16 {
+		return s, ErrInvalidFile
+	}
+	s.points = make([]geometry.Point, numPoints)
+	for i := range s.points {
+		s.points[i].X = readFloat(data[pos+i*16:])
+		s.points[i].Y = readFloat(data[pos+i*16+8:])
+	}
+	if hasZ {
+		// the z values are after the points, and after a z range for
+		// shapes other than points.
+		pos += numPoints * 16
+		if typ != PointZ {
+			pos += 16
+		}
+		if numPoints > (len(data)-pos)/8 {
+			return s, ErrInvalidFile
+		}
+		s.zs = make([]float64, numPoints)
+		for i := range s.zs {
+			s.zs[i] = readFloat(data[pos+i*8:])
+		}
+	}
+	return s, nil
+}
+
+// appendShape appends the GeoJSON geometry of the content of a record, or
+// returns nil for null shapes.
+func appendShape(dst []byte, content []byte) ([]byte, error) {
+	typ := ShapeType(int32(binary.LittleEndian.Uint32(content)))
+	if typ == Null {
+		return nil, nil
+	}
+	if typ == MultiPatch {
+		return nil, ErrUnsupportedShape
+	}
+	s, err := readShape(typ, content[4:])
+	if err != nil {
+		return nil, err
+	}
+	switch typ {
+	case Point, PointZ, PointM:
+		dst = append(dst, `{"type":"Point","coordinates":`...)
+		dst = s.appendPosition(dst, 0)
+	case MultiPoint, MultiPointZ, MultiPointM:
+		if len(s.points) == 0 {
+			return nil, nil
+		}
+		dst = append(dst, `{"type":"MultiPoint","coordinates":`...)
+		dst = s.appendPositions(dst, s.ring(0, len(s.points), false))
+	case PolyLine, PolyLineZ, PolyLineM:
+		var lines [][]int
+		for i := range s.parts {
+			start, end := s.partRange(i)
+			if end-start >= 2 {
+				lines = append(lines, s.ring(start, end, false))
+			}
+		}
+		switch len(lines) {
+		case 0:
+			return nil, nil
+		case 1:
+			dst = append(dst, `{"type":"LineString","coordinates":`...)
+			dst = s.appendPositions(dst, lines[0])
+		default:
+			dst = append(dst, `{"type":"MultiLineString","coordinates":[`...)
+			for i, line := range lines {
+				if i > 0 {
+					dst = append(dst, ',')
+				}
+				dst = s.appendPositions(dst, line)
+			}
+			dst = append(dst, ']')
+		}
+	case Polygon, PolygonZ, PolygonM:
+		polys := s.assignRings()
+		if len(polys) == 0 {
+			return nil, nil
+		}
+		if len(polys) == 1 {
+			dst = append(dst, `{"type":"Polygon","coordinates":`...)
+			dst = s.appendPolygon(dst, polys[0])
+		} else {
+			dst = append(dst, `{"type":"MultiPolygon","coordinates":[`...)
+			for i, poly := range polys {
+				if i > 0 {
+					dst = append(dst, ',')
+				}
+				dst = s.appendPolygon(dst, poly)
+			}
+			dst = append(dst, ']')
+		}
+	default:
+		return nil, ErrInvalidFile
+	}
+	return append(dst, '}'), nil
+}
+
+// partRange returns the range of the points of a part
+func (s *shape) partRange(i int) (start, end int) {
+	start, end = s.parts[i], len(s.points)
+	if i < len(s.parts)-1 {
+		end = s.parts[i+1]
+	}
+	return start, end
+}
+
+// ring returns the point indexes of a range, which are closed with the
+// first point when needed.
+func (s *shape) ring(start, end int, closed bool) []int {
+	idxs := make([]int, 0, end-start+1)
+	for i := start; i < end; i++ {
+		idxs = append(idxs, i)
+	}
+	if closed && end > start && s.points[start] != s.points[end-1] {
+		idxs = append(idxs, start)
+	}
+	return idxs
+}
+
+func (s *shape) appendPosition(dst []byte, i int) []byte {
+	dst = append(dst, '[')
+	dst = strconv.AppendFloat(dst, s.points[i].X, 'f', -1, 64)
+	dst = append(dst, ',')
+	dst = strconv.AppendFloat(dst, s.points[i].Y, 'f', -1, 64)
+	if s.zs != nil {
+		dst = append(dst, ',')
+		dst = strconv.AppendFloat(dst, s.zs[i], 'f', -1, 64)
+	}
+	return append(dst, ']')
+}
+
+func (s *shape) appendPositions(dst []byte, idxs []int) []byte {
+	dst = append(dst, '[')
+	for i, idx := range idxs {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = s.appendPosition(dst, idx)
+	}
+	return append(dst, ']')
+}
+
+func (s *shape) appendPolygon(dst []byte, rings [][]int) []byte {
+	dst = append(dst, '[')
+	for i, ring := range rings {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = s.appendPositions(dst, ring)
+	}
+	return append(dst, ']')
+}
+
+type ring struct {
+	idxs []int
+	poly *geometry.Poly
+	area float64
+}
+
+// assignRings returns polygons of the rings of a shape, where each polygon
+// is an exterior ring followed by its holes. A ring that is inside of an
+// even number of rings is an exterior, and any other ring is a hole of the
+// smallest ring that contains it.
+func (s *shape) assignRings() [][][]int {
+	var rings []ring
+	for i := range s.parts {
+		start, end := s.partRange(i)
+		idxs := s.ring(start, end, true)
+		if len(idxs) < 4 {
+			continue
+		}
+		points := make([]geometry.Point, len(idxs))
+		var area float64
+		for j, idx := range idxs {
+			points[j] = s.points[idx]
+			if j > 0 {
+				a, b := points[j-1], points[j]
+				area += a.X*b.Y - b.X*a.Y
+			}
+		}
+		rings = append(rings, ring{idxs: idxs,
+			poly: geometry.NewPoly(points, nil, nil), area: math.Abs(area)})
+	}
+	sort.SliceStable(rings, func(i, j int) bool {
+		return rings[i].area > rings[j].area
+	})
+	var polys [][][]int
+	depths := make([]int, len(rings))
+	owners := make([]int, len(rings))
+	for i := range rings {
+		parent := -1
+		for j := i - 1; j >= 0; j-- {
+			if rings[j].poly.Rect().ContainsRect(rings[i].poly.Rect()) &&
+				rings[j].poly.ContainsPoly(rings[i].poly) {
+				parent = j
+				break
+			}
+		}
+		if parent >= 0 {
+			depths[i] = depths[parent] + 1
+		}
+		if depths[i]%2 == 0 {
+			owners[i] = len(polys)
+			polys = append(polys, [][]int{rings[i].idxs})
+		} else {
+			owners[i] = owners[parent]
+			polys[owners[i]] = append(polys[owners[i]], rings[i].idxs)
+		}
+	}
+	return polys
+}
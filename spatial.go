@@ -21,7 +21,8 @@ type Spatial interface {
 var _ = []Spatial{
 	&Point{}, &LineString{}, &Polygon{}, &Feature{},
 	&MultiPoint{}, &MultiLineString{}, &MultiPolygon{},
-	&GeometryCollection{}, &FeatureCollection{}, &Rect{},
+	&GeometryCollection{}, &FeatureCollection{}, &Rect{}, &Circle{},
+	&ClippedCircle{}, &Ellipse{}, &Sector{}, &Annulus{},
 	EmptySpatial{},
 }
 
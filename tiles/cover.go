@@ -0,0 +1,83 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package tiles
+
+import (
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/geojson/geometry"
+)
+
+// Cover returns the tiles at a zoom level that an object touches. The tiles
+// are tested against the object itself, and not just its bounding box, so
+// a tile in a polygon's hole or between the parts of a multi-part object is
+// left out. Objects beyond the Web Mercator latitude limit are in the top or
+// bottom row of tiles, like FromPoint. Tiles are returned in quadkey order.
+func Cover(obj geojson.Object, zoom int) []Tile {
+	return CoverRange(obj, zoom, zoom)
+}
+
+// CoverRange returns the tiles for every zoom level from minZoom to maxZoom
+// that an object touches. Each tile is followed by its covering tiles at the
+// deeper zoom levels, as in quadkey order.
+func CoverRange(obj geojson.Object, minZoom, maxZoom int) []Tile {
+	minZoom, maxZoom = clampZoom(minZoom), clampZoom(maxZoom)
+	if maxZoom < minZoom || obj.Empty() {
+		return nil
+	}
+	c := coverer{obj: obj, rect: obj.Rect(), min: minZoom, max: maxZoom}
+	c.cover(Tile{})
+	return c.tiles
+}
+
+type coverer struct {
+	obj      geojson.Object
+	rect     geometry.Rect
+	min, max int
+	tiles    []Tile
+}
+
+func (c *coverer) cover(t Tile) {
+	bounds := t.Bounds()
+	// The top and bottom rows of tiles also cover the latitudes beyond the
+	// Web Mercator limit, which FromPoint clamps to those rows.
+	if t.Y == 0 {
+		bounds.Max.Y = 90
+	}
+	if t.Y == 1<<uint(t.Z)-1 {
+		bounds.Min.Y = -90
+	}
+	if !c.rect.IntersectsRect(bounds) {
+		return
+	}
+	cell := geojson.NewRect(bounds)
+	if !c.obj.Intersects(cell) {
+		return
+	}
+	if c.obj.Contains(cell) {
+		c.all(t)
+		return
+	}
+	if t.Z >= c.min {
+		c.tiles = append(c.tiles, t)
+	}
+	if t.Z < c.max {
+		for _, child := range t.Children() {
+			c.cover(child)
+		}
+	}
+}
+
+// all adds a tile that is inside of the object, and all of its descendants
+// down to the maximum zoom level.
+func (c *coverer) all(t Tile) {
+	if t.Z >= c.min {
+		c.tiles = append(c.tiles, t)
+	}
+	if t.Z < c.max {
+		for _, child := range t.Children() {
+			c.all(child)
+		}
+	}
+}
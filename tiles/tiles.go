@@ -0,0 +1,156 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package tiles
+
+import (
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/tidwall/geojson/geo"
+	"github.com/tidwall/geojson/geometry"
+)
+
+// MaxZoom is the deepest supported zoom level
+const MaxZoom = 30
+
+// ErrInvalidQuadkey is returned when a quadkey contains characters other
+// than 0-3, or is longer than MaxZoom.
+var ErrInvalidQuadkey = errors.New("invalid quadkey")
+
+// Tile is an XYZ slippy-map tile, where X is the column from the
+// antimeridian going east, Y is the row from the top of the map going south,
+// and Z is the zoom level.
+type Tile struct {
+	X, Y, Z int
+}
+
+// FromPoint returns the tile that holds a point at a zoom level. Latitudes
+// past the edges of Web Mercator are clamped to the top or bottom row.
+func FromPoint(lat, lon float64, zoom int) Tile {
+	zoom = clampZoom(zoom)
+	n := float64(uint64(1) << uint(zoom))
+	lat = math.Max(-geo.MaxMercatorLatitude,
+		math.Min(geo.MaxMercatorLatitude, lat))
+	sin := math.Sin(lat * math.Pi / 180)
+	x := (lon + 180) / 360 * n
+	y := (0.5 - math.Log((1+sin)/(1-sin))/(4*math.Pi)) * n
+	return Tile{X: clampTile(x, n), Y: clampTile(y, n), Z: zoom}
+}
+
+func clampZoom(zoom int) int {
+	if zoom < 0 {
+		return 0
+	}
+	if zoom > MaxZoom {
+		return MaxZoom
+	}
+	return zoom
+}
+
+func clampTile(v, n float64) int {
+	if v < 0 {
+		return 0
+	}
+	if v >= n {
+		return int(n) - 1
+	}
+	return int(v)
+}
+
+// Valid returns true when the tile is on the map at its zoom level
+func (t Tile) Valid() bool {
+	if t.Z < 0 || t.Z > MaxZoom {
+		return false
+	}
+	n := 1 << uint(t.Z)
+	return t.X >= 0 && t.X < n && t.Y >= 0 && t.Y < n
+}
+
+// String returns the tile as "z/x/y"
+func (t Tile) String() string {
+	return strconv.Itoa(t.Z) + "/" + strconv.Itoa(t.X) + "/" +
+		strconv.Itoa(t.Y)
+}
+
+// Bounds returns the lon/lat rectangle covered by the tile
+func (t Tile) Bounds() geometry.Rect {
+	n := float64(uint64(1) << uint(t.Z))
+	return geometry.Rect{
+		Min: geometry.Point{X: tileLon(float64(t.X), n),
+			Y: tileLat(float64(t.Y+1), n)},
+		Max: geometry.Point{X: tileLon(float64(t.X+1), n),
+			Y: tileLat(float64(t.Y), n)},
+	}
+}
+
+func tileLon(x, n float64) float64 {
+	return x/n*360 - 180
+}
+
+func tileLat(y, n float64) float64 {
+	return math.Atan(math.Sinh(math.Pi*(1-2*y/n))) * 180 / math.Pi
+}
+
+// Parent returns the tile at the zoom level above that holds the tile. The
+// parent of a zoom 0 tile is itself.
+func (t Tile) Parent() Tile {
+	if t.Z == 0 {
+		return t
+	}
+	return Tile{X: t.X >> 1, Y: t.Y >> 1, Z: t.Z - 1}
+}
+
+// Children returns the four tiles at the next zoom level that make up the
+// tile, in quadkey order.
+func (t Tile) Children() [4]Tile {
+	x, y, z := t.X<<1, t.Y<<1, t.Z+1
+	return [4]Tile{
+		{X: x, Y: y, Z: z}, {X: x + 1, Y: y, Z: z},
+		{X: x, Y: y + 1, Z: z}, {X: x + 1, Y: y + 1, Z: z},
+	}
+}
+
+// Quadkey returns the Bing Maps quadkey for the tile. The zoom 0 tile has an
+// empty quadkey.
+func (t Tile) Quadkey() string {
+	key := make([]byte, t.Z)
+	for i := t.Z; i > 0; i-- {
+		mask := 1 << uint(i-1)
+		digit := byte('0')
+		if t.X&mask != 0 {
+			digit++
+		}
+		if t.Y&mask != 0 {
+			digit += 2
+		}
+		key[t.Z-i] = digit
+	}
+	return string(key)
+}
+
+// FromQuadkey returns the tile for a Bing Maps quadkey
+func FromQuadkey(key string) (Tile, error) {
+	if len(key) > MaxZoom {
+		return Tile{}, ErrInvalidQuadkey
+	}
+	t := Tile{Z: len(key)}
+	for i := 0; i < len(key); i++ {
+		mask := 1 << uint(len(key)-i-1)
+		switch key[i] {
+		case '0':
+		case '1':
+			t.X |= mask
+		case '2':
+			t.Y |= mask
+		case '3':
+			t.X |= mask
+			t.Y |= mask
+		default:
+			return Tile{}, ErrInvalidQuadkey
+		}
+	}
+	return t, nil
+}
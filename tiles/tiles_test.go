@@ -0,0 +1,157 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package tiles
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/geojson/geo"
+	"github.com/tidwall/geojson/geometry"
+)
+
+func init() {
+	seed := time.Now().UnixNano()
+	println(seed)
+	rand.Seed(seed)
+}
+
+func expect(t testing.TB, what bool) {
+	t.Helper()
+	if !what {
+		t.Fatal("expection failure")
+	}
+}
+
+func TestTile(t *testing.T) {
+	expect(t, FromPoint(0, 0, 0) == Tile{})
+	expect(t, FromPoint(47.6062, -122.3321, 10) == Tile{X: 164, Y: 357, Z: 10})
+	expect(t, FromPoint(90, 180, 2) == Tile{X: 3, Y: 0, Z: 2})
+	expect(t, FromPoint(-90, -180, 2) == Tile{X: 0, Y: 3, Z: 2})
+	expect(t, FromPoint(0, 0, 40).Z == MaxZoom)
+
+	b := Tile{}.Bounds()
+	expect(t, b.Min.X == -180 && b.Max.X == 180)
+	expect(t, math.Abs(b.Max.Y-geo.MaxMercatorLatitude) < 1e-12)
+	expect(t, math.Abs(b.Min.Y+geo.MaxMercatorLatitude) < 1e-12)
+	b = Tile{X: 1, Y: 1, Z: 1}.Bounds()
+	expect(t, b.Min.X == 0 && b.Max.Y == 0)
+
+	tile := Tile{X: 3, Y: 5, Z: 3}
+	expect(t, tile.String() == "3/3/5")
+	expect(t, tile.Quadkey() == "213")
+	expect(t, tile.Valid())
+	expect(t, !Tile{X: 8, Y: 5, Z: 3}.Valid())
+	expect(t, !Tile{Z: -1}.Valid())
+	parsed, err := FromQuadkey("213")
+	expect(t, err == nil && parsed == tile)
+	_, err = FromQuadkey("214")
+	expect(t, err == ErrInvalidQuadkey)
+	parsed, err = FromQuadkey("")
+	expect(t, err == nil && parsed == Tile{})
+	expect(t, tile.Parent() == Tile{X: 1, Y: 2, Z: 2})
+	expect(t, Tile{}.Parent() == Tile{})
+	for i, child := range tile.Children() {
+		expect(t, child.Parent() == tile)
+		expect(t, child.Quadkey() == "213"+string(byte('0'+i)))
+	}
+
+	for i := 0; i < 1000; i++ {
+		lat := rand.Float64()*170 - 85
+		lon := rand.Float64()*360 - 180
+		tile := FromPoint(lat, lon, rand.Intn(MaxZoom+1))
+		expect(t, tile.Valid())
+		expect(t, tile.Bounds().ContainsPoint(geometry.Point{X: lon, Y: lat}))
+		parsed, err := FromQuadkey(tile.Quadkey())
+		expect(t, err == nil && parsed == tile)
+	}
+}
+
+func TestCover(t *testing.T) {
+	// a ring with a hole that holds a whole zoom 6 tile
+	hole := Tile{X: 20, Y: 25, Z: 6}.Bounds()
+	poly := geojson.NewPolygon(geometry.NewPoly(
+		[]geometry.Point{
+			{X: -70, Y: 20}, {X: -50, Y: 20}, {X: -50, Y: 40},
+			{X: -70, Y: 40}, {X: -70, Y: 20},
+		},
+		[][]geometry.Point{{
+			{X: hole.Min.X - 0.1, Y: hole.Min.Y - 0.1},
+			{X: hole.Max.X + 0.1, Y: hole.Min.Y - 0.1},
+			{X: hole.Max.X + 0.1, Y: hole.Max.Y + 0.1},
+			{X: hole.Min.X - 0.1, Y: hole.Max.Y + 0.1},
+			{X: hole.Min.X - 0.1, Y: hole.Min.Y - 0.1},
+		}}, nil))
+	tiles := Cover(poly, 6)
+	seen := make(map[Tile]bool)
+	for _, tile := range tiles {
+		expect(t, tile.Z == 6 && !seen[tile])
+		seen[tile] = true
+		expect(t, poly.Intersects(geojson.NewRect(tile.Bounds())))
+	}
+	expect(t, !seen[Tile{X: 20, Y: 25, Z: 6}])
+
+	// every bbox tile that is left out does not touch the polygon
+	rect := poly.Rect()
+	min := FromPoint(rect.Max.Y, rect.Min.X, 6)
+	max := FromPoint(rect.Min.Y, rect.Max.X, 6)
+	var bbox int
+	for x := min.X; x <= max.X; x++ {
+		for y := min.Y; y <= max.Y; y++ {
+			bbox++
+			tile := Tile{X: x, Y: y, Z: 6}
+			expect(t, seen[tile] ==
+				poly.Intersects(geojson.NewRect(tile.Bounds())))
+		}
+	}
+	expect(t, len(tiles) == bbox-1)
+
+	// ranges hold every zoom and are in quadkey order
+	tiles = CoverRange(poly, 2, 6)
+	counts := make(map[int]int)
+	for i, tile := range tiles {
+		counts[tile.Z]++
+		if i > 0 {
+			expect(t, tiles[i-1].Quadkey() < tile.Quadkey())
+		}
+	}
+	expect(t, counts[6] == len(seen))
+	expect(t, counts[2] >= 1 && counts[1] == 0 && counts[7] == 0)
+	expect(t, len(CoverRange(poly, 6, 2)) == 0)
+
+	// points and lines
+	tiles = Cover(geojson.NewPoint(geometry.Point{X: -122.3321, Y: 47.6062}),
+		10)
+	expect(t, len(tiles) == 1 && tiles[0] == Tile{X: 164, Y: 357, Z: 10})
+	line := geojson.NewLineString(geometry.NewLine([]geometry.Point{
+		{X: -10, Y: -10}, {X: 10, Y: 10},
+	}, nil))
+	tiles = Cover(line, 8)
+	seen = make(map[Tile]bool)
+	for _, tile := range tiles {
+		seen[tile] = true
+	}
+	// the diagonal skips the off-diagonal tiles in its bbox
+	expect(t, len(tiles) < 20*20)
+	expect(t, seen[FromPoint(0.1, 0.1, 8)] && seen[FromPoint(-5, -5, 8)])
+	expect(t, !seen[FromPoint(-9, 9, 8)])
+	expect(t, len(Cover(geojson.NewMultiPoint(nil), 3)) == 0)
+
+	// beyond the Web Mercator limit, like FromPoint
+	for _, lat := range []float64{88, -88, 90, -90} {
+		tiles = Cover(geojson.NewPoint(geometry.Point{X: 10, Y: lat}), 3)
+		expect(t, len(tiles) == 1 && tiles[0] == FromPoint(lat, 10, 3))
+	}
+	tiles = Cover(geojson.NewRect(geometry.Rect{
+		Min: geometry.Point{X: -180, Y: 86}, Max: geometry.Point{X: 180, Y: 90},
+	}), 2)
+	expect(t, len(tiles) == 4)
+	for _, tile := range tiles {
+		expect(t, tile.Y == 0)
+	}
+}
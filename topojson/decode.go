@@ -0,0 +1,252 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package topojson
+
+import (
+	"errors"
+
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/geojson/geometry"
+	"github.com/tidwall/gjson"
+)
+
+// ErrInvalidTopology is returned when the data is not a valid TopoJSON
+// topology.
+var ErrInvalidTopology = errors.New("invalid topology")
+
+// Decode returns the named objects of a TopoJSON topology, each as a
+// FeatureCollection in the coordinates of the topology. Quantized arcs are
+// delta decoded and transformed. The geometries of a GeometryCollection
+// object become the features of the collection, with their "id" and
+// "properties" members, and any other object becomes a single feature.
+// Geometries with a null type are left out.
+func Decode(data string) (map[string]*geojson.FeatureCollection, error) {
+	if !gjson.Valid(data) {
+		return nil, ErrInvalidTopology
+	}
+	root := gjson.Parse(data)
+	if root.Get("type").String() != "Topology" {
+		return nil, ErrInvalidTopology
+	}
+	var d decoder
+	if transform := root.Get("transform"); transform.Exists() {
+		scale := transform.Get("scale").Array()
+		translate := transform.Get("translate").Array()
+		if len(scale) != 2 || len(translate) != 2 {
+			return nil, ErrInvalidTopology
+		}
+		d.quantized = true
+		d.scale = geometry.Point{X: scale[0].Float(), Y: scale[1].Float()}
+		d.translate = geometry.Point{
+			X: translate[0].Float(), Y: translate[1].Float(),
+		}
+	}
+	for _, arc := range root.Get("arcs").Array() {
+		var points []geometry.Point
+		var x, y float64
+		for _, pos := range arc.Array() {
+			xy := pos.Array()
+			if len(xy) < 2 {
+				return nil, ErrInvalidTopology
+			}
+			if d.quantized {
+				// positions after the first are deltas
+				x += xy[0].Float()
+				y += xy[1].Float()
+				points = append(points, d.transform(x, y))
+			} else {
+				points = append(points,
+					geometry.Point{X: xy[0].Float(), Y: xy[1].Float()})
+			}
+		}
+		d.arcs = append(d.arcs, points)
+	}
+	objects := make(map[string]*geojson.FeatureCollection)
+	var err error
+	root.Get("objects").ForEach(func(name, obj gjson.Result) bool {
+		var features []geojson.Object
+		if obj.Get("type").String() == "GeometryCollection" {
+			for _, g := range obj.Get("geometries").Array() {
+				if features, err = d.appendFeature(features, g); err != nil {
+					return false
+				}
+			}
+		} else if features, err = d.appendFeature(features, obj); err != nil {
+			return false
+		}
+		objects[name.String()] = geojson.NewFeatureCollection(features)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+type decoder struct {
+	quantized bool
+	scale     geometry.Point
+	translate geometry.Point
+	arcs      [][]geometry.Point
+}
+
+func (d *decoder) transform(x, y float64) geometry.Point {
+	return geometry.Point{
+		X: x*d.scale.X + d.translate.X,
+		Y: y*d.scale.Y + d.translate.Y,
+	}
+}
+
+func (d *decoder) appendFeature(features []geojson.Object, g gjson.Result,
+) ([]geojson.Object, error) {
+	obj, err := d.geometry(g)
+	if err != nil || obj == nil {
+		return features, err
+	}
+	var members []byte
+	if id := g.Get("id"); id.Exists() {
+		members = append(members, `{"id":`...)
+		members = append(members, id.Raw...)
+	}
+	if props := g.Get("properties"); props.Exists() {
+		if len(members) == 0 {
+			members = append(members, '{')
+		} else {
+			members = append(members, ',')
+		}
+		members = append(members, `"properties":`...)
+		members = append(members, props.Raw...)
+	}
+	if len(members) > 0 {
+		members = append(members, '}')
+	}
+	return append(features, geojson.NewFeature(obj, string(members))), nil
+}
+
+func (d *decoder) geometry(g gjson.Result) (geojson.Object, error) {
+	switch g.Get("type").String() {
+	case "", "null":
+		return nil, nil
+	case "Point":
+		point, err := d.position(g.Get("coordinates"))
+		if err != nil {
+			return nil, err
+		}
+		return geojson.NewPoint(point), nil
+	case "MultiPoint":
+		var points []geometry.Point
+		for _, pos := range g.Get("coordinates").Array() {
+			point, err := d.position(pos)
+			if err != nil {
+				return nil, err
+			}
+			points = append(points, point)
+		}
+		return geojson.NewMultiPoint(points), nil
+	case "LineString":
+		points, err := d.stitch(g.Get("arcs"))
+		if err != nil {
+			return nil, err
+		}
+		return geojson.NewLineString(geometry.NewLine(points, nil)), nil
+	case "MultiLineString":
+		var lines []*geometry.Line
+		for _, arcs := range g.Get("arcs").Array() {
+			points, err := d.stitch(arcs)
+			if err != nil {
+				return nil, err
+			}
+			lines = append(lines, geometry.NewLine(points, nil))
+		}
+		return geojson.NewMultiLineString(lines), nil
+	case "Polygon":
+		poly, err := d.poly(g.Get("arcs"))
+		if err != nil {
+			return nil, err
+		}
+		return geojson.NewPolygon(poly), nil
+	case "MultiPolygon":
+		var polys []*geometry.Poly
+		for _, arcs := range g.Get("arcs").Array() {
+			poly, err := d.poly(arcs)
+			if err != nil {
+				return nil, err
+			}
+			polys = append(polys, poly)
+		}
+		return geojson.NewMultiPolygon(polys), nil
+	case "GeometryCollection":
+		var children []geojson.Object
+		for _, child := range g.Get("geometries").Array() {
+			obj, err := d.geometry(child)
+			if err != nil {
+				return nil, err
+			}
+			if obj != nil {
+				children = append(children, obj)
+			}
+		}
+		return geojson.NewGeometryCollection(children), nil
+	}
+	return nil, ErrInvalidTopology
+}
+
+// position returns a point position, which is not delta encoded
+func (d *decoder) position(pos gjson.Result) (geometry.Point, error) {
+	xy := pos.Array()
+	if len(xy) < 2 {
+		return geometry.Point{}, ErrInvalidTopology
+	}
+	if d.quantized {
+		return d.transform(xy[0].Float(), xy[1].Float()), nil
+	}
+	return geometry.Point{X: xy[0].Float(), Y: xy[1].Float()}, nil
+}
+
+func (d *decoder) poly(rings gjson.Result) (*geometry.Poly, error) {
+	var exterior []geometry.Point
+	var holes [][]geometry.Point
+	for i, arcs := range rings.Array() {
+		points, err := d.stitch(arcs)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			exterior = points
+		} else {
+			holes = append(holes, points)
+		}
+	}
+	return geometry.NewPoly(exterior, holes, nil), nil
+}
+
+// stitch joins arcs into a single sequence of points. A negative index i
+// is the arc at ^i in reverse. The first point of each arc after the first
+// is the same as the last point of the arc before it, and is skipped.
+func (d *decoder) stitch(arcs gjson.Result) ([]geometry.Point, error) {
+	var points []geometry.Point
+	for i, idx := range arcs.Array() {
+		n := idx.Int()
+		reverse := n < 0
+		if reverse {
+			n = ^n
+		}
+		if n >= int64(len(d.arcs)) {
+			return nil, ErrInvalidTopology
+		}
+		arc := d.arcs[n]
+		for j := range arc {
+			if i > 0 && j == 0 {
+				continue
+			}
+			if reverse {
+				points = append(points, arc[len(arc)-1-j])
+			} else {
+				points = append(points, arc[j])
+			}
+		}
+	}
+	return points, nil
+}
@@ -0,0 +1,513 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package topojson
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"strconv"
+
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/geojson/geometry"
+	"github.com/tidwall/gjson"
+)
+
+// EncodeOptions ...
+type EncodeOptions struct {
+	// Quantization is the number of positions along each axis that the
+	// coordinates are rounded to, such as 1e4 or 1e5. Quantized arcs are
+	// delta encoded, and the topology has a transform. Zero means that the
+	// coordinates are kept as is.
+	Quantization int
+}
+
+// Encode returns a TopoJSON topology that holds the collection as a single
+// GeometryCollection object with a name. Lines and polygon rings are cut
+// where they meet other lines and rings, and edges that are shared become
+// a single arc that is used by each of the geometries. The "id" and
+// "properties" members of features are kept. Rects and shapes, such as
+// circles, are converted to polygons.
+func Encode(name string, col geojson.Collection, opts *EncodeOptions) []byte {
+	var e encoder
+	var geoms []*node
+	for _, child := range col.Children() {
+		geoms = append(geoms, e.node(child))
+	}
+	if opts != nil && opts.Quantization > 1 {
+		e.quantize(geoms, float64(opts.Quantization))
+	}
+	e.removeRepeats()
+	e.findJunctions()
+	e.cutArcs()
+
+	dst := []byte(`{"type":"Topology"`)
+	if e.quantized {
+		dst = append(dst, `,"transform":{"scale":[`...)
+		dst = appendFloat(dst, e.scale.X)
+		dst = append(dst, ',')
+		dst = appendFloat(dst, e.scale.Y)
+		dst = append(dst, `],"translate":[`...)
+		dst = appendFloat(dst, e.bbox.Min.X)
+		dst = append(dst, ',')
+		dst = appendFloat(dst, e.bbox.Min.Y)
+		dst = append(dst, "]}"...)
+	}
+	if e.hasBBox {
+		dst = append(dst, `,"bbox":[`...)
+		dst = appendFloat(dst, e.bbox.Min.X)
+		dst = append(dst, ',')
+		dst = appendFloat(dst, e.bbox.Min.Y)
+		dst = append(dst, ',')
+		dst = appendFloat(dst, e.bbox.Max.X)
+		dst = append(dst, ',')
+		dst = appendFloat(dst, e.bbox.Max.Y)
+		dst = append(dst, ']')
+	}
+	key, _ := json.Marshal(name)
+	dst = append(dst, `,"objects":{`...)
+	dst = append(dst, key...)
+	dst = append(dst, `:{"type":"GeometryCollection","geometries":[`...)
+	for i, geom := range geoms {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = e.appendGeometry(dst, geom)
+	}
+	dst = append(dst, `]}},"arcs":[`...)
+	for i, arc := range e.arcs {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = append(dst, '[')
+		var prev geometry.Point
+		for j, point := range arc {
+			if j > 0 {
+				dst = append(dst, ',')
+			}
+			if e.quantized {
+				// positions after the first are deltas
+				dst = e.appendPosition(dst, geometry.Point{
+					X: point.X - prev.X, Y: point.Y - prev.Y,
+				})
+				prev = point
+			} else {
+				dst = e.appendPosition(dst, point)
+			}
+		}
+		dst = append(dst, ']')
+	}
+	dst = append(dst, "]}"...)
+	return dst
+}
+
+// node is a geometry of the topology
+type node struct {
+	typ      string
+	id       string // raw json
+	props    string // raw json
+	points   []geometry.Point
+	lines    []int   // sequence for each line
+	polys    [][]int // sequences for the rings of each polygon
+	children []*node
+}
+
+// encoder cuts the lines and rings of the geometries, which are called
+// sequences, into arcs.
+type encoder struct {
+	seqs   [][]geometry.Point
+	closed []bool
+
+	hasBBox   bool
+	bbox      geometry.Rect
+	quantized bool
+	scale     geometry.Point
+
+	junctions map[geometry.Point]bool
+	arcs      [][]geometry.Point
+	arcIdx    map[string]int
+	seqArcs   [][]int // the arcs of each sequence
+}
+
+func (e *encoder) addSeq(points []geometry.Point, closed bool) int {
+	for _, point := range points {
+		e.extend(point)
+	}
+	e.seqs = append(e.seqs, points)
+	e.closed = append(e.closed, closed)
+	return len(e.seqs) - 1
+}
+
+func (e *encoder) extend(point geometry.Point) {
+	if !e.hasBBox {
+		e.bbox = geometry.Rect{Min: point, Max: point}
+		e.hasBBox = true
+		return
+	}
+	e.bbox.Min.X = math.Min(e.bbox.Min.X, point.X)
+	e.bbox.Min.Y = math.Min(e.bbox.Min.Y, point.Y)
+	e.bbox.Max.X = math.Max(e.bbox.Max.X, point.X)
+	e.bbox.Max.Y = math.Max(e.bbox.Max.Y, point.Y)
+}
+
+func (e *encoder) addPoly(poly *geometry.Poly) []int {
+	rings := []int{e.addSeq(seriesPoints(poly.Exterior), true)}
+	for _, hole := range poly.Holes {
+		rings = append(rings, e.addSeq(seriesPoints(hole), true))
+	}
+	return rings
+}
+
+func seriesPoints(series geometry.Series) []geometry.Point {
+	points := make([]geometry.Point, series.NumPoints())
+	for i := range points {
+		points[i] = series.PointAt(i)
+	}
+	return points
+}
+
+func (e *encoder) node(obj geojson.Object) *node {
+	switch g := obj.(type) {
+	case *geojson.Feature:
+		n := e.node(g.Base())
+		members := g.Members()
+		n.id = gjson.Get(members, "id").Raw
+		n.props = gjson.Get(members, "properties").Raw
+		return n
+	case *geojson.Point:
+		e.extend(g.Base())
+		return &node{typ: "Point", points: []geometry.Point{g.Base()}}
+	case *geojson.SimplePoint:
+		e.extend(g.Point)
+		return &node{typ: "Point", points: []geometry.Point{g.Point}}
+	case *geojson.MultiPoint:
+		n := &node{typ: "MultiPoint"}
+		for _, child := range g.Children() {
+			point := child.Center()
+			e.extend(point)
+			n.points = append(n.points, point)
+		}
+		return n
+	case *geojson.LineString:
+		return &node{typ: "LineString",
+			lines: []int{e.addSeq(seriesPoints(g.Base()), false)}}
+	case *geojson.MultiLineString:
+		n := &node{typ: "MultiLineString"}
+		for _, child := range g.Children() {
+			if line, ok := child.(*geojson.LineString); ok {
+				n.lines = append(n.lines,
+					e.addSeq(seriesPoints(line.Base()), false))
+			}
+		}
+		return n
+	case *geojson.Polygon:
+		return &node{typ: "Polygon", polys: [][]int{e.addPoly(g.Base())}}
+	case *geojson.Rect:
+		return &node{typ: "Polygon", polys: [][]int{e.addPoly(
+			geometry.NewPoly(seriesPoints(g.Base()), nil, nil))}}
+	case *geojson.MultiPolygon:
+		n := &node{typ: "MultiPolygon"}
+		for _, child := range g.Children() {
+			if poly, ok := child.(*geojson.Polygon); ok {
+				n.polys = append(n.polys, e.addPoly(poly.Base()))
+			}
+		}
+		return n
+	case geojson.Collection:
+		n := &node{typ: "GeometryCollection"}
+		for _, child := range g.Children() {
+			n.children = append(n.children, e.node(child))
+		}
+		return n
+	case interface{ Primative() geojson.Object }:
+		return e.node(g.Primative())
+	}
+	return &node{}
+}
+
+// quantize rounds every coordinate to an integer position in the bounding
+// box of the topology.
+func (e *encoder) quantize(geoms []*node, n float64) {
+	if !e.hasBBox {
+		return
+	}
+	e.quantized = true
+	e.scale = geometry.Point{
+		X: (e.bbox.Max.X - e.bbox.Min.X) / (n - 1),
+		Y: (e.bbox.Max.Y - e.bbox.Min.Y) / (n - 1),
+	}
+	if e.scale.X == 0 {
+		e.scale.X = 1
+	}
+	if e.scale.Y == 0 {
+		e.scale.Y = 1
+	}
+	for _, seq := range e.seqs {
+		e.quantizePoints(seq)
+	}
+	var quantizeNodes func(geoms []*node)
+	quantizeNodes = func(geoms []*node) {
+		for _, geom := range geoms {
+			e.quantizePoints(geom.points)
+			quantizeNodes(geom.children)
+		}
+	}
+	quantizeNodes(geoms)
+}
+
+func (e *encoder) quantizePoints(points []geometry.Point) {
+	for i, point := range points {
+		points[i] = geometry.Point{
+			X: math.Round((point.X - e.bbox.Min.X) / e.scale.X),
+			Y: math.Round((point.Y - e.bbox.Min.Y) / e.scale.Y),
+		}
+	}
+}
+
+// removeRepeats removes points that are the same as the point before them,
+// which happens when points are quantized to the same position.
+func (e *encoder) removeRepeats() {
+	for i, seq := range e.seqs {
+		var j int
+		for k, point := range seq {
+			if k > 0 && point == seq[j-1] {
+				continue
+			}
+			seq[j] = point
+			j++
+		}
+		e.seqs[i] = seq[:j]
+	}
+}
+
+// ring returns the points of a closed sequence without its closing point
+func (e *encoder) ring(i int) []geometry.Point {
+	seq := e.seqs[i]
+	if len(seq) > 1 && seq[0] == seq[len(seq)-1] {
+		return seq[:len(seq)-1]
+	}
+	return seq
+}
+
+// findJunctions finds the points where sequences meet or part ways. The ends
+// of lines are junctions, and so is any point that has different neighbors
+// in different places.
+func (e *encoder) findJunctions() {
+	e.junctions = make(map[geometry.Point]bool)
+	type neighbors struct{ a, b geometry.Point }
+	seen := make(map[geometry.Point]neighbors)
+	visit := func(point, prev, next geometry.Point) {
+		if e.junctions[point] {
+			return
+		}
+		if n, ok := seen[point]; ok {
+			if !(n.a == prev && n.b == next) && !(n.a == next && n.b == prev) {
+				e.junctions[point] = true
+			}
+			return
+		}
+		seen[point] = neighbors{prev, next}
+	}
+	for i, seq := range e.seqs {
+		if e.closed[i] {
+			ring := e.ring(i)
+			for j, point := range ring {
+				visit(point, ring[(j+len(ring)-1)%len(ring)],
+					ring[(j+1)%len(ring)])
+			}
+			continue
+		}
+		if len(seq) == 0 {
+			continue
+		}
+		e.junctions[seq[0]] = true
+		e.junctions[seq[len(seq)-1]] = true
+		for j := 1; j < len(seq)-1; j++ {
+			visit(seq[j], seq[j-1], seq[j+1])
+		}
+	}
+}
+
+// cutArcs cuts the sequences at their junctions, and adds the pieces as arcs
+// that are shared by pieces with the same points in either direction.
+func (e *encoder) cutArcs() {
+	e.arcIdx = make(map[string]int)
+	e.seqArcs = make([][]int, len(e.seqs))
+	for i, seq := range e.seqs {
+		if !e.closed[i] {
+			e.seqArcs[i] = e.cut(seq)
+			continue
+		}
+		ring := e.ring(i)
+		if len(ring) == 0 {
+			continue
+		}
+		start := -1
+		for j, point := range ring {
+			if e.junctions[point] {
+				start = j
+				break
+			}
+		}
+		if start == -1 {
+			// a ring without junctions starts at its lowest point, so that
+			// rings with the same points share the same arc
+			start = 0
+			for j, point := range ring {
+				low := ring[start]
+				if point.X < low.X || (point.X == low.X && point.Y < low.Y) {
+					start = j
+				}
+			}
+		}
+		rotated := make([]geometry.Point, 0, len(ring)+1)
+		rotated = append(rotated, ring[start:]...)
+		rotated = append(rotated, ring[:start]...)
+		rotated = append(rotated, ring[start])
+		e.seqArcs[i] = e.cut(rotated)
+	}
+}
+
+func (e *encoder) cut(seq []geometry.Point) []int {
+	var arcs []int
+	begin := 0
+	for j := 1; j < len(seq); j++ {
+		if j == len(seq)-1 || e.junctions[seq[j]] {
+			arcs = append(arcs, e.addArc(seq[begin:j+1]))
+			begin = j
+		}
+	}
+	if len(seq) == 1 {
+		arcs = append(arcs, e.addArc(seq))
+	}
+	return arcs
+}
+
+func (e *encoder) addArc(points []geometry.Point) int {
+	if idx, ok := e.arcIdx[arcKey(points, false)]; ok {
+		return idx
+	}
+	if idx, ok := e.arcIdx[arcKey(points, true)]; ok {
+		return ^idx
+	}
+	idx := len(e.arcs)
+	e.arcs = append(e.arcs, points)
+	e.arcIdx[arcKey(points, false)] = idx
+	return idx
+}
+
+func arcKey(points []geometry.Point, reverse bool) string {
+	key := make([]byte, len(points)*16)
+	for i := range points {
+		point := points[i]
+		if reverse {
+			point = points[len(points)-1-i]
+		}
+		binary.LittleEndian.PutUint64(key[i*16:], math.Float64bits(point.X))
+		binary.LittleEndian.PutUint64(key[i*16+8:],
+			math.Float64bits(point.Y))
+	}
+	return string(key)
+}
+
+func appendFloat(dst []byte, f float64) []byte {
+	return strconv.AppendFloat(dst, f, 'f', -1, 64)
+}
+
+func (e *encoder) appendPosition(dst []byte, point geometry.Point) []byte {
+	dst = append(dst, '[')
+	dst = appendFloat(dst, point.X)
+	dst = append(dst, ',')
+	dst = appendFloat(dst, point.Y)
+	return append(dst, ']')
+}
+
+func (e *encoder) appendArcs(dst []byte, seq int) []byte {
+	dst = append(dst, '[')
+	for i, arc := range e.seqArcs[seq] {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = strconv.AppendInt(dst, int64(arc), 10)
+	}
+	return append(dst, ']')
+}
+
+func (e *encoder) appendPoly(dst []byte, rings []int) []byte {
+	dst = append(dst, '[')
+	for i, ring := range rings {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = e.appendArcs(dst, ring)
+	}
+	return append(dst, ']')
+}
+
+func (e *encoder) appendGeometry(dst []byte, n *node) []byte {
+	if n.typ == "" {
+		dst = append(dst, `{"type":null`...)
+	} else {
+		dst = append(dst, `{"type":"`...)
+		dst = append(dst, n.typ...)
+		dst = append(dst, '"')
+	}
+	switch n.typ {
+	case "Point":
+		dst = append(dst, `,"coordinates":`...)
+		dst = e.appendPosition(dst, n.points[0])
+	case "MultiPoint":
+		dst = append(dst, `,"coordinates":[`...)
+		for i, point := range n.points {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = e.appendPosition(dst, point)
+		}
+		dst = append(dst, ']')
+	case "LineString":
+		dst = append(dst, `,"arcs":`...)
+		dst = e.appendArcs(dst, n.lines[0])
+	case "MultiLineString":
+		dst = append(dst, `,"arcs":[`...)
+		for i, line := range n.lines {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = e.appendArcs(dst, line)
+		}
+		dst = append(dst, ']')
+	case "Polygon":
+		dst = append(dst, `,"arcs":`...)
+		dst = e.appendPoly(dst, n.polys[0])
+	case "MultiPolygon":
+		dst = append(dst, `,"arcs":[`...)
+		for i, rings := range n.polys {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = e.appendPoly(dst, rings)
+		}
+		dst = append(dst, ']')
+	case "GeometryCollection":
+		dst = append(dst, `,"geometries":[`...)
+		for i, child := range n.children {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = e.appendGeometry(dst, child)
+		}
+		dst = append(dst, ']')
+	}
+	if n.id != "" {
+		dst = append(dst, `,"id":`...)
+		dst = append(dst, n.id...)
+	}
+	if n.props != "" {
+		dst = append(dst, `,"properties":`...)
+		dst = append(dst, n.props...)
+	}
+	return append(dst, '}')
+}
@@ -0,0 +1,238 @@
+// Copyright 2018 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package topojson
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/geojson/geometry"
+	"github.com/tidwall/gjson"
+)
+
+func expect(t testing.TB, what bool) {
+	t.Helper()
+	if !what {
+		t.Fatal("expection failure")
+	}
+}
+
+func P(x, y float64) geometry.Point {
+	return geometry.Point{X: x, Y: y}
+}
+
+func near(a, b geometry.Point, tol float64) bool {
+	return math.Abs(a.X-b.X) <= tol && math.Abs(a.Y-b.Y) <= tol
+}
+
+func expectPoints(t *testing.T, series geometry.Series,
+	points []geometry.Point, tol float64,
+) {
+	t.Helper()
+	expect(t, series.NumPoints() == len(points))
+	for i, point := range points {
+		expect(t, near(series.PointAt(i), point, tol))
+	}
+}
+
+func TestDecode(t *testing.T) {
+	// the example from the TopoJSON specification
+	objects, err := Decode(`{
+		"type": "Topology",
+		"transform": {
+			"scale": [0.0005000500050005, 0.00010001000100010001],
+			"translate": [100, 0]
+		},
+		"objects": {
+			"example": {
+				"type": "GeometryCollection",
+				"geometries": [
+					{"type": "Point", "properties": {"prop0": "value0"},
+						"coordinates": [4000, 5000]},
+					{"type": "LineString", "id": 9,
+						"properties": {"prop0": "value0", "prop1": 0},
+						"arcs": [0]},
+					{"type": "Polygon",
+						"properties": {"prop0": "value0",
+							"prop1": {"this": "that"}},
+						"arcs": [[-2]]},
+					{"type": null}
+				]
+			}
+		},
+		"arcs": [
+			[[4000, 0], [1999, 9999], [2000, -9999], [2000, 9999]],
+			[[0, 0], [0, 9999], [2000, 0], [0, -9999], [-2000, 0]]
+		]
+	}`)
+	expect(t, err == nil && len(objects) == 1)
+	children := objects["example"].Children()
+	expect(t, len(children) == 3)
+	tol := 1e-3
+
+	f := children[0].(*geojson.Feature)
+	expect(t, near(f.Base().(*geojson.Point).Base(), P(102, 0.5), tol))
+	expect(t, gjson.Get(f.Members(), "properties.prop0").String() == "value0")
+
+	f = children[1].(*geojson.Feature)
+	expect(t, gjson.Get(f.Members(), "id").Int() == 9)
+	expectPoints(t, f.Base().(*geojson.LineString).Base(), []geometry.Point{
+		P(102, 0), P(103, 1), P(104, 0), P(105, 1),
+	}, tol)
+
+	f = children[2].(*geojson.Feature)
+	expect(t, gjson.Get(f.Members(), "properties.prop1.this").String() ==
+		"that")
+	expectPoints(t, f.Base().(*geojson.Polygon).Base().Exterior,
+		[]geometry.Point{
+			P(100, 0), P(101, 0), P(101, 1), P(100, 1), P(100, 0),
+		}, tol)
+
+	// without a transform, and with a single geometry object
+	objects, err = Decode(`{"type":"Topology","objects":{
+		"a":{"type":"MultiLineString","arcs":[[0],[-1]]},
+		"b":{"type":"GeometryCollection","geometries":[
+			{"type":"MultiPoint","coordinates":[[1,2],[3,4]]},
+			{"type":"GeometryCollection","geometries":[
+				{"type":"MultiPolygon","arcs":[[[1]]]}
+			]}
+		]}},
+		"arcs":[[[0,0],[1,1],[2,0]],[[0,0],[0,1],[1,1],[0,0]]]}`)
+	expect(t, err == nil && len(objects) == 2)
+	lines := objects["a"].Children()[0].(*geojson.Feature).Base()
+	second := lines.(*geojson.MultiLineString).Children()[1]
+	expectPoints(t, second.(*geojson.LineString).Base(), []geometry.Point{
+		P(2, 0), P(1, 1), P(0, 0),
+	}, 0)
+	children = objects["b"].Children()
+	expect(t, len(children) == 2)
+	mp := children[0].(*geojson.Feature).Base().(*geojson.MultiPoint)
+	expect(t, mp.Children()[1].Center() == P(3, 4))
+	gc := children[1].(*geojson.Feature).Base().(*geojson.GeometryCollection)
+	expect(t, gc.Children()[0].(*geojson.MultiPolygon).Contains(
+		geojson.NewPoint(P(0.25, 0.5))))
+
+	_, err = Decode(`{"type":"Topology",`)
+	expect(t, err == ErrInvalidTopology)
+	_, err = Decode(`{"type":"FeatureCollection","features":[]}`)
+	expect(t, err == ErrInvalidTopology)
+	_, err = Decode(`{"type":"Topology","objects":{"a":{"type":"LineString",
+		"arcs":[3]}},"arcs":[]}`)
+	expect(t, err == ErrInvalidTopology)
+	_, err = Decode(`{"type":"Topology","objects":{"a":{"type":"Curve"}}}`)
+	expect(t, err == ErrInvalidTopology)
+}
+
+func square(x, y, size float64) []geometry.Point {
+	return []geometry.Point{
+		P(x, y), P(x+size, y), P(x+size, y+size), P(x, y+size), P(x, y),
+	}
+}
+
+func TestEncode(t *testing.T) {
+	// two squares that share an edge, and an island in a hole
+	fc := geojson.NewFeatureCollection([]geojson.Object{
+		geojson.NewFeature(geojson.NewPolygon(geometry.NewPoly(
+			square(0, 0, 10), nil, nil)),
+			`{"id":"a","properties":{"name":"west"}}`),
+		geojson.NewFeature(geojson.NewPolygon(geometry.NewPoly(
+			[]geometry.Point{P(20, 0), P(20, 10), P(10, 10),
+				P(10, 0), P(20, 0)}, nil, nil)),
+			`{"id":"b","properties":{"name":"east"}}`),
+		geojson.NewPolygon(geometry.NewPoly(square(30, 0, 10),
+			[][]geometry.Point{square(32, 2, 4)}, nil)),
+		geojson.NewPolygon(geometry.NewPoly(square(32, 2, 4), nil, nil)),
+		geojson.NewPoint(P(50, 50)),
+	})
+	data := Encode("places", fc, nil)
+	expect(t, gjson.ValidBytes(data))
+	topo := gjson.ParseBytes(data)
+	expect(t, !topo.Get("transform").Exists())
+	expect(t, topo.Get("bbox").Raw == "[0,0,50,50]")
+	// west, east, the shared edge, the outer ring, and the island ring
+	expect(t, len(topo.Get("arcs").Array()) == 5)
+	geoms := topo.Get("objects.places.geometries").Array()
+	expect(t, len(geoms) == 5)
+	expect(t, geoms[0].Get("id").String() == "a")
+	expect(t, len(geoms[0].Get("arcs.0").Array()) == 2)
+	expect(t, len(geoms[1].Get("arcs.0").Array()) == 2)
+	expect(t, geoms[0].Get("arcs.0.#(<0)").Exists() ||
+		geoms[1].Get("arcs.0.#(<0)").Exists())
+	expect(t, geoms[2].Get("arcs.1.0").Int() == ^geoms[3].Get("arcs.0.0").Int() ||
+		geoms[2].Get("arcs.1.0").Int() == geoms[3].Get("arcs.0.0").Int())
+
+	objects, err := Decode(string(data))
+	expect(t, err == nil)
+	children := objects["places"].Children()
+	expect(t, len(children) == 5)
+	for i, child := range children {
+		orig := fc.Children()[i]
+		expect(t, child.Rect() == orig.Rect())
+		expect(t, child.NumPoints() == orig.NumPoints())
+		expect(t, child.Contains(geojson.NewPoint(orig.Center())) ==
+			orig.Contains(geojson.NewPoint(orig.Center())))
+		if f, ok := orig.(*geojson.Feature); ok {
+			expect(t, child.(*geojson.Feature).Members() == f.Members())
+		}
+	}
+	expect(t, !children[2].Contains(geojson.NewPoint(P(34, 4))))
+	expect(t, children[3].Contains(geojson.NewPoint(P(34, 4))))
+}
+
+func TestEncodeLines(t *testing.T) {
+	// two lines that share a middle part
+	fc := geojson.NewFeatureCollection([]geojson.Object{
+		geojson.NewLineString(geometry.NewLine([]geometry.Point{
+			P(0, 0), P(1, 1), P(2, 1), P(3, 0),
+		}, nil)),
+		geojson.NewMultiLineString([]*geometry.Line{
+			geometry.NewLine([]geometry.Point{
+				P(0, 2), P(1, 1), P(2, 1), P(3, 2),
+			}, nil),
+			geometry.NewLine([]geometry.Point{P(2, 1), P(1, 1)}, nil),
+		}),
+	})
+	topo := gjson.ParseBytes(Encode("lines", fc, nil))
+	expect(t, len(topo.Get("arcs").Array()) == 5)
+	objects, err := Decode(topo.Raw)
+	expect(t, err == nil)
+	children := objects["lines"].Children()
+	expectPoints(t, children[0].(*geojson.Feature).Base().(*geojson.LineString).Base(),
+		[]geometry.Point{P(0, 0), P(1, 1), P(2, 1), P(3, 0)}, 0)
+	mls := children[1].(*geojson.Feature).Base().(*geojson.MultiLineString)
+	expectPoints(t, mls.Children()[1].(*geojson.LineString).Base(),
+		[]geometry.Point{P(2, 1), P(1, 1)}, 0)
+}
+
+func TestEncodeQuantized(t *testing.T) {
+	fc := geojson.NewFeatureCollection([]geojson.Object{
+		geojson.NewPolygon(geometry.NewPoly(square(-112.1, 33.4, 0.1), nil,
+			nil)),
+		geojson.NewPolygon(geometry.NewPoly(square(-112.0, 33.4, 0.1), nil,
+			nil)),
+		geojson.NewMultiPoint([]geometry.Point{P(-111.95, 33.45)}),
+	})
+	data := Encode("q", fc, &EncodeOptions{Quantization: 1e4})
+	topo := gjson.ParseBytes(data)
+	expect(t, topo.Get("transform").Exists())
+	expect(t, len(topo.Get("arcs").Array()) == 3)
+	// positions after the first in an arc are deltas
+	for _, arc := range topo.Get("arcs").Array() {
+		for _, pos := range arc.Array() {
+			for _, v := range pos.Array() {
+				expect(t, v.Float() == math.Trunc(v.Float()))
+				expect(t, math.Abs(v.Float()) < 1e4)
+			}
+		}
+	}
+	objects, err := Decode(string(data))
+	expect(t, err == nil)
+	tol := 0.2 / 1e4
+	for i, child := range objects["q"].Children() {
+		rect, orig := child.Rect(), fc.Children()[i].Rect()
+		expect(t, near(rect.Min, orig.Min, tol) && near(rect.Max, orig.Max, tol))
+	}
+}
@@ -0,0 +1,26 @@
+package geojson
+
+import (
+	"github.com/tidwall/geojson/geo"
+	"github.com/tidwall/geojson/geometry"
+)
+
+// Transform returns a copy of an object where every lon/lat coordinate has
+// been converted by the projection's Forward function into x/y coordinates.
+// Use geo.Invert to convert projected coordinates back into lon/lat.
+// Feature members and foreign members are kept, except that a "bbox" is
+// replaced by the rect of the new coordinates, and extra coordinate values,
+// such as Z and M, are not changed. Shapes, such as circles, are
+// converted to their polygon approximations first.
+func Transform(obj Object, proj geo.Projection, opts *geometry.IndexOptions,
+) Object {
+	return rebuildObject(obj, func(points []geometry.Point, values []float64,
+		dims int, closed bool,
+	) ([]geometry.Point, []float64) {
+		npoints := make([]geometry.Point, len(points))
+		for i, point := range points {
+			npoints[i].X, npoints[i].Y = proj.Forward(point.Y, point.X)
+		}
+		return npoints, values
+	}, true, opts)
+}
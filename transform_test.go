@@ -0,0 +1,106 @@
+package geojson
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tidwall/geojson/geo"
+	"github.com/tidwall/geojson/geometry"
+	"github.com/tidwall/gjson"
+)
+
+func TestTransform(t *testing.T) {
+	g := expectJSON(t, `{"type":"Feature","id":7,"geometry":{"type":"LineString","coordinates":[[0,0,5],[180,85.05112877980659,6]]},"properties":{"name":"a"},"foreign":true}`, `{"type":"Feature","geometry":{"type":"LineString","coordinates":[[0,0,5],[180,85.05112877980659,6]]},"id":7,"properties":{"name":"a"},"foreign":true}`)
+	merc := Transform(g, geo.WebMercator{}, nil)
+	feature := merc.(*Feature)
+	expect(t, feature.Members() == g.(*Feature).Members())
+	line := feature.Base().(*LineString)
+	expect(t, line.Base().PointAt(0) == P(0, 0))
+	end := line.Base().PointAt(1)
+	expect(t, math.Abs(end.X-20037508.342789244) < 1e-6)
+	expect(t, math.Abs(end.Y-20037508.342789244) < 1e-6)
+	expect(t, line.extra.values[0] == 5 && line.extra.values[1] == 6)
+
+	// and back again
+	back := Transform(merc, geo.Invert(geo.WebMercator{}), nil)
+	end = back.(*Feature).Base().(*LineString).Base().PointAt(1)
+	expect(t, math.Abs(end.X-180) < 1e-9)
+	expect(t, math.Abs(end.Y-85.05112877980659) < 1e-9)
+}
+
+func TestTransformBBox(t *testing.T) {
+	// the bbox is the rect of the new coordinates
+	for _, input := range []string{
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"bbox":[1,2,1,2],"properties":{}}`,
+		`{"type":"LineString","coordinates":[[0,0],[1,1]],"bbox":[0,0,1,1]}`,
+		`{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]],"bbox":[0,0,1,1]}`,
+		`{"type":"MultiPoint","coordinates":[[0,0],[1,1]],"bbox":[0,0,1,1]}`,
+		`{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"bbox":[1,2,1,2],"properties":{}}],"bbox":[1,2,1,2]}`,
+	} {
+		merc := Transform(expectJSON(t, input, nil), geo.WebMercator{}, nil)
+		rect := merc.Rect()
+		bbox := gjson.Get(merc.JSON(), "bbox").Array()
+		expect(t, len(bbox) == 4)
+		expect(t, bbox[0].Float() == rect.Min.X && bbox[1].Float() == rect.Min.Y)
+		expect(t, bbox[2].Float() == rect.Max.X && bbox[3].Float() == rect.Max.Y)
+		expect(t, rect.Max.X > 100000)
+	}
+	// a bbox with a third dimension is removed
+	merc := Transform(expectJSON(t,
+		`{"type":"Point","coordinates":[0,0,5],"bbox":[0,0,5,0,0,5]}`, nil),
+		geo.WebMercator{}, nil)
+	expect(t, merc.JSON() == `{"type":"Point","coordinates":[0,0,5]}`)
+}
+
+func TestTransformUTM(t *testing.T) {
+	utm := geo.UTMZone(33.5, -112.3)
+	expect(t, utm.Zone == 12)
+	polys := []*geometry.Poly{
+		geometry.NewPoly(
+			[]geometry.Point{
+				P(-112.4, 33.4), P(-112.2, 33.4), P(-112.2, 33.6),
+				P(-112.4, 33.6), P(-112.4, 33.4),
+			},
+			[][]geometry.Point{{
+				P(-112.35, 33.45), P(-112.25, 33.45), P(-112.25, 33.55),
+				P(-112.35, 33.45),
+			}}, nil),
+	}
+	g := NewMultiPolygon(polys)
+	projected := Transform(g, utm, &geometry.IndexOptions{
+		Kind: geometry.RTree, MinPoints: 1,
+	})
+	expect(t, projected.Valid() == false) // meters are not valid lon/lat
+	poly := projected.(*MultiPolygon).Children()[0].(*Polygon)
+	expect(t, len(poly.Base().Holes) == 1)
+	expect(t, poly.Base().Exterior.Index() != nil)
+	x, y := utm.Forward(33.4, -112.4)
+	expect(t, poly.Base().Exterior.PointAt(0) == P(x, y))
+
+	// contains relationships are kept in the projected space
+	px, py := utm.Forward(33.5, -112.22)
+	expect(t, projected.Contains(NewPoint(P(px, py))))
+	px, py = utm.Forward(33.5, -112.3)
+	expect(t, !projected.Contains(NewPoint(P(px, py))))
+
+	back := Transform(projected, geo.Invert(utm), nil)
+	for i, point := range seriesPoints(back.(*MultiPolygon).Children()[0].(*Polygon).Base().Exterior) {
+		orig := polys[0].Exterior.PointAt(i)
+		expect(t, math.Abs(point.X-orig.X) < 1e-9)
+		expect(t, math.Abs(point.Y-orig.Y) < 1e-9)
+	}
+}
+
+func TestTransformShapes(t *testing.T) {
+	circle := NewCircle(P(-112, 33), 1000, 64)
+	projected := Transform(NewFeatureCollection([]Object{circle, PO(-112, 33)}),
+		geo.WebMercator{}, nil)
+	children := projected.(*FeatureCollection).Children()
+	poly, ok := children[0].(*Polygon)
+	expect(t, ok)
+	expect(t, poly.Base().Exterior.NumPoints() ==
+		circle.Primative().(*Polygon).Base().Exterior.NumPoints())
+	x, y := geo.WebMercator{}.Forward(33, -112)
+	expect(t, children[1].(*Point).Base() == P(x, y))
+	expect(t, poly.Contains(NewPoint(P(x, y))))
+}